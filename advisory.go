@@ -0,0 +1,87 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gopub/log"
+)
+
+// ErrLockNotAcquired is returned by TryWithLock if the lock is held by others
+var ErrLockNotAcquired = errors.New("lock not acquired")
+
+// WithLock runs fn holding advisory lock of name, e.g. to run a job on one instance of the app at a time.
+// It waits until the lock is acquired or ctx is done, and releases the lock after fn returns.
+// The lock is GET_LOCK in mysql, pg_advisory_lock in postgres and sp_getapplock in sqlserver
+func (d *DB) WithLock(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return d.withLock(ctx, name, true, fn)
+}
+
+// TryWithLock runs fn like WithLock if lock of name is acquired immediately, otherwise returns ErrLockNotAcquired
+func (d *DB) TryWithLock(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return d.withLock(ctx, name, false, fn)
+}
+
+func (d *DB) withLock(ctx context.Context, name string, wait bool, fn func(ctx context.Context) error) error {
+	lock, unlock := advisoryLockQueries(d.driverName, wait)
+	if len(lock) == 0 {
+		panic(d.driverName + " doesn't support advisory locks")
+	}
+
+	// The lock is owned by the session, so it's acquired and released on the same connection
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer conn.Close()
+
+	d.opts.logQuery(d.driverName, lock, []interface{}{name})
+	var locked sql.NullInt64
+	if err = conn.QueryRowContext(ctx, lock, name).Scan(&locked); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	// sp_getapplock returns 0 if the lock is granted without waiting
+	granted := locked.Int64 > 0 || (locked.Int64 == 0 && (d.driverName == "sqlserver" || d.driverName == "mssql"))
+	if !locked.Valid || !granted {
+		return ErrLockNotAcquired
+	}
+
+	defer func() {
+		d.opts.logQuery(d.driverName, unlock, []interface{}{name})
+		// ctx may be done, which mustn't leave the lock held by a pooled connection
+		if _, err := conn.ExecContext(context.Background(), unlock, name); err != nil {
+			log.Error(err)
+		}
+	}()
+	return fn(ctx)
+}
+
+// advisoryLockQueries returns queries to lock and unlock by name, lock query returns a number telling if it's acquired.
+// They're empty if driver doesn't support advisory locks
+func advisoryLockQueries(driverName string, wait bool) (lock, unlock string) {
+	switch {
+	case driverName == "mysql":
+		if wait {
+			return "SELECT GET_LOCK(?, -1)", "DO RELEASE_LOCK(?)"
+		}
+		return "SELECT GET_LOCK(?, 0)", "DO RELEASE_LOCK(?)"
+	case isPostgres(driverName):
+		if wait {
+			return "SELECT 1 FROM pg_advisory_lock(hashtext($1))", "SELECT pg_advisory_unlock(hashtext($1))"
+		}
+		return "SELECT pg_try_advisory_lock(hashtext($1))::int", "SELECT pg_advisory_unlock(hashtext($1))"
+	case driverName == "sqlserver" || driverName == "mssql":
+		timeout := "-1"
+		if !wait {
+			timeout = "0"
+		}
+		return "DECLARE @result int; EXEC @result = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', " +
+				"@LockOwner = 'Session', @LockTimeout = " + timeout + "; SELECT @result",
+			"EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'"
+	default:
+		return "", ""
+	}
+}
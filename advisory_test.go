@@ -0,0 +1,37 @@
+package sql_test
+
+import (
+	"context"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+func TestDB_WithLock(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("GET_LOCK").Returns([]string{"locked"}, []interface{}{1}).Once()
+	called := false
+	err := db.WithLock(context.Background(), "daily_report", func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if err != nil || !called {
+		t.Fatal(err, called)
+	}
+
+	statements := f.Statements()
+	if len(statements) != 2 || statements[0].Query != "SELECT GET_LOCK(?, -1)" || statements[1].Query != "DO RELEASE_LOCK(?)" ||
+		!reflect.DeepEqual(statements[1].Args, []interface{}{"daily_report"}) {
+		t.Error(statements)
+	}
+
+	f.On("GET_LOCK").Returns([]string{"locked"}, []interface{}{0}).Once()
+	err = db.TryWithLock(context.Background(), "daily_report", func(ctx context.Context) error {
+		t.Error("called without lock")
+		return nil
+	})
+	if err != sql.ErrLockNotAcquired {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,256 @@
+package sql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ArrayValue binds a slice as postgres array, or scans postgres array into a pointer to slice, see Array
+type ArrayValue struct {
+	v interface{}
+}
+
+var (
+	_ driver.Valuer = (*ArrayValue)(nil)
+	_ sql.Scanner   = (*ArrayValue)(nil)
+)
+
+// Array returns postgres array of v, which is a slice of strings, integers, floats or bools for binding,
+// or a pointer to such slice for scanning, e.g. db.QueryRow("SELECT tags FROM posts WHERE id=$1", 1).Scan(sql.Array(&tags)).
+// Slice args and fields tagged by array are bound and scanned as arrays by Table without it, e.g.
+//
+//	type Post struct {
+//		ID   int64
+//		Tags []string `sql:"array"`
+//	}
+//
+//	db.Table("posts").Select(&posts, "id = ANY(?)", []int64{1, 2})
+func Array(v interface{}) *ArrayValue {
+	typ := reflect.TypeOf(v)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || !isArrayType(typ) {
+		panic(fmt.Sprintf("not array: %T", v))
+	}
+	return &ArrayValue{v: v}
+}
+
+func (a *ArrayValue) Value() (driver.Value, error) {
+	v := reflect.Indirect(reflect.ValueOf(a.v))
+	if v.IsNil() {
+		return nil, nil
+	}
+	return formatArray(v), nil
+}
+
+func (a *ArrayValue) Scan(src interface{}) error {
+	v := reflect.ValueOf(a.v)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("cannot scan into array, which isn't a pointer to slice")
+	}
+
+	switch s := src.(type) {
+	case nil:
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+		return nil
+	case []byte:
+		return parseArray(string(s), v.Elem())
+	case string:
+		return parseArray(s, v.Elem())
+	default:
+		return fmt.Errorf("cannot scan %T into %v", src, v.Elem().Type())
+	}
+}
+
+// isArrayType reports whether typ is a slice which can be bound as postgres array
+func isArrayType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Slice {
+		return false
+	}
+
+	switch typ.Elem().Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// formatArray returns text of postgres array of slice v, e.g. {1,2} and {"a","b"}
+func formatArray(v reflect.Value) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		e := v.Index(i)
+		switch e.Kind() {
+		case reflect.String:
+			b.WriteByte('"')
+			for _, c := range []byte(e.String()) {
+				if c == '"' || c == '\\' {
+					b.WriteByte('\\')
+				}
+				b.WriteByte(c)
+			}
+			b.WriteByte('"')
+		case reflect.Bool:
+			if e.Bool() {
+				b.WriteByte('t')
+			} else {
+				b.WriteByte('f')
+			}
+		case reflect.Float32, reflect.Float64:
+			b.WriteString(strconv.FormatFloat(e.Float(), 'g', -1, 64))
+		case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			b.WriteString(strconv.FormatUint(e.Uint(), 10))
+		default:
+			b.WriteString(strconv.FormatInt(e.Int(), 10))
+		}
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// parseArray parses text of one dimensional postgres array s into slice v. NULL elements are zero values
+func parseArray(s string, v reflect.Value) error {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return fmt.Errorf("invalid array: %s", s)
+	}
+
+	elems, err := splitArray(s[1 : len(s)-1])
+	if err != nil {
+		return fmt.Errorf("invalid array: %s: %w", s, err)
+	}
+
+	result := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+	for i, e := range elems {
+		if e == nil {
+			continue
+		}
+
+		if err := setArrayElem(result.Index(i), *e); err != nil {
+			return fmt.Errorf("invalid array: %s: %w", s, err)
+		}
+	}
+	v.Set(result)
+	return nil
+}
+
+// splitArray splits elements of array text without braces, nil element is NULL
+func splitArray(s string) ([]*string, error) {
+	var elems []*string
+	if len(s) == 0 {
+		return elems, nil
+	}
+
+	for i := 0; i <= len(s); {
+		var b strings.Builder
+		quoted := i < len(s) && s[i] == '"'
+		if quoted {
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' {
+					i++
+					if i == len(s) {
+						break
+					}
+				}
+				b.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return nil, errors.New("unterminated quote")
+			}
+			i++
+		} else {
+			for ; i < len(s) && s[i] != ','; i++ {
+				if s[i] == '{' || s[i] == '"' {
+					return nil, errors.New("unexpected " + string(s[i]))
+				}
+				b.WriteByte(s[i])
+			}
+		}
+
+		e := b.String()
+		if !quoted && strings.EqualFold(e, "NULL") {
+			elems = append(elems, nil)
+		} else {
+			elems = append(elems, &e)
+		}
+
+		if i < len(s) && s[i] != ',' {
+			return nil, errors.New("unexpected " + string(s[i]))
+		}
+		i++
+	}
+	return elems, nil
+}
+
+func setArrayElem(e reflect.Value, s string) error {
+	switch e.Kind() {
+	case reflect.String:
+		e.SetString(s)
+	case reflect.Bool:
+		e.SetBool(s == "t" || s == "true")
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, e.Type().Bits())
+		if err != nil {
+			return err
+		}
+		e.SetFloat(f)
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, e.Type().Bits())
+		if err != nil {
+			return err
+		}
+		e.SetUint(n)
+	default:
+		n, err := strconv.ParseInt(s, 10, e.Type().Bits())
+		if err != nil {
+			return err
+		}
+		e.SetInt(n)
+	}
+	return nil
+}
+
+// bindArrays returns args whose slices are replaced by ArrayValue, or args itself if there's no slice
+func bindArrays(args []interface{}) []interface{} {
+	var bound []interface{}
+	for i, a := range args {
+		var b interface{}
+		switch v := a.(type) {
+		case SensitiveValue:
+			if v.v == nil || !isArrayType(reflect.TypeOf(v.v)) {
+				continue
+			}
+			b = Sensitive(&ArrayValue{v: v.v})
+		default:
+			if a == nil || !isArrayType(reflect.TypeOf(a)) {
+				continue
+			}
+			b = &ArrayValue{v: a}
+		}
+
+		if bound == nil {
+			bound = append([]interface{}(nil), args...)
+		}
+		bound[i] = b
+	}
+
+	if bound == nil {
+		return args
+	}
+	return bound
+}
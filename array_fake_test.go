@@ -0,0 +1,34 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+func TestArray_postgres(t *testing.T) {
+	type post struct {
+		ID   int64    `sql:"primary key"`
+		Tags []string `sql:"array"`
+	}
+
+	db, f := gosqltest.New("postgres")
+	if err := db.Table("posts").Insert(&post{ID: 1, Tags: []string{"a", "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Args[1] != `{"a","b"}` {
+		t.Error(s.Args)
+	}
+
+	f.On("SELECT id, tags FROM posts").Returns([]string{"id", "tags"}, []interface{}{1, "{a,b}"}, []interface{}{2, nil})
+	var posts []*post
+	if err := db.Table("posts").Select(&posts, "id = ANY(?)", []int64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 2 || !reflect.DeepEqual(posts[0].Tags, []string{"a", "b"}) || posts[1].Tags != nil {
+		t.Error(posts)
+	}
+	if s := f.LastStatement(); s.Query != "SELECT id, tags FROM posts WHERE id = ANY($1)" || s.Args[0] != "{1,2}" {
+		t.Error(s)
+	}
+}
@@ -0,0 +1,51 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArray(t *testing.T) {
+	v, err := Array([]string{"a", `b"c`, `d\e`, ""}).Value()
+	if err != nil || v != `{"a","b\"c","d\\e",""}` {
+		t.Error(v, err)
+	}
+
+	if v, _ = Array([]int64{1, -2}).Value(); v != "{1,-2}" {
+		t.Error(v)
+	}
+
+	if v, _ = Array([]bool(nil)).Value(); v != nil {
+		t.Error(v)
+	}
+
+	var s []string
+	if err := Array(&s).Scan([]byte(`{a,"b\"c","d,e",NULL,""}`)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s, []string{"a", `b"c`, "d,e", "", ""}) {
+		t.Error(s)
+	}
+
+	var f []float64
+	if err := Array(&f).Scan("{1.5,2}"); err != nil || !reflect.DeepEqual(f, []float64{1.5, 2}) {
+		t.Error(err, f)
+	}
+
+	if err := Array(&f).Scan("{}"); err != nil || f == nil || len(f) != 0 {
+		t.Error(err, f)
+	}
+
+	var n []int32
+	for _, src := range []string{"1,2", "{1,x}", `{"1}`, "{{1,2},{3,4}}"} {
+		if err := Array(&n).Scan(src); err == nil {
+			t.Error(src, n)
+		}
+	}
+
+	args := []interface{}{1, []byte("x"), []int64{1, 2}}
+	bound := bindArrays(args)
+	if !reflect.DeepEqual(bound[:2], args[:2]) || !reflect.DeepEqual(bound[2], &ArrayValue{v: []int64{1, 2}}) {
+		t.Error(bound)
+	}
+}
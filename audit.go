@@ -0,0 +1,489 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntry is the change of rows made by an INSERT, UPDATE or DELETE statement
+type AuditEntry struct {
+	Table  string
+	Action string
+	Actor  interface{}
+	Time   time.Time
+
+	// Before is rows matching where clause before UPDATE or DELETE
+	Before []map[string]interface{}
+
+	// After is rows inserted by INSERT, or rows of Before after UPDATE
+	After []map[string]interface{}
+}
+
+// AuditSink records entries. exe executes statements in the transaction of the audited statement if there is one,
+// and bypasses middlewares, so that audit rows are committed or rolled back with the change
+type AuditSink func(ctx context.Context, exe Executor, e *AuditEntry) error
+
+// AuditError is returned with the result of a statement which is executed, but whose audit entry isn't recorded.
+// The change is committed unless the statement is executed in a transaction which is rolled back then
+type AuditError struct {
+	Table  string
+	Action string
+	Err    error
+}
+
+func (e *AuditError) Error() string {
+	return "audit " + e.Action + " of " + e.Table + ": " + e.Err.Error()
+}
+
+func (e *AuditError) Unwrap() error {
+	return e.Err
+}
+
+type actorKey struct{}
+
+// WithActor returns a child context of ctx carrying actor of changes, which is read by ActorFromContext
+func WithActor(ctx context.Context, actor interface{}) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns actor set by WithActor
+func ActorFromContext(ctx context.Context) (interface{}, bool) {
+	actor := ctx.Value(actorKey{})
+	return actor, actor != nil
+}
+
+// Audit returns middleware which records before and after images of rows changed by INSERT, UPDATE and DELETE
+// statements to sink, with actor from context set by WithActor, e.g.
+//
+//	db.Use(sql.Audit(db.AuditTable("audit_log")))
+//	db.Table("users").WithContext(sql.WithActor(ctx, userID)).Update(user)
+//
+// Before images are selected by where clause of the statement, which takes an extra query before UPDATE and DELETE.
+// After images of UPDATE are selected by primary keys of before images, which are resolved by schema introspection
+// once per table, or by where clause if the table has no primary key. Changes are consistent with images only
+// in transactions. Statements which aren't in the forms generated by Table, e.g. UPDATE and DELETE with JOIN,
+// and statements with RETURNING, which are executed as queries, are not audited.
+// If the entry isn't recorded after the statement is executed, the result is returned with AuditError
+func Audit(sink AuditSink) Middleware {
+	if sink == nil {
+		panic("sink is nil")
+	}
+
+	return func(next Executor) Executor {
+		return &auditor{next: next, sink: sink}
+	}
+}
+
+type auditor struct {
+	next Executor
+	sink AuditSink
+	keys *keyResolver
+}
+
+func (a *auditor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	s := parseAuditStatement(query, args)
+	if s == nil {
+		return a.next.ExecContext(ctx, query, args...)
+	}
+
+	e := &AuditEntry{
+		Table:  unquoteIdent(s.table),
+		Action: s.action,
+		Time:   time.Now(),
+	}
+	e.Actor, _ = ActorFromContext(ctx)
+
+	var err error
+	if s.action != "INSERT" {
+		if e.Before, err = a.selectImage(ctx, s.table, s.where, s.whereArgs); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := a.next.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.action {
+	case "INSERT":
+		e.After = s.inserted
+	case "UPDATE":
+		if e.After, err = a.selectAfterImage(ctx, s, e.Before); err != nil {
+			return result, &AuditError{Table: e.Table, Action: e.Action, Err: err}
+		}
+	}
+
+	if err = a.sink(ctx, a.next, e); err != nil {
+		return result, &AuditError{Table: e.Table, Action: e.Action, Err: err}
+	}
+	return result, nil
+}
+
+func (a *auditor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return a.next.QueryContext(ctx, query, args...)
+}
+
+func (a *auditor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return a.next.QueryRowContext(ctx, query, args...)
+}
+
+// selectAfterImage selects rows of before image by their primary keys, or by where clause of s
+// if primary keys of the table aren't known
+func (a *auditor) selectAfterImage(ctx context.Context, s *auditStatement, before []map[string]interface{}) ([]map[string]interface{}, error) {
+	if len(before) == 0 {
+		return nil, nil
+	}
+
+	pk, err := a.keys.primaryKeys(unquoteIdent(s.table))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range pk {
+		if _, ok := before[0][c]; !ok {
+			pk = nil
+			break
+		}
+	}
+
+	if len(pk) == 0 {
+		return a.selectImage(ctx, s.table, s.where, s.whereArgs)
+	}
+
+	var conds []string
+	var args []interface{}
+	for _, row := range before {
+		cond := make([]string, len(pk))
+		for i, c := range pk {
+			cond[i] = quoteIdent(a.keys.db.driverName, c) + " = ?"
+			args = append(args, row[c])
+		}
+		conds = append(conds, "("+strings.Join(cond, " AND ")+")")
+	}
+
+	where := strings.Join(conds, " OR ")
+	if isPostgres(a.keys.db.driverName) {
+		where = rebind(where)
+	}
+	return a.selectImage(ctx, s.table, where, args)
+}
+
+// selectImage selects rows of table matching where
+func (a *auditor) selectImage(ctx context.Context, table, where string, args []interface{}) ([]map[string]interface{}, error) {
+	query := "SELECT * FROM " + table
+	if len(where) > 0 {
+		query += " WHERE " + where
+	}
+
+	rows, err := a.next.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var image []map[string]interface{}
+	values := make([]interface{}, len(columns))
+	dests := make([]interface{}, len(columns))
+	for i := range values {
+		dests[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(dests...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, c := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[c] = string(b)
+			} else {
+				row[c] = values[i]
+			}
+		}
+		image = append(image, row)
+	}
+	return image, rows.Err()
+}
+
+// AuditTable returns AuditSink which inserts entries into table, whose columns are
+// table_name, action, actor, before_image, after_image and created_at. Images are json text
+func (d *DB) AuditTable(table string) AuditSink {
+	query := "INSERT INTO " + quoteIdent(d.driverName, table) +
+		"(table_name, action, actor, before_image, after_image, created_at) VALUES (?, ?, ?, ?, ?, ?)"
+	if isPostgres(d.driverName) {
+		query = rebind(query)
+	}
+
+	return func(ctx context.Context, exe Executor, e *AuditEntry) error {
+		before, err := json.Marshal(e.Before)
+		if err != nil {
+			return err
+		}
+
+		after, err := json.Marshal(e.After)
+		if err != nil {
+			return err
+		}
+
+		var actor interface{}
+		if e.Actor != nil {
+			actor = fmt.Sprint(e.Actor)
+		}
+		_, err = exe.ExecContext(ctx, query, e.Table, e.Action, actor, string(before), string(after), e.Time)
+		return err
+	}
+}
+
+// auditStatement is an INSERT, UPDATE or DELETE statement parsed by parseAuditStatement
+type auditStatement struct {
+	action string
+
+	// table is quoted as in the statement
+	table string
+
+	// where clause with placeholders numbered from 1, and its args
+	where     string
+	whereArgs []interface{}
+
+	// rows of INSERT
+	inserted []map[string]interface{}
+}
+
+// parseAuditStatement parses statements in the forms generated by Table. It returns nil for other statements,
+// e.g. UPDATE and DELETE with alias or JOIN
+func parseAuditStatement(query string, args []interface{}) *auditStatement {
+	q := skipLeadingComments(query)
+	fields := strings.Fields(q)
+	if len(fields) < 2 {
+		return nil
+	}
+
+	s := &auditStatement{action: strings.ToUpper(fields[0])}
+	switch s.action {
+	case "UPDATE":
+		// UPDATE with alias, JOIN or FROM, whose where clause refers to other tables
+		if len(fields) < 3 || !strings.EqualFold(fields[2], "SET") || keywordIndex(q, "FROM") >= 0 {
+			return nil
+		}
+		s.table = fields[1]
+	case "DELETE":
+		if len(fields) < 3 || !strings.EqualFold(fields[1], "FROM") || (len(fields) > 3 && !strings.EqualFold(fields[3], "WHERE")) {
+			return nil
+		}
+		s.table = fields[2]
+	case "INSERT":
+		i := keywordIndex(q, "INTO")
+		if i < 0 {
+			return nil
+		}
+		return parseInsert(s, strings.TrimSpace(q[i+len("INTO"):]), args)
+	default:
+		return nil
+	}
+
+	if i := keywordIndex(q, "WHERE"); i >= 0 {
+		s.where, s.whereArgs = bindWhere(q[:i], strings.TrimSpace(q[i+len("WHERE"):]), args)
+	}
+	return s
+}
+
+// parseInsert parses "table(columns) VALUES (...), (...)" of INSERT statement, whose values are placeholders
+func parseInsert(s *auditStatement, q string, args []interface{}) *auditStatement {
+	i := strings.IndexAny(q, "( ")
+	if i <= 0 {
+		return nil
+	}
+	s.table = q[:i]
+
+	q = strings.TrimSpace(q[i:])
+	end := strings.IndexByte(q, ')')
+	if !strings.HasPrefix(q, "(") || end < 0 {
+		return nil
+	}
+
+	columns := strings.Split(q[1:end], ",")
+	for j, c := range columns {
+		columns[j] = unquoteIdent(strings.TrimSpace(c))
+	}
+
+	values := q[end+1:]
+	if j := keywordIndex(values, "ON"); j >= 0 {
+		values = values[:j]
+	}
+
+	n := countPlaceholders(values)
+	if n%len(columns) != 0 || n > len(args) {
+		return nil
+	}
+
+	for j := 0; j < n; j += len(columns) {
+		row := make(map[string]interface{}, len(columns))
+		for k, c := range columns {
+			row[c] = args[j+k]
+		}
+		s.inserted = append(s.inserted, row)
+	}
+	return s
+}
+
+// bindWhere returns where with its args, whose placeholders are renumbered from 1 if they're $n.
+// prefix is the statement before where, whose ? placeholders are skipped
+func bindWhere(prefix, where string, args []interface{}) (string, []interface{}) {
+	if !strings.Contains(where, "$") {
+		n := countPlaceholders(prefix)
+		m := countPlaceholders(where)
+		if n+m > len(args) {
+			return where, nil
+		}
+		return where, args[n : n+m]
+	}
+
+	var buf strings.Builder
+	var whereArgs []interface{}
+	walkSQL(where, func(i int, c byte) int {
+		if c != '$' {
+			buf.WriteByte(c)
+			return i + 1
+		}
+
+		j := i + 1
+		for j < len(where) && where[j] >= '0' && where[j] <= '9' {
+			j++
+		}
+
+		k, err := strconv.Atoi(where[i+1 : j])
+		if err != nil || k < 1 || k > len(args) {
+			buf.WriteByte(c)
+			return i + 1
+		}
+		whereArgs = append(whereArgs, args[k-1])
+		buf.WriteString("$" + strconv.Itoa(len(whereArgs)))
+		return j
+	}, func(c byte) {
+		buf.WriteByte(c)
+	})
+	return buf.String(), whereArgs
+}
+
+// walkSQL calls fn with unquoted characters of query, which returns position of the next character,
+// and calls quoted with quoted characters
+func walkSQL(query string, fn func(i int, c byte) int, quoted func(c byte)) {
+	var quote byte
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		default:
+			i = fn(i, c)
+			continue
+		}
+		quoted(c)
+		i++
+	}
+}
+
+func countPlaceholders(query string) int {
+	n := 0
+	walkSQL(query, func(i int, c byte) int {
+		if c == '?' {
+			n++
+		} else if c == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9' {
+			n++
+		}
+		return i + 1
+	}, func(c byte) {})
+	return n
+}
+
+// keywordIndex returns index of the first keyword outside quotes in query case-insensitively, or -1
+func keywordIndex(query, keyword string) int {
+	index := -1
+	walkSQL(query, func(i int, c byte) int {
+		if index >= 0 || i+len(keyword) > len(query) || !strings.EqualFold(query[i:i+len(keyword)], keyword) {
+			return i + 1
+		}
+
+		if (i == 0 || !isIdentChar(query[i-1])) && (i+len(keyword) == len(query) || !isIdentChar(query[i+len(keyword)])) {
+			index = i
+		}
+		return i + 1
+	}, func(c byte) {})
+	return index
+}
+
+func skipLeadingComments(query string) string {
+	query = strings.TrimSpace(query)
+	for strings.HasPrefix(query, "/*") {
+		i := strings.Index(query, "*/")
+		if i < 0 {
+			return query
+		}
+		query = strings.TrimSpace(query[i+2:])
+	}
+	return query
+}
+
+// unquoteIdent removes quotes of name quoted by quoteIdent
+func unquoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		if len(p) > 2 && strings.ContainsRune("`\"[", rune(p[0])) {
+			parts[i] = p[1 : len(p)-1]
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// keyResolver resolves primary keys of tables of db by schema introspection, and caches them
+type keyResolver struct {
+	db   *DB
+	keys sync.Map // table name:[]string
+}
+
+// primaryKeys returns primary keys of table, which are empty if the table has no primary key
+// or the driver doesn't support introspection
+func (r *keyResolver) primaryKeys(table string) ([]string, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	if pk, ok := r.keys.Load(table); ok {
+		return pk.([]string), nil
+	}
+
+	if r.db.driverName != "mysql" && r.db.driverName != "sqlite3" && !isPostgres(r.db.driverName) {
+		return nil, nil
+	}
+
+	columns, err := r.db.Columns(table)
+	if err != nil {
+		return nil, err
+	}
+
+	pk := []string{}
+	for _, c := range columns {
+		if c.Key == "PRI" {
+			pk = append(pk, c.Name)
+		}
+	}
+	r.keys.Store(table, pk)
+	return pk, nil
+}
@@ -0,0 +1,119 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	var entries []*sql.AuditEntry
+	db.Use(sql.Audit(func(ctx context.Context, exe sql.Executor, e *sql.AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	}))
+	f.On("SELECT * FROM items").Returns([]string{"id", "name"}, []interface{}{1, "a"}).Once()
+	f.On("SELECT * FROM items").Returns([]string{"id", "name"}, []interface{}{1, "b"}).Once()
+
+	ctx := sql.WithActor(context.Background(), "alice")
+	if err := db.Table("items").WithContext(ctx).Update(&fakeItem{ID: 1, Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Table("items").Insert(&fakeItem{ID: 2, Name: "c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatal(len(entries))
+	}
+
+	e := entries[0]
+	if e.Table != "items" || e.Action != "UPDATE" || e.Actor != "alice" || len(e.Before) != 1 || e.Before[0]["name"] != "a" ||
+		len(e.After) != 1 || e.After[0]["name"] != "b" {
+		t.Error(e)
+	}
+
+	if s := f.Statements()[0]; s.Query != "SELECT * FROM items WHERE id = ?" || !reflect.DeepEqual(s.Args, []interface{}{int64(1)}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	e = entries[1]
+	if e.Action != "INSERT" || e.Actor != nil || !reflect.DeepEqual(e.After, []map[string]interface{}{{"id": int64(2), "name": "c"}}) {
+		t.Error(e)
+	}
+}
+
+func TestAudit_afterImage(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	var entries []*sql.AuditEntry
+	db.Use(sql.Audit(func(ctx context.Context, exe sql.Executor, e *sql.AuditEntry) error {
+		entries = append(entries, e)
+		return nil
+	}))
+	f.On("information_schema.columns").Returns([]string{"column_name", "column_type", "nullable", "column_default", "column_key"},
+		[]interface{}{"id", "bigint", false, nil, "PRI"}, []interface{}{"status", "varchar(16)", false, nil, ""})
+	f.On("SELECT * FROM orders WHERE status = ?").Returns([]string{"id", "status"},
+		[]interface{}{1, "pending"}, []interface{}{2, "pending"})
+	f.On("SELECT * FROM orders WHERE (id = ?) OR (id = ?)").Returns([]string{"id", "status"},
+		[]interface{}{1, "done"}, []interface{}{2, "done"})
+
+	for i := 0; i < 2; i++ {
+		if err := db.Table("orders").UpdateColumnsMap(map[string]interface{}{"status": "done"}, "status = ?", "pending"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(entries) != 2 || len(entries[1].After) != 2 || entries[1].After[1]["status"] != "done" {
+		t.Fatal(entries)
+	}
+
+	var introspections int
+	for _, s := range f.Statements() {
+		if strings.Contains(s.Query, "information_schema") {
+			introspections++
+		}
+	}
+	if introspections != 1 {
+		t.Error(introspections)
+	}
+
+	if s := f.Statements()[3]; !reflect.DeepEqual(s.Args, []interface{}{int64(1), int64(2)}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	// joined updates aren't audited instead of failing
+	f.Reset()
+	err := db.Table("orders o").Join("users u ON u.id = o.user_id").
+		UpdateColumnsMap(map[string]interface{}{"o.status": "closed"}, "u.banned = ?", true)
+	if err != nil || len(entries) != 2 {
+		t.Fatal(err, len(entries))
+	}
+
+	if s := f.LastStatement(); !strings.HasPrefix(s.Query, "UPDATE orders o JOIN users u") {
+		t.Error(s.Query)
+	}
+}
+
+func TestAudit_sinkError(t *testing.T) {
+	db, _ := gosqltest.New("mysql")
+	sinkErr := errors.New("sink error")
+	db.Use(sql.Audit(func(ctx context.Context, exe sql.Executor, e *sql.AuditEntry) error {
+		return sinkErr
+	}))
+
+	result, err := db.Exec("DELETE FROM items WHERE id = ?", 1)
+	var auditErr *sql.AuditError
+	if !errors.As(err, &auditErr) || !errors.Is(err, sinkErr) || auditErr.Table != "items" || auditErr.Action != "DELETE" {
+		t.Fatal(err)
+	}
+
+	if result == nil {
+		t.Error("no result of executed statement")
+	}
+}
@@ -0,0 +1,43 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAuditStatement(t *testing.T) {
+	s := parseAuditStatement("/* app */ UPDATE `user` SET name = ?, note = '?' WHERE id = ? and tenant_id = ?", []interface{}{"a", 1, 2})
+	if s.action != "UPDATE" || s.table != "`user`" || s.where != "id = ? and tenant_id = ?" ||
+		!reflect.DeepEqual(s.whereArgs, []interface{}{1, 2}) {
+		t.Error(s)
+	}
+
+	s = parseAuditStatement(`DELETE FROM "user" WHERE id = $2 AND name <> '$1'`, []interface{}{"x", 3})
+	if s.action != "DELETE" || unquoteIdent(s.table) != "user" || s.where != "id = $1 AND name <> '$1'" ||
+		!reflect.DeepEqual(s.whereArgs, []interface{}{3}) {
+		t.Error(s)
+	}
+
+	s = parseAuditStatement("INSERT INTO user(id, name) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE name = ?",
+		[]interface{}{1, "a", 2, "b", "c"})
+	inserted := []map[string]interface{}{{"id": 1, "name": "a"}, {"id": 2, "name": "b"}}
+	if s.action != "INSERT" || s.table != "user" || !reflect.DeepEqual(s.inserted, inserted) {
+		t.Error(s)
+	}
+
+	if s = parseAuditStatement("SELECT * FROM user", nil); s != nil {
+		t.Error(s)
+	}
+
+	for _, q := range []string{
+		"UPDATE orders o JOIN users u ON u.id = o.user_id SET o.status = ? WHERE u.banned = ?",
+		"UPDATE orders o SET status = $1 FROM users u WHERE (u.id = o.user_id) AND (u.banned = $2)",
+		"UPDATE o SET status = ? FROM orders o JOIN users u ON u.id = o.user_id WHERE u.banned = ?",
+		"DELETE o FROM orders o JOIN users u ON u.id = o.user_id WHERE u.banned = ?",
+		"DELETE FROM orders o USING users u WHERE (u.id = o.user_id) AND (u.banned = $1)",
+	} {
+		if s = parseAuditStatement(q, []interface{}{"x", true}); s != nil {
+			t.Error(q, s)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/gopub/log"
+	"strings"
+)
+
+type prepareFunc func(t *Table, record interface{}) (string, []interface{}, error)
+
+// SetMultiStatementBatchSize enables batching of the statements generated by MultiInsert, MultiUpdate, MultiSave and MultiDelete.
+// Up to n statements are joined with ";" and sent in one round trip. n <= 1 disables batching.
+// It's only available for mysql whose data source name contains multiStatements=true or multiStatements=1.
+// Be aware multiStatements also allows stacked queries in raw sql and where clauses, never build them with untrusted input.
+func (d *DB) SetMultiStatementBatchSize(n int) {
+	if n > 1 {
+		if d.driverName != "mysql" {
+			panic("multi statements is not supported for driver: " + d.driverName)
+		}
+
+		if !d.multiStatements {
+			panic("multiStatements=true is required in data source name")
+		}
+	}
+	d.batchSize = n
+}
+
+// hasMultiStatements reports whether parameters of mysql data source name enable multiStatements
+func hasMultiStatements(dataSourceName string) bool {
+	i := strings.LastIndex(dataSourceName, "/")
+	j := strings.Index(dataSourceName[i+1:], "?")
+	if j < 0 {
+		return false
+	}
+
+	for _, param := range strings.Split(dataSourceName[i+j+2:], "&") {
+		key, value, _ := strings.Cut(param, "=")
+		if key == "multiStatements" {
+			return value == "true" || value == "1"
+		}
+	}
+	return false
+}
+
+// batchExec executes statements of values in batches within a transaction.
+// Records which need auto increment id are executed by single, so that the id can be read from its own result.
+func (d *DB) batchExec(values []interface{}, prepare prepareFunc, single func(tx *Tx, record interface{}) error) error {
+	tx, err := d.Begin()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	b := &batcher{
+		t:    tx.Table(""),
+		size: d.batchSize,
+	}
+	for _, v := range values {
+		if single != nil && requiresInsertID(v) {
+			err = b.flush()
+			if err == nil {
+				err = single(tx, v)
+			}
+		} else {
+			var query string
+			var args []interface{}
+			query, args, err = prepare(tx.Table(getTableName(v)), v)
+			if err == nil {
+				err = b.add(query, args)
+			}
+		}
+
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err = b.flush(); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func requiresInsertID(record interface{}) bool {
+	v := getStructValue(record)
+	info := getColumnInfo(v.Type())
+	return len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0
+}
+
+// batcher joins statements with ";" and executes them by t when size statements are added.
+// Each statement is followed by SELECT ROW_COUNT(), so that results of statements are split from the joined one
+type batcher struct {
+	t       *Table
+	size    int
+	query   bytes.Buffer
+	args    []interface{}
+	count   int
+	results []sql.Result
+}
+
+func (b *batcher) add(query string, args []interface{}) error {
+	b.query.WriteString(query)
+	b.query.WriteString("; SELECT ROW_COUNT(); ")
+	b.args = append(b.args, args...)
+	b.count++
+	if b.count >= b.size {
+		return b.flush()
+	}
+	return nil
+}
+
+// flush executes added statements in the context of t, and appends their results whose LastInsertId isn't available
+func (b *batcher) flush() error {
+	if b.count == 0 {
+		return nil
+	}
+
+	query := strings.TrimSuffix(b.query.String(), " ")
+	args := b.args
+	count := b.count
+	b.query.Reset()
+	b.args = nil
+	b.count = 0
+
+	t := b.t
+	t.logQuery(query, args)
+	ctx, cancel := t.context()
+	defer cancel()
+	rows, err := t.exe.QueryContext(ctx, t.annotate(query), args...)
+	if err != nil {
+		err = t.queryError(query, args, err)
+		log.Error(err)
+		return err
+	}
+	defer rows.Close()
+
+	n := len(b.results)
+	if err = b.scanRowCounts(rows); err == nil && len(b.results)-n != count {
+		err = fmt.Errorf("got %d row counts of %d statements", len(b.results)-n, count)
+	}
+	if err != nil {
+		err = t.queryError(query, args, err)
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+// scanRowCounts appends row counts in result sets of rows
+func (b *batcher) scanRowCounts(rows *sql.Rows) error {
+	for {
+		for rows.Next() {
+			var n int64
+			if err := rows.Scan(&n); err != nil {
+				return err
+			}
+			b.results = append(b.results, driver.RowsAffected(n))
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if !rows.NextResultSet() {
+			return rows.Err()
+		}
+	}
+}
+
+// Batch collects statements which are executed together in a transaction by Exec
+type Batch struct {
+	db    *DB
+	items []batchItem
+}
+
+type batchItem struct {
+	table string //table of statements generated from records, empty for raw statements
+	query string
+	args  []interface{}
+}
+
+// Batch returns an empty batch. Statements are sent in one round trip if multiStatements=true is in data source name of mysql,
+// otherwise they're executed one by one in the transaction
+func (d *DB) Batch() *Batch {
+	return &Batch{db: d}
+}
+
+// Len returns number of statements in b
+func (b *Batch) Len() int {
+	return len(b.items)
+}
+
+// Queue appends a raw statement
+func (b *Batch) Queue(query string, args ...interface{}) *Batch {
+	b.items = append(b.items, batchItem{query: query, args: args})
+	return b
+}
+
+// Insert appends insert statement of record, whose auto_increment id can't be read in batch so it must be non-zero
+func (b *Batch) Insert(record interface{}) error {
+	if requiresInsertID(record) {
+		return errors.New("auto_increment id isn't available in batch")
+	}
+	return b.queueRecord(record, (*Table).prepareInsertQuery)
+}
+
+// Update appends update statement of record by its primary key
+func (b *Batch) Update(record interface{}) error {
+	return b.queueRecord(record, (*Table).prepareUpdateQuery)
+}
+
+// Delete appends delete statement of record by its primary key
+func (b *Batch) Delete(record interface{}) error {
+	return b.queueRecord(record, (*Table).prepareDeleteRecordQuery)
+}
+
+func (b *Batch) queueRecord(record interface{}, prepare prepareFunc) error {
+	name := getTableName(record)
+	query, args, err := prepare(b.db.Table(name), record)
+	if err != nil {
+		return err
+	}
+	b.items = append(b.items, batchItem{table: name, query: query, args: args})
+	return nil
+}
+
+// Exec executes statements of b in a transaction, which is rolled back if any statement fails.
+// Results are in order of statements, and LastInsertId of them isn't available if they're sent in one round trip
+func (b *Batch) Exec(ctx context.Context) ([]sql.Result, error) {
+	if len(b.items) == 0 {
+		return nil, nil
+	}
+
+	var results []sql.Result
+	err := b.db.WithTx(ctx, func(tx *Tx) error {
+		if b.db.driverName == "mysql" && b.db.multiStatements {
+			bt := &batcher{
+				t:    tx.Table(""),
+				size: len(b.items),
+			}
+			for _, item := range b.items {
+				if err := bt.add(item.query, item.args); err != nil {
+					return err
+				}
+			}
+			if err := bt.flush(); err != nil {
+				return err
+			}
+			results = bt.results
+			return nil
+		}
+
+		for _, item := range b.items {
+			var result sql.Result
+			var err error
+			if len(item.table) == 0 {
+				result, err = tx.Exec(item.query, item.args...)
+			} else {
+				result, err = tx.Table(item.table).exec(item.query, item.args...)
+			}
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,49 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gopub/sql/gosqltest"
+	"strings"
+	"testing"
+)
+
+func TestDB_Batch(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	b := db.Batch()
+	if err := b.Insert(&fakeItem{ID: 1, Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete(&fakeItem{ID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	b.Queue("UPDATE counters SET n=n+1")
+	f.On("UPDATE counters").Affects(0, 2)
+	results, err := b.Exec(context.Background())
+	if err != nil || b.Len() != 3 || len(results) != 3 {
+		t.Fatal(err, results)
+	}
+
+	if n, _ := results[2].RowsAffected(); n != 2 {
+		t.Error(n)
+	}
+
+	statements := f.Statements()
+	if len(statements) != 5 || statements[0].Query != "BEGIN" || statements[4].Query != "COMMIT" ||
+		!strings.HasPrefix(statements[1].Query, "INSERT INTO fake_items") || statements[2].Query != "DELETE FROM fake_items WHERE id = $1" {
+		t.Fatal(statements)
+	}
+
+	f.Reset()
+	f.On("DELETE").Fails(errors.New("locked"))
+	if _, err = b.Exec(context.Background()); err == nil {
+		t.Fatal("no error")
+	}
+	if last := f.LastStatement(); last.Query != "ROLLBACK" {
+		t.Error(last)
+	}
+
+	if err = b.Insert(&dryRunItem{Name: "c"}); err == nil || b.Len() != 3 {
+		t.Error(err, b.Len())
+	}
+}
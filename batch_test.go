@@ -0,0 +1,79 @@
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBatcher_flush(t *testing.T) {
+	c := &stubConnector{}
+	d := OpenDB("mysql", sql.OpenDB(c))
+	d.SetDefaultQueryTimeout(time.Second)
+	b := &batcher{
+		t:    d.Table(""),
+		size: 3,
+	}
+	if err := b.add("DELETE FROM a WHERE id = ?", []interface{}{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.add("DELETE FROM b WHERE id = ?", []interface{}{2}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(b.results) != 2 {
+		t.Fatal(b.results)
+	}
+
+	if n, _ := b.results[1].RowsAffected(); n != 1 {
+		t.Error(n)
+	}
+
+	if c.last != "DELETE FROM a WHERE id = ?; SELECT ROW_COUNT(); DELETE FROM b WHERE id = ?; SELECT ROW_COUNT();" || !c.deadline {
+		t.Error(c.last, c.deadline)
+	}
+}
+
+func TestBatcher_flush_error(t *testing.T) {
+	c := &stubConnector{}
+	b := &batcher{
+		t:    OpenDB("mysql", sql.OpenDB(c)).Table(""),
+		size: 3,
+	}
+	c.down = true
+	if err := b.add("DELETE FROM a WHERE id = ?", []interface{}{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	var qe *QueryError
+	if err := b.flush(); !errors.As(err, &qe) || qe.Op != "DELETE" {
+		t.Error(err)
+	}
+
+	c.down = false
+	b.query.WriteString("DELETE FROM a WHERE id = 1; ")
+	b.count = 1
+	if err := b.flush(); err == nil || len(b.results) != 0 {
+		t.Error(err, b.results)
+	}
+}
+
+func TestHasMultiStatements(t *testing.T) {
+	for dsn, want := range map[string]bool{
+		"user:pass@tcp(localhost:3306)/db?multiStatements=true":             true,
+		"user:pass@tcp(localhost:3306)/db?parseTime=true&multiStatements=1": true,
+		"user:pass@tcp(localhost:3306)/db?multiStatements=false":            false,
+		"user:pass@tcp(localhost:3306)/db?interpolateParams=true":           false,
+		"user:multiStatements=true@tcp(localhost:3306)/db":                  false,
+		"user:pass@tcp(localhost:3306)/db":                                  false,
+	} {
+		if hasMultiStatements(dsn) != want {
+			t.Error(dsn)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+// Benchmarks run against gosqltest fake, so they measure allocations of this package rather than database.
+// Run: go test -run=NONE -bench=. -benchmem
+
+type benchProduct struct {
+	ID    int64 `sql:"primary key,auto_increment"`
+	Name  string
+	Price float64
+	Note  string `sql:"nullable"`
+}
+
+func BenchmarkInsert(b *testing.B) {
+	db, f := gosqltest.New("mysql")
+	f.On("INSERT").Affects(1, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := &benchProduct{Name: "apple", Price: 1.5}
+		if err := db.Insert(p); err != nil {
+			b.Fatal(err)
+		}
+		f.Reset()
+		f.On("INSERT").Affects(1, 1)
+	}
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	db, f := gosqltest.New("mysql")
+	p := &benchProduct{ID: 1, Name: "apple", Price: 1.5}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := db.Update(p); err != nil {
+			b.Fatal(err)
+		}
+		f.Reset()
+	}
+}
+
+func BenchmarkSelect(b *testing.B) {
+	db, f := gosqltest.New("mysql")
+	rows := make([][]interface{}, 100)
+	for i := range rows {
+		rows[i] = []interface{}{i + 1, "apple", 1.5, nil}
+	}
+	f.On("SELECT").Returns([]string{"id", "name", "price", "note"}, rows...)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var products []*benchProduct
+		if err := db.Table("products").OrderBy("id").Limit(100).Select(&products, "price > ?", 1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package sql
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"fmt"
+	"github.com/gopub/log"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RegisterReaderHandler and DeregisterReaderHandler must be set to functions of github.com/go-sql-driver/mysql
+// before BulkLoad on mysql, which reads data by LOAD DATA LOCAL INFILE 'Reader::<name>', e.g.
+//
+//	sql.RegisterReaderHandler = mysql.RegisterReaderHandler
+//	sql.DeregisterReaderHandler = mysql.DeregisterReaderHandler
+var (
+	RegisterReaderHandler   func(name string, handler func() io.Reader)
+	DeregisterReaderHandler func(name string)
+)
+
+var _readerHandlerSeq int64
+
+// BulkLoad loads rows of text from r into columns, by LOAD DATA LOCAL INFILE for mysql or COPY FROM STDIN for postgres
+// with github.com/lib/pq. It's the default text format of both: a row per line, fields are separated by tab,
+// backslash is escape character, and \N is NULL.
+// It returns the number of loaded rows
+func (t *Table) BulkLoad(r io.Reader, columns []string) (int64, error) {
+	if len(columns) == 0 {
+		panic("columns is empty")
+	}
+
+	if err := t.checkScopedWrite(); err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	switch {
+	case t.driverName == "mysql":
+		return t.loadData(r, columns)
+	case isPostgres(t.driverName):
+		br := bufio.NewReader(r)
+		return t.copyIn(columns, func() ([]interface{}, error) {
+			line, err := br.ReadString('\n')
+			if err == io.EOF && len(line) > 0 {
+				err = nil
+			}
+
+			if err != nil {
+				return nil, err
+			}
+			return parseCopyLine(strings.TrimSuffix(line, "\n"), len(columns))
+		})
+	default:
+		panic("BulkLoad is not supported for driver: " + t.driverName)
+	}
+}
+
+// BulkLoadRecords loads records received from ch, which is a channel of structs or pointers to structs,
+// until ch is closed. Columns of auto increment are left to database
+func (t *Table) BulkLoadRecords(ch interface{}) (int64, error) {
+	cv := reflect.ValueOf(ch)
+	if cv.Kind() != reflect.Chan || cv.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic("must be a receivable channel")
+	}
+
+	elemType := cv.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		panic("channel element must be a struct or pointer to struct")
+	}
+
+	info := getColumnInfo(elemType)
+	columns := info.notAINames
+	next := func() ([]interface{}, error) {
+		v, ok := cv.Recv()
+		if !ok {
+			return nil, io.EOF
+		}
+
+		v = reflect.Indirect(v)
+		values := make([]interface{}, len(columns))
+		for i, name := range columns {
+			fv, err := t.getFieldValueByName(v, info, name, true)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = fv
+		}
+		return values, nil
+	}
+
+	// drain ch so that sender isn't blocked after failure
+	defer func() {
+		go func() {
+			for _, ok := cv.Recv(); ok; _, ok = cv.Recv() {
+			}
+		}()
+	}()
+
+	if isPostgres(t.driverName) {
+		return t.copyIn(columns, next)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		var err error
+		for err == nil {
+			var values []interface{}
+			if values, err = next(); err == nil {
+				var line string
+				if line, err = formatCopyLine(t.driverName, values); err == nil {
+					_, err = io.WriteString(pw, line)
+				}
+			}
+		}
+
+		if err == io.EOF {
+			err = nil
+		}
+		pw.CloseWithError(err)
+	}()
+
+	n, err := t.BulkLoad(pr, columns)
+	pr.Close()
+	return n, err
+}
+
+func (t *Table) loadData(r io.Reader, columns []string) (int64, error) {
+	if RegisterReaderHandler == nil || DeregisterReaderHandler == nil {
+		panic("RegisterReaderHandler and DeregisterReaderHandler are not set")
+	}
+
+	name := "gosql_" + strconv.FormatInt(atomic.AddInt64(&_readerHandlerSeq, 1), 10)
+	RegisterReaderHandler(name, func() io.Reader {
+		return r
+	})
+	defer DeregisterReaderHandler(name)
+
+	query := "LOAD DATA LOCAL INFILE 'Reader::" + name + "' INTO TABLE " + t.quote(t.name) + " (" + t.quoteColumns(columns) + ")"
+	log.Debug(query)
+	result, err := t.exec(query)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// copyIn executes COPY FROM STDIN in a transaction, with rows returned by next until io.EOF
+func (t *Table) copyIn(columns []string, next func() ([]interface{}, error)) (int64, error) {
+	if t.opts.readOnly {
+		return 0, ErrReadOnly
+	}
+
+	var n int64
+	err := t.withTx(func(c *Table) error {
+		query := "COPY " + c.quote(c.name) + " (" + c.quoteColumns(columns) + ") FROM STDIN"
+		log.Debug(query)
+		stmt, err := c.tx.Prepare(query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for {
+			values, err := next()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if _, err = stmt.Exec(values...); err != nil {
+				return err
+			}
+			n++
+		}
+
+		// Exec without args flushes the copied rows
+		_, err = stmt.Exec()
+		return err
+	})
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return n, nil
+}
+
+var _copyEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// formatCopyLine formats values as a line of BulkLoad text
+func formatCopyLine(driverName string, values []interface{}) (string, error) {
+	var b strings.Builder
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte('\t')
+		}
+
+		if dv, ok := v.(driver.Valuer); ok {
+			var err error
+			if v, err = dv.Value(); err != nil {
+				return "", err
+			}
+		}
+		b.WriteString(formatCopyValue(driverName, v))
+	}
+	b.WriteByte('\n')
+	return b.String(), nil
+}
+
+func formatCopyValue(driverName string, v interface{}) string {
+
+	switch x := v.(type) {
+	case nil:
+		return `\N`
+	case SensitiveValue:
+		return formatCopyValue(driverName, x.v)
+	case UUID:
+		if isBinaryUUID(driverName) {
+			return _copyEscaper.Replace(string(x[:]))
+		}
+		return x.String()
+	case []byte:
+		return _copyEscaper.Replace(string(x))
+	case string:
+		return _copyEscaper.Replace(x)
+	case bool:
+		if driverName == "mysql" {
+			if x {
+				return "1"
+			}
+			return "0"
+		}
+		return strconv.FormatBool(x)
+	case time.Time:
+		if isPostgres(driverName) {
+			return x.Format("2006-01-02 15:04:05.999999-07:00")
+		}
+		return x.Format("2006-01-02 15:04:05.999999")
+	default:
+		return _copyEscaper.Replace(fmt.Sprint(v))
+	}
+}
+
+// parseCopyLine parses line of BulkLoad text into n values
+func parseCopyLine(line string, n int) ([]interface{}, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != n {
+		return nil, fmt.Errorf("expected %d fields, got %d: %s", n, len(fields), line)
+	}
+
+	values := make([]interface{}, n)
+	for i, f := range fields {
+		if f == `\N` {
+			continue
+		}
+
+		if strings.IndexByte(f, '\\') < 0 {
+			values[i] = f
+			continue
+		}
+
+		var b strings.Builder
+		for j := 0; j < len(f); j++ {
+			c := f[j]
+			if c == '\\' && j+1 < len(f) {
+				j++
+				switch f[j] {
+				case 't':
+					c = '\t'
+				case 'n':
+					c = '\n'
+				case 'r':
+					c = '\r'
+				default:
+					c = f[j]
+				}
+			}
+			b.WriteByte(c)
+		}
+		values[i] = b.String()
+	}
+	return values, nil
+}
+
+func (d *DB) BulkLoad(table string, r io.Reader, columns []string) (int64, error) {
+	return d.Table(table).BulkLoad(r, columns)
+}
+
+func (d *DB) BulkLoadRecords(table string, ch interface{}) (int64, error) {
+	return d.Table(table).BulkLoadRecords(ch)
+}
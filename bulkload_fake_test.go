@@ -0,0 +1,61 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTable_BulkLoad(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	n, err := db.BulkLoad("items", strings.NewReader("1\ta\n2\t\\N\n"), []string{"id", "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 2 {
+		t.Error(n)
+	}
+
+	var args [][]interface{}
+	for _, s := range f.Statements() {
+		if s.Query == "COPY items (id, name) FROM STDIN" {
+			args = append(args, s.Args)
+		}
+	}
+	if !reflect.DeepEqual(args, [][]interface{}{{"1", "a"}, {"2", nil}, {}}) {
+		t.Error(args)
+	}
+
+	db, f = gosqltest.New("mysql")
+	var data []byte
+	sql.RegisterReaderHandler = func(name string, handler func() io.Reader) {
+		data, _ = io.ReadAll(handler())
+	}
+	sql.DeregisterReaderHandler = func(name string) {}
+	defer func() {
+		sql.RegisterReaderHandler = nil
+		sql.DeregisterReaderHandler = nil
+	}()
+
+	f.On("LOAD DATA").Affects(0, 2)
+	ch := make(chan *fakeItem, 2)
+	ch <- &fakeItem{ID: 1, Name: "a"}
+	ch <- &fakeItem{ID: 2, Name: "b\tc"}
+	close(ch)
+	if n, err = db.BulkLoadRecords("items", ch); err != nil || n != 2 {
+		t.Fatal(n, err)
+	}
+
+	if string(data) != "1\ta\n2\tb\\tc\n" {
+		t.Errorf("%q", data)
+	}
+
+	if s := f.LastStatement(); !strings.HasPrefix(s.Query, "LOAD DATA LOCAL INFILE 'Reader::gosql_") ||
+		!strings.HasSuffix(s.Query, "' INTO TABLE items (id, name)") {
+		t.Error(s.Query)
+	}
+}
@@ -0,0 +1,32 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCopyLine(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	line, err := formatCopyLine("mysql", []interface{}{int64(1), "a\tb\\c\nd", nil, true, at, []byte("x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if line != "1\ta\\tb\\\\c\\nd\t\\N\t1\t2020-01-02 03:04:05\tx\n" {
+		t.Errorf("%q", line)
+	}
+
+	values, err := parseCopyLine(line[:len(line)-1], 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(values, []interface{}{"1", "a\tb\\c\nd", nil, "1", "2020-01-02 03:04:05", "x"}) {
+		t.Errorf("%q", values)
+	}
+
+	if _, err = parseCopyLine("1\t2", 3); err == nil {
+		t.Error("expected error")
+	}
+}
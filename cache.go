@@ -0,0 +1,307 @@
+package sql
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"github.com/gopub/log"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStore stores results of Select and SelectOne, which are tagged by tables they depend on.
+// It can be backed by memory, see NewLRUCache, or shared by instances, e.g. Redis.
+// Errors are handled by the store, as cache is best effort
+type CacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, tables []string, ttl time.Duration)
+
+	// Invalidate deletes results depending on tables
+	Invalidate(ctx context.Context, tables ...string)
+}
+
+// SetCache sets store of results cached by Table.Cache. Results of a table are invalidated by
+// INSERT, UPDATE and DELETE statements on the table executed by d, and again after their transactions are committed.
+// It must be called before d is used
+func (d *DB) SetCache(store CacheStore) {
+	if store == nil {
+		panic("store is nil")
+	}
+	d.opts.cache = store
+	d.Use(func(next Executor) Executor {
+		return &cacheInvalidator{next: next, store: store}
+	})
+}
+
+// Cache returns a copy of t whose Select and SelectOne results are cached for ttl, outside transactions.
+// The results depend on t, and also on tables if they're selected by join or subquery
+func (t *Table) Cache(ttl time.Duration, tables ...string) *Table {
+	c := *t
+	c.cacheTTL = ttl
+	c.cacheTables = tables
+	return &c
+}
+
+// cacheKey returns key of the result of query into dest, or empty string if the result isn't cached
+func (t *Table) cacheKey(query string, args []interface{}, dest interface{}) string {
+	if t.cacheTTL <= 0 || t.opts == nil || t.opts.cache == nil || t.tx != nil || len(t.lock) > 0 {
+		return ""
+	}
+
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\x00%T\x00%s", t.driverName, dest, strings.Join(strings.Fields(query), " "))
+	for _, a := range args {
+		a = cacheKeyArg(a)
+		fmt.Fprintf(h, "\x00%T:%v", a, a)
+	}
+	return "gosql:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheKeyArg returns the value of arg bound by driver, so that pointers are keyed by values rather than addresses
+func cacheKeyArg(a interface{}) interface{} {
+	if s, ok := a.(SensitiveValue); ok {
+		a = s.v
+	}
+
+	for {
+		v := reflect.ValueOf(a)
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+
+		if valuer, ok := a.(driver.Valuer); ok {
+			if dv, err := valuer.Value(); err == nil {
+				return dv
+			}
+			return a
+		}
+
+		if v.Kind() != reflect.Ptr {
+			return a
+		}
+		a = v.Elem().Interface()
+	}
+}
+
+// loadCache decodes cached result of key into v, which is a pointer
+func (t *Table) loadCache(key string, v reflect.Value) bool {
+	data, ok := t.opts.cache.Get(t.cacheContext(), key)
+	if !ok {
+		return false
+	}
+
+	result := reflect.New(v.Type().Elem())
+	if err := gob.NewDecoder(bytes.NewReader(data)).DecodeValue(result); err != nil {
+		log.Warn(err)
+		return false
+	}
+	v.Elem().Set(result.Elem())
+	return true
+}
+
+// storeCache caches result v, which is a pointer
+func (t *Table) storeCache(key string, v reflect.Value) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).EncodeValue(v); err != nil {
+		log.Warn(err)
+		return
+	}
+
+	tables := append([]string{strings.Fields(t.name)[0]}, t.cacheTables...)
+	t.opts.cache.Set(t.cacheContext(), key, buf.Bytes(), tables, t.cacheTTL)
+}
+
+func (t *Table) cacheContext() context.Context {
+	if t.ctx != nil {
+		return t.ctx
+	}
+	return context.Background()
+}
+
+type cacheInvalidator struct {
+	next  Executor
+	store CacheStore
+}
+
+func (c *cacheInvalidator) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := c.next.ExecContext(ctx, query, args...)
+	if s := parseAuditStatement(query, args); s != nil {
+		// results are invalidated even if statement fails, which may have changed rows partially
+		c.store.Invalidate(ctx, unquoteIdent(s.table))
+	}
+	return result, err
+}
+
+func (c *cacheInvalidator) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := c.next.QueryContext(ctx, query, args...)
+	if s := parseAuditStatement(query, args); s != nil {
+		// statements with RETURNING
+		c.store.Invalidate(ctx, unquoteIdent(s.table))
+	}
+	return rows, err
+}
+
+func (c *cacheInvalidator) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	row := c.next.QueryRowContext(ctx, query, args...)
+	if s := parseAuditStatement(query, args); s != nil {
+		c.store.Invalidate(ctx, unquoteIdent(s.table))
+	}
+	return row
+}
+
+// txWrites records tables written by a transaction, whose cached results are invalidated again after commit,
+// as concurrent readers may cache old rows between the statements and the commit
+type txWrites struct {
+	next   Executor
+	mu     sync.Mutex
+	tables map[string]struct{}
+}
+
+func (w *txWrites) record(query string, args []interface{}) {
+	s := parseAuditStatement(query, args)
+	if s == nil {
+		return
+	}
+
+	w.mu.Lock()
+	if w.tables == nil {
+		w.tables = make(map[string]struct{})
+	}
+	w.tables[unquoteIdent(s.table)] = struct{}{}
+	w.mu.Unlock()
+}
+
+// invalidate invalidates cached results of written tables in store
+func (w *txWrites) invalidate(ctx context.Context, store CacheStore) {
+	w.mu.Lock()
+	tables := make([]string, 0, len(w.tables))
+	for name := range w.tables {
+		tables = append(tables, name)
+	}
+	w.tables = nil
+	w.mu.Unlock()
+
+	if len(tables) > 0 {
+		store.Invalidate(ctx, tables...)
+	}
+}
+
+func (w *txWrites) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	w.record(query, args)
+	return w.next.ExecContext(ctx, query, args...)
+}
+
+func (w *txWrites) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	w.record(query, args)
+	return w.next.QueryContext(ctx, query, args...)
+}
+
+func (w *txWrites) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	w.record(query, args)
+	return w.next.QueryRowContext(ctx, query, args...)
+}
+
+// LRUCache is an in-memory CacheStore which evicts the least recently used results beyond its size
+type LRUCache struct {
+	mu     sync.Mutex
+	size   int
+	items  *list.List               //*lruItem, most recently used at front
+	keys   map[string]*list.Element //key:element of items
+	tables map[string]map[string]struct{}
+}
+
+type lruItem struct {
+	key     string
+	value   []byte
+	tables  []string
+	expires time.Time
+}
+
+var _ CacheStore = (*LRUCache)(nil)
+
+// NewLRUCache returns LRUCache holding at most size results
+func NewLRUCache(size int) *LRUCache {
+	if size <= 0 {
+		panic("size must be positive")
+	}
+	return &LRUCache{
+		size:   size,
+		items:  list.New(),
+		keys:   make(map[string]*list.Element),
+		tables: make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *LRUCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.keys[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := e.Value.(*lruItem)
+	if time.Now().After(item.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.items.MoveToFront(e)
+	return item.value, true
+}
+
+func (c *LRUCache) Set(ctx context.Context, key string, value []byte, tables []string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.keys[key]; ok {
+		c.remove(e)
+	}
+
+	item := &lruItem{key: key, value: value, tables: tables, expires: time.Now().Add(ttl)}
+	c.keys[key] = c.items.PushFront(item)
+	for _, table := range tables {
+		if c.tables[table] == nil {
+			c.tables[table] = make(map[string]struct{})
+		}
+		c.tables[table][key] = struct{}{}
+	}
+
+	for c.items.Len() > c.size {
+		c.remove(c.items.Back())
+	}
+}
+
+func (c *LRUCache) Invalidate(ctx context.Context, tables ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, table := range tables {
+		for key := range c.tables[table] {
+			c.remove(c.keys[key])
+		}
+	}
+}
+
+// Len returns number of cached results
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items.Len()
+}
+
+func (c *LRUCache) remove(e *list.Element) {
+	item := c.items.Remove(e).(*lruItem)
+	delete(c.keys, item.key)
+	for _, table := range item.tables {
+		delete(c.tables[table], item.key)
+		if len(c.tables[table]) == 0 {
+			delete(c.tables, table)
+		}
+	}
+}
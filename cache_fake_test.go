@@ -0,0 +1,97 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+	"time"
+)
+
+func TestTable_Cache(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetCache(sql.NewLRUCache(10))
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a"}).Once()
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "b"}).Once()
+
+	items := db.Table("items").Cache(time.Minute)
+	for i := 0; i < 2; i++ {
+		var result []*fakeItem
+		if err := items.Select(&result, "id = ?", 1); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result) != 1 || result[0].Name != "a" {
+			t.Fatal(result)
+		}
+	}
+
+	var item fakeItem
+	if err := items.SelectOne(&item, "id = ?", 1); err != nil || item.Name != "b" {
+		t.Fatal(item, err)
+	}
+
+	if err := items.SelectOne(&item, "id = ?", 1); err != nil || item.Name != "b" {
+		t.Fatal(item, err)
+	}
+
+	if err := db.Table("items").Update(&fakeItem{ID: 1, Name: "c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var result []*fakeItem
+	if err := items.Select(&result, "id = ?", 1); err != nil || len(result) != 0 {
+		t.Fatal(result, err)
+	}
+
+	if n := len(f.Statements()); n != 4 {
+		t.Error(n)
+	}
+}
+
+func TestTable_Cache_tx(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetCache(sql.NewLRUCache(10))
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a"}).Once()
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "b"}).Once()
+	items := db.Table("items").Cache(time.Minute)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tx.Table("items").Update(&fakeItem{ID: 1, Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a reader caches the row before commit
+	var item fakeItem
+	if err = items.SelectOne(&item, "id = ?", 1); err != nil || item.Name != "a" {
+		t.Fatal(item, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = items.SelectOne(&item, "id = ?", 1); err != nil || item.Name != "b" {
+		t.Fatal(item, err)
+	}
+}
+
+func TestTable_Cache_pointerArgs(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetCache(sql.NewLRUCache(10))
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a"}).Once()
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{2, "b"}).Once()
+	items := db.Table("items").Cache(time.Minute)
+
+	id := new(int64)
+	var item fakeItem
+	for i := int64(1); i <= 2; i++ {
+		*id = i
+		if err := items.SelectOne(&item, "id = ?", id); err != nil || item.ID != i {
+			t.Fatal(item, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(2)
+	c.Set(ctx, "a", []byte("1"), []string{"users"}, time.Minute)
+	c.Set(ctx, "b", []byte("2"), []string{"orders", "users"}, time.Minute)
+	if _, ok := c.Get(ctx, "a"); !ok {
+		t.Fatal("a is missing")
+	}
+
+	c.Set(ctx, "c", []byte("3"), []string{"orders"}, time.Minute)
+	if _, ok := c.Get(ctx, "b"); ok {
+		t.Error("b isn't evicted")
+	}
+
+	c.Invalidate(ctx, "users")
+	if _, ok := c.Get(ctx, "a"); ok || c.Len() != 1 {
+		t.Error("a isn't invalidated", c.Len())
+	}
+
+	c.Set(ctx, "d", []byte("4"), nil, -time.Second)
+	if _, ok := c.Get(ctx, "d"); ok {
+		t.Error("d isn't expired")
+	}
+
+	if v, ok := c.Get(ctx, "c"); !ok || string(v) != "3" {
+		t.Error(string(v), ok)
+	}
+}
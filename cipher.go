@@ -0,0 +1,153 @@
+package sql
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// ErrUnknownKey is returned if ciphertext is encrypted by a key which isn't in Keyring
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// Cipher encrypts values of columns tagged by encrypted, e.g.
+//
+//	type User struct {
+//		ID    int64
+//		Email string `sql:"encrypted"`
+//	}
+//
+// String fields are stored as base64 text of ciphertext, and []byte fields are stored as ciphertext.
+// Encrypted columns can't be matched by where clause, as ciphertext of the same value differs
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// SetCipher sets cipher of encrypted columns
+func (d *DB) SetCipher(c Cipher) {
+	d.opts.cipher = c
+}
+
+// Keyring is a Cipher with AES-GCM keys identified by ids. Ciphertext is prefixed by id of the key encrypting it,
+// so keys can be rotated by adding a new current key while old keys still decrypt existing values,
+// which are encrypted by the current key once they're saved again
+type Keyring struct {
+	current string
+	aeads   map[string]cipher.AEAD
+}
+
+var _ Cipher = (*Keyring)(nil)
+
+// NewKeyring returns a Keyring encrypting with keys[current]. Keys are 16, 24 or 32 bytes for AES-128, AES-192 or AES-256
+func NewKeyring(current string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("no current key: %s", current)
+	}
+
+	k := &Keyring{
+		current: current,
+		aeads:   make(map[string]cipher.AEAD, len(keys)),
+	}
+	for id, key := range keys {
+		if len(id) == 0 || len(id) > 255 {
+			return nil, fmt.Errorf("invalid key id: %s", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+
+		if k.aeads[id], err = cipher.NewGCM(block); err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+	}
+	return k, nil
+}
+
+// Encrypt returns len(id) | id | nonce | sealed plaintext
+func (k *Keyring) Encrypt(plaintext []byte) ([]byte, error) {
+	aead := k.aeads[k.current]
+	n := 1 + len(k.current)
+	out := make([]byte, n+aead.NonceSize(), n+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	out[0] = byte(len(k.current))
+	copy(out[1:], k.current)
+	if _, err := io.ReadFull(rand.Reader, out[n:]); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, out[n:], plaintext, nil), nil
+}
+
+func (k *Keyring) Decrypt(ciphertext []byte) ([]byte, error) {
+	id, err := KeyID(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	n := 1 + len(id)
+	if len(ciphertext) < n+aead.NonceSize() {
+		return nil, errors.New("invalid ciphertext")
+	}
+	return aead.Open(nil, ciphertext[n:n+aead.NonceSize()], ciphertext[n+aead.NonceSize():], nil)
+}
+
+// KeyID returns id of the key encrypting ciphertext by Keyring, e.g. to find values to be re-encrypted
+func KeyID(ciphertext []byte) (string, error) {
+	if len(ciphertext) == 0 || len(ciphertext) < 1+int(ciphertext[0]) {
+		return "", errors.New("invalid ciphertext")
+	}
+	return string(ciphertext[1 : 1+int(ciphertext[0])]), nil
+}
+
+// encryptValue returns encrypted column value of field v, which is string or []byte
+func encryptValue(c Cipher, v reflect.Value) (interface{}, error) {
+	if c == nil {
+		panic("no cipher. please call SetCipher")
+	}
+
+	if v.Kind() == reflect.String {
+		data, err := c.Encrypt([]byte(v.String()))
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	return c.Encrypt(v.Bytes())
+}
+
+// decryptValue decrypts column value data into field v
+func decryptValue(c Cipher, data []byte, v reflect.Value) error {
+	if c == nil {
+		panic("no cipher. please call SetCipher")
+	}
+
+	if v.Kind() == reflect.String {
+		decoded, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return err
+		}
+		data = decoded
+	}
+
+	plaintext, err := c.Decrypt(data)
+	if err != nil {
+		return err
+	}
+
+	if v.Kind() == reflect.String {
+		v.SetString(string(plaintext))
+	} else {
+		v.SetBytes(plaintext)
+	}
+	return nil
+}
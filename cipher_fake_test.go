@@ -0,0 +1,44 @@
+package sql_test
+
+import (
+	"bytes"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"strings"
+	"testing"
+)
+
+type secretItem struct {
+	ID    int64  `sql:"primary key"`
+	Email string `sql:"encrypted"`
+	Token []byte `sql:"encrypted,nullable"`
+}
+
+func TestDB_SetCipher(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	keyring, err := sql.NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetCipher(keyring)
+
+	if err = db.Insert(&secretItem{ID: 1, Email: "a@b.c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	args := f.LastStatement().Args
+	email, ok := args[1].(string)
+	if !ok || strings.Contains(email, "a@b.c") || args[2] != nil {
+		t.Fatal(args)
+	}
+
+	f.On("SELECT").Returns([]string{"id", "email", "token"}, []interface{}{1, email, nil})
+	var item secretItem
+	if err = db.SelectOne(&item, "id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if item.Email != "a@b.c" || item.Token != nil {
+		t.Error(item)
+	}
+}
@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKeyring(t *testing.T) {
+	old, err := NewKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := old.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := NewKeyring("k2", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32), "k2": bytes.Repeat([]byte{2}, 16)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := rotated.Decrypt(ciphertext)
+	if err != nil || string(plaintext) != "secret" {
+		t.Fatal(string(plaintext), err)
+	}
+
+	ciphertext, err = rotated.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id, err := KeyID(ciphertext); err != nil || id != "k2" {
+		t.Error(id, err)
+	}
+
+	if _, err = old.Decrypt(ciphertext); err != ErrUnknownKey {
+		t.Error(err)
+	}
+
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err = rotated.Decrypt(ciphertext); err == nil {
+		t.Error("tampered ciphertext is decrypted")
+	}
+
+	if _, err = NewKeyring("k3", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}); err == nil {
+		t.Error("no error for missing current key")
+	}
+}
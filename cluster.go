@@ -0,0 +1,153 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+)
+
+type ReplicaPolicy int
+
+const (
+	RoundRobin ReplicaPolicy = iota
+	LeastConn
+)
+
+// a replica which fails to respond to ping is skipped for replicaDownDuration
+var replicaDownDuration = 10 * time.Second
+
+// OpenCluster opens a primary database and its replicas.
+// Select, SelectOne, Count, Query and QueryRow of DB are routed to replicas, other operations go to primary.
+// A replica is skipped for a while if it's down, and primary is used if all replicas are down.
+// Replication lag must be acceptable for reads routed to replicas, otherwise read in transaction.
+func OpenCluster(driverName, primaryDSN string, replicaDSNs ...string) (*DB, error) {
+	d, err := Open(driverName, primaryDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(replicaDSNs) == 0 {
+		return d, nil
+	}
+
+	d.replicas = &replicaSet{primary: d.db}
+	for _, dsn := range replicaDSNs {
+		db, err := sql.Open(driverName, dsn)
+		if err != nil {
+			d.Close()
+			return nil, err
+		}
+		d.replicas.replicas = append(d.replicas.replicas, &replica{db: db})
+	}
+	return d, nil
+}
+
+// SetReplicaPolicy sets the way to choose replica for reads
+func (d *DB) SetReplicaPolicy(p ReplicaPolicy) {
+	if d.replicas == nil {
+		panic("no replicas")
+	}
+	atomic.StoreInt32(&d.replicas.policy, int32(p))
+}
+
+func (d *DB) reader() Executor {
+	if d.replicas != nil {
+		return d.replicas
+	}
+	return d.db
+}
+
+type replica struct {
+	db        *sql.DB
+	downUntil int64
+}
+
+func (r *replica) isDown() bool {
+	return atomic.LoadInt64(&r.downUntil) > time.Now().UnixNano()
+}
+
+func (r *replica) markDown() {
+	atomic.StoreInt64(&r.downUntil, time.Now().Add(replicaDownDuration).UnixNano())
+}
+
+// replicaSet implements Executor, it only runs queries on replicas
+type replicaSet struct {
+	primary  *sql.DB
+	replicas []*replica
+	policy   int32
+	next     uint32
+}
+
+func (s *replicaSet) pick() *replica {
+	if ReplicaPolicy(atomic.LoadInt32(&s.policy)) == LeastConn {
+		var picked *replica
+		minInUse := 0
+		for _, r := range s.replicas {
+			if r.isDown() {
+				continue
+			}
+			inUse := r.db.Stats().InUse
+			if picked == nil || inUse < minInUse {
+				picked = r
+				minInUse = inUse
+			}
+		}
+		return picked
+	}
+
+	n := uint32(len(s.replicas))
+	start := atomic.AddUint32(&s.next, 1)
+	for i := uint32(0); i < n; i++ {
+		r := s.replicas[(start+i)%n]
+		if !r.isDown() {
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *replicaSet) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.ExecContext(context.Background(), query, args...)
+}
+
+func (s *replicaSet) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.QueryContext(context.Background(), query, args...)
+}
+
+func (s *replicaSet) QueryRow(query string, args ...interface{}) *sql.Row {
+	return s.QueryRowContext(context.Background(), query, args...)
+}
+
+func (s *replicaSet) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.primary.ExecContext(ctx, query, args...)
+}
+
+func (s *replicaSet) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	for r := s.pick(); r != nil; r = s.pick() {
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err == nil || ctx.Err() != nil || r.db.PingContext(ctx) == nil {
+			return rows, err
+		}
+		r.markDown()
+	}
+	return s.primary.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext pings replica before the query, as error of sql.Row is only known after it's scanned.
+// Replicas failing to ping are marked down like QueryContext
+func (s *replicaSet) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	for r := s.pick(); r != nil; r = s.pick() {
+		if r.db.PingContext(ctx) == nil || ctx.Err() != nil {
+			return r.db.QueryRowContext(ctx, query, args...)
+		}
+		r.markDown()
+	}
+	return s.primary.QueryRowContext(ctx, query, args...)
+}
+
+func (s *replicaSet) close() {
+	for _, r := range s.replicas {
+		r.db.Close()
+	}
+}
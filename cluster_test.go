@@ -0,0 +1,166 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// stubConnector connects to a database answering queries with a row of id 1 per SELECT, or fails if it's down
+type stubConnector struct {
+	down     bool
+	queries  int
+	last     string
+	deadline bool
+}
+
+func (c *stubConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if c.down {
+		return nil, errors.New("connection refused")
+	}
+	return &stubConn{c: c}, nil
+}
+
+func (c *stubConnector) Driver() driver.Driver {
+	return nil
+}
+
+type stubConn struct {
+	c *stubConnector
+}
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+	return &stubStmt{c: c.c, query: query}, nil
+}
+
+func (c *stubConn) Close() error {
+	return nil
+}
+
+func (c *stubConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *stubConn) Ping(ctx context.Context) error {
+	if c.c.down {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+type stubStmt struct {
+	c     *stubConnector
+	query string
+}
+
+func (s *stubStmt) Close() error {
+	return nil
+}
+
+func (s *stubStmt) NumInput() int {
+	return -1
+}
+
+func (s *stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (s *stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.c.down {
+		return nil, driver.ErrBadConn
+	}
+	s.c.queries++
+	s.c.last = s.query
+	return &stubRows{n: strings.Count(s.query, "SELECT")}, nil
+}
+
+func (s *stubStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	_, s.c.deadline = ctx.Deadline()
+	return s.Query(nil)
+}
+
+type stubRows struct {
+	n int
+}
+
+func (r *stubRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *stubRows) Close() error {
+	return nil
+}
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.n == 0 {
+		return io.EOF
+	}
+	r.n--
+	dest[0] = int64(1)
+	return nil
+}
+
+type replicaItem struct {
+	ID int64
+}
+
+// openStubCluster returns DB of primary and replicas, whose replicas are down if down is true
+func openStubCluster(down ...bool) (*DB, *stubConnector, []*stubConnector) {
+	primary := &stubConnector{}
+	d := OpenDB("mysql", sql.OpenDB(primary))
+	d.replicas = &replicaSet{primary: d.db}
+	var replicas []*stubConnector
+	for _, v := range down {
+		c := &stubConnector{down: v}
+		replicas = append(replicas, c)
+		d.replicas.replicas = append(d.replicas.replicas, &replica{db: sql.OpenDB(c)})
+	}
+	return d, primary, replicas
+}
+
+func TestReplicaSet_failover(t *testing.T) {
+	d, primary, replicas := openStubCluster(true, false)
+	for i := 0; i < 2; i++ {
+		if n, err := d.Table("items").Count(""); err != nil || n != 1 {
+			t.Fatal(n, err)
+		}
+
+		var item replicaItem
+		if err := d.Table("items").SelectOne(&item, "id = ?", 1); err != nil || item.ID != 1 {
+			t.Fatal(item, err)
+		}
+
+		var items []*replicaItem
+		if err := d.Table("items").Select(&items, ""); err != nil || len(items) != 1 {
+			t.Fatal(items, err)
+		}
+	}
+
+	if !d.replicas.replicas[0].isDown() || d.replicas.replicas[1].isDown() {
+		t.Error("replica isn't marked down")
+	}
+
+	if primary.queries != 0 || replicas[1].queries != 6 {
+		t.Error(primary.queries, replicas[1].queries)
+	}
+}
+
+func TestReplicaSet_allDown(t *testing.T) {
+	d, primary, _ := openStubCluster(true, true)
+	var item replicaItem
+	if err := d.Table("items").SelectOne(&item, "id = ?", 1); err != nil || item.ID != 1 {
+		t.Fatal(item, err)
+	}
+
+	if n, err := d.Table("items").Count(""); err != nil || n != 1 {
+		t.Fatal(n, err)
+	}
+
+	if primary.queries != 2 {
+		t.Error(primary.queries)
+	}
+}
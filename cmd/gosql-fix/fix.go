@@ -0,0 +1,520 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+const (
+	v1Path  = "github.com/gopub/sql"
+	v2Path  = "github.com/gopub/sql/v2"
+	v1Alias = "gosqlv1"
+)
+
+type kind int
+
+const (
+	kindNone kind = iota
+	kindDB
+	kindTx
+	kindTable
+	// kindV1 is a value of v1 API kept by V1(), whose calls aren't rewritten
+	kindV1
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindDB:
+		return "DB"
+	case kindTx:
+		return "Tx"
+	case kindTable:
+		return "Table"
+	default:
+		return "v1"
+	}
+}
+
+type set map[string]bool
+
+func newSet(names ...string) set {
+	s := make(set, len(names))
+	for _, n := range names {
+		s[n] = true
+	}
+	return s
+}
+
+var (
+	// statements are methods of v2 which take context first
+	statements = map[kind]set{
+		kindDB: newSet("Exec", "Query", "QueryRow", "Insert", "Update", "Save", "Delete", "Get", "Select", "SelectOne",
+			"MultiInsert", "MultiUpdate", "MultiDelete"),
+		kindTx: newSet("Exec", "Insert", "InsertIgnore", "Update", "Save", "Delete", "Get", "Select", "SelectOne"),
+		kindTable: newSet("Insert", "InsertIgnore", "Update", "Save", "Delete", "DeleteRecord", "Get", "Select",
+			"SelectOne", "Count"),
+	}
+
+	// kept are methods of v2 whose signatures are the same as v1
+	kept = map[kind]set{
+		kindDB:    newSet("V1", "Close", "Table", "BeginTx", "WithTx"),
+		kindTx:    newSet("V1", "Commit", "Rollback", "Table"),
+		kindTable: newSet("V1", "Columns", "OrderBy", "Limit", "Offset", "Paginate"),
+	}
+
+	// exports are package level identifiers of v2
+	exports = newSet("DB", "Tx", "Table", "Open", "OpenDB", "FromV1", "ErrInvalidUsage", "ErrNoRows", "Expression",
+		"ConflictStrategy", "TxOptions", "MultiError", "RecordError", "UpdateOnConflict", "SkipConflicts", "FailFast")
+
+	// suspicious are names of statements, calls of them on unresolved receivers are listed for manual review
+	suspicious = newSet("Exec", "Query", "QueryRow", "Insert", "InsertIgnore", "Update", "Save", "Delete",
+		"DeleteRecord", "Get", "Select", "SelectOne", "Count", "MultiInsert", "MultiUpdate", "MultiDelete",
+		"MultiSave", "MultiSaveWithStrategy", "Begin")
+)
+
+// fixer rewrites a file importing v1
+type fixer struct {
+	fset    *token.FileSet
+	file    *ast.File
+	pkg     string
+	imports map[string]string
+
+	objs   map[*ast.Object]kind
+	fields map[string]kind
+
+	needContext bool
+	needV1      bool
+	notes       []*note
+}
+
+// fix rewrites src of file to v2. It returns nil if file doesn't import v1 or imports v2 already
+func fix(file string, src []byte) ([]byte, []*note, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	x := &fixer{
+		fset:    fset,
+		file:    f,
+		imports: make(map[string]string),
+		objs:    make(map[*ast.Object]kind),
+		fields:  make(map[string]kind),
+	}
+
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if path == v2Path {
+			// mixes v1 and v2 deliberately, e.g. by package compat
+			return nil, nil, nil
+		}
+
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		x.imports[path] = name
+
+		if path == v1Path {
+			x.pkg = name
+			spec.Path.Value = strconv.Quote(v2Path)
+		}
+	}
+
+	switch x.pkg {
+	case "":
+		return nil, nil, nil
+	case "_", ".":
+		x.note(f.Package, true, fmt.Sprintf("import %s of %s isn't resolved, fix its usage", x.pkg, v1Path))
+		x.pkg = ""
+	default:
+		// resolves again for kinds derived from values declared later, e.g. struct fields
+		x.resolve()
+		x.resolve()
+		ast.Walk(&scope{x: x}, f)
+	}
+
+	var buf bytes.Buffer
+	if err = format.Node(&buf, fset, f); err != nil {
+		return nil, nil, err
+	}
+
+	var specs []string
+	if x.needContext {
+		specs = append(specs, strconv.Quote("context"))
+	}
+	if x.needV1 {
+		specs = append(specs, v1Alias+" "+strconv.Quote(v1Path))
+	}
+
+	out, err := addImports(file, buf.Bytes(), specs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, x.notes, nil
+}
+
+func (x *fixer) note(pos token.Pos, manual bool, text string) {
+	x.notes = append(x.notes, &note{pos: x.fset.Position(pos), manual: manual, text: text})
+}
+
+// resolve finds kinds of variables, parameters and struct fields
+func (x *fixer) resolve() {
+	ast.Inspect(x.file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.StructType:
+			for _, field := range n.Fields.List {
+				k := x.kindOfType(field.Type)
+				for _, name := range field.Names {
+					x.setField(name.Name, k)
+				}
+			}
+		case *ast.Field:
+			if k := x.kindOfType(n.Type); k != kindNone {
+				for _, name := range n.Names {
+					x.setObj(name, k)
+				}
+			}
+		case *ast.ValueSpec:
+			if n.Type != nil {
+				k := x.kindOfType(n.Type)
+				for _, name := range n.Names {
+					x.setObj(name, k)
+				}
+				break
+			}
+			x.assign(exprs(n.Names), n.Values)
+		case *ast.AssignStmt:
+			x.assign(n.Lhs, n.Rhs)
+		case *ast.KeyValueExpr:
+			// fields of composite literals, e.g. &Service{db: db}
+			if key, ok := n.Key.(*ast.Ident); ok {
+				x.setField(key.Name, x.kindOf(n.Value))
+			}
+		}
+		return true
+	})
+}
+
+func (x *fixer) assign(lhs, rhs []ast.Expr) {
+	if len(rhs) == 1 && len(lhs) > 1 {
+		// e.g. db, err := sql.Open(...)
+		lhs = lhs[:1]
+	}
+
+	if len(lhs) != len(rhs) {
+		return
+	}
+
+	for i, e := range lhs {
+		k := x.kindOf(rhs[i])
+		switch e := e.(type) {
+		case *ast.Ident:
+			x.setObj(e, k)
+		case *ast.SelectorExpr:
+			x.setField(e.Sel.Name, k)
+		}
+	}
+}
+
+func (x *fixer) setObj(id *ast.Ident, k kind) {
+	if id.Obj != nil && k != kindNone {
+		x.objs[id.Obj] = k
+	}
+}
+
+// setField sets kind of struct fields named name. Fields of different kinds with the same name aren't resolved
+func (x *fixer) setField(name string, k kind) {
+	if k == kindNone {
+		return
+	}
+
+	if prev, ok := x.fields[name]; ok && prev != k {
+		k = kindNone
+	}
+	x.fields[name] = k
+}
+
+// kindOfType returns kind of *DB, *Tx and *Table of v1
+func (x *fixer) kindOfType(e ast.Expr) kind {
+	star, ok := e.(*ast.StarExpr)
+	if !ok {
+		return kindNone
+	}
+
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok || !x.isPkg(sel.X) {
+		return kindNone
+	}
+
+	switch sel.Sel.Name {
+	case "DB":
+		return kindDB
+	case "Tx":
+		return kindTx
+	case "Table":
+		return kindTable
+	default:
+		return kindNone
+	}
+}
+
+// kindOf returns kind of value e
+func (x *fixer) kindOf(e ast.Expr) kind {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return x.kindOf(e.X)
+	case *ast.Ident:
+		if e.Obj != nil {
+			return x.objs[e.Obj]
+		}
+	case *ast.SelectorExpr:
+		if !x.isPkg(e.X) {
+			return x.fields[e.Sel.Name]
+		}
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return kindNone
+		}
+
+		if x.isPkg(sel.X) {
+			if sel.Sel.Name == "Open" || sel.Sel.Name == "OpenDB" {
+				return kindDB
+			}
+			return kindNone
+		}
+
+		return x.result(x.kindOf(sel.X), sel.Sel.Name)
+	}
+	return kindNone
+}
+
+// result returns kind of the result of method name of k
+func (x *fixer) result(k kind, name string) kind {
+	switch {
+	case k == kindNone:
+		return kindNone
+	case name == "V1":
+		return kindV1
+	case (k == kindDB || k == kindTx) && name == "Table":
+		return kindTable
+	case k == kindDB && (name == "Begin" || name == "BeginTx"):
+		return kindTx
+	case k == kindTable && kept[kindTable][name]:
+		return kindTable
+	default:
+		// methods which aren't in v2 are called on v1
+		return kindV1
+	}
+}
+
+// isPkg reports whether e is the package name of v1
+func (x *fixer) isPkg(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Obj == nil && id.Name == x.pkg
+}
+
+// isImport reports whether e is a package name of other imports
+func (x *fixer) isImport(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	if !ok || id.Obj != nil {
+		return false
+	}
+
+	for _, name := range x.imports {
+		if name == id.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// scope walks nodes with the context expression of enclosing functions
+type scope struct {
+	x   *fixer
+	ctx string
+}
+
+func (s *scope) Visit(n ast.Node) ast.Visitor {
+	switch n := n.(type) {
+	case *ast.FuncDecl:
+		return &scope{x: s.x, ctx: s.x.contextParam(n.Type, "")}
+	case *ast.FuncLit:
+		return &scope{x: s.x, ctx: s.x.contextParam(n.Type, s.ctx)}
+	case *ast.CallExpr:
+		s.x.call(n, s.ctx)
+	case *ast.SelectorExpr:
+		s.x.selector(n)
+	}
+	return s
+}
+
+// contextParam returns name of the context.Context parameter of fn, or def if there's none
+func (x *fixer) contextParam(fn *ast.FuncType, def string) string {
+	pkg, ok := x.imports["context"]
+	if !ok {
+		return def
+	}
+
+	for _, field := range fn.Params.List {
+		sel, ok := field.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Context" {
+			continue
+		}
+
+		if id, ok := sel.X.(*ast.Ident); !ok || id.Name != pkg {
+			continue
+		}
+
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				return name.Name
+			}
+		}
+	}
+	return def
+}
+
+// selector rewrites package identifiers which aren't in v2 to v1
+func (x *fixer) selector(sel *ast.SelectorExpr) {
+	if !x.isPkg(sel.X) || exports[sel.Sel.Name] {
+		return
+	}
+
+	x.note(sel.Pos(), false, fmt.Sprintf("kept v1 API %s.%s", x.pkg, sel.Sel.Name))
+	sel.X = &ast.Ident{NamePos: sel.X.Pos(), Name: v1Alias}
+	x.needV1 = true
+}
+
+// call rewrites method calls of DB, Tx and Table
+func (x *fixer) call(c *ast.CallExpr, ctx string) {
+	sel, ok := c.Fun.(*ast.SelectorExpr)
+	if !ok || x.isPkg(sel.X) || x.isImport(sel.X) {
+		return
+	}
+
+	name := sel.Sel.Name
+	k := x.kindOf(sel.X)
+	switch {
+	case k == kindNone:
+		if suspicious[name] {
+			x.note(c.Pos(), true, fmt.Sprintf("receiver of %s isn't resolved, it may take context first in v2", name))
+		}
+		return
+	case k == kindV1 || kept[k][name]:
+		return
+	}
+
+	pos := c.Pos()
+	switch {
+	case statements[k][name]:
+		c.Args = append([]ast.Expr{x.context(pos, ctx)}, c.Args...)
+		x.note(pos, false, fmt.Sprintf("%s.%s takes context", k, name))
+	case k == kindDB && name == "MultiSave":
+		strategy := &ast.SelectorExpr{X: ast.NewIdent(x.pkg), Sel: ast.NewIdent("UpdateOnConflict")}
+		c.Args = append([]ast.Expr{x.context(pos, ctx), strategy}, c.Args...)
+		x.note(pos, false, "DB.MultiSave takes context and UpdateOnConflict")
+	case k == kindDB && name == "MultiSaveWithStrategy":
+		sel.Sel = &ast.Ident{NamePos: sel.Sel.NamePos, Name: "MultiSave"}
+		c.Args = append([]ast.Expr{x.context(pos, ctx)}, c.Args...)
+		x.note(pos, false, "DB.MultiSaveWithStrategy is DB.MultiSave with context")
+	case k == kindDB && name == "Begin":
+		sel.Sel = &ast.Ident{NamePos: sel.Sel.NamePos, Name: "BeginTx"}
+		c.Args = []ast.Expr{x.context(pos, ctx), ast.NewIdent("nil")}
+		x.note(pos, false, "DB.Begin is DB.BeginTx with context")
+	default:
+		end := sel.X.End()
+		sel.X = &ast.CallExpr{
+			Fun:    &ast.SelectorExpr{X: sel.X, Sel: ast.NewIdent("V1")},
+			Lparen: end,
+			Rparen: end,
+		}
+		x.note(pos, false, fmt.Sprintf("kept v1 API %s.%s by V1()", k, name))
+	}
+}
+
+// context returns ctx, or context.TODO() if there's no context in scope
+func (x *fixer) context(pos token.Pos, ctx string) ast.Expr {
+	if ctx != "" {
+		return ast.NewIdent(ctx)
+	}
+
+	pkg, ok := x.imports["context"]
+	if !ok {
+		pkg = "context"
+		x.imports["context"] = pkg
+		x.needContext = true
+	}
+	x.note(pos, true, "context.TODO() is passed, pass context of the caller instead")
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent("TODO")},
+	}
+}
+
+// addImports adds import specs to src, which is formatted
+func addImports(file string, src []byte, specs []string) ([]byte, error) {
+	if len(specs) == 0 {
+		return src, nil
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	var decl *ast.GenDecl
+	for _, d := range f.Decls {
+		if d, ok := d.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			decl = d
+			break
+		}
+	}
+
+	lines := "\n\t" + strings.Join(specs, "\n\t")
+	var buf bytes.Buffer
+	if decl.Lparen.IsValid() {
+		off := fset.Position(decl.Lparen).Offset + 1
+		buf.Write(src[:off])
+		buf.WriteString(lines)
+		buf.Write(src[off:])
+	} else {
+		start := fset.Position(decl.Specs[0].Pos()).Offset
+		end := fset.Position(decl.End()).Offset
+		buf.Write(src[:fset.Position(decl.Pos()).Offset])
+		buf.WriteString("import (\n\t")
+		buf.Write(src[start:end])
+		buf.WriteString(lines)
+		buf.WriteString("\n)")
+		buf.Write(src[end:])
+	}
+
+	fset = token.NewFileSet()
+	f, err = parser.ParseFile(fset, file, buf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	ast.SortImports(fset, f)
+	buf.Reset()
+	if err = format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func exprs(ids []*ast.Ident) []ast.Expr {
+	l := make([]ast.Expr, len(ids))
+	for i, id := range ids {
+		l[i] = id
+	}
+	return l
+}
@@ -0,0 +1,167 @@
+// gosql-fix migrates code using github.com/gopub/sql to github.com/gopub/sql/v2. It rewrites import paths,
+// inserts context as the first argument of statements of DB, Tx and Table, and keeps calls which v2 doesn't
+// provide on v1 by V1(). Receivers are resolved by declarations in the same file, calls it can't resolve are
+// listed in the migration guide for manual review.
+//
+// Usage:
+//
+//	gosql-fix [-w] [-guide MIGRATION.md] path ...
+//
+// Paths are go files or directories, which are walked recursively except vendor and testdata.
+// Rewritten files are printed unless -w is set, and the guide is printed to stderr unless -guide is set
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	write := flag.Bool("w", false, "write rewritten files instead of printing them")
+	guidePath := flag.String("guide", "", "file of the migration guide, default is stderr")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var files []string
+	for _, path := range flag.Args() {
+		l, err := goFiles(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		files = append(files, l...)
+	}
+
+	g := &guide{}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		out, notes, err := fix(file, src)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if out == nil {
+			continue
+		}
+		g.add(file, notes)
+
+		if *write {
+			err = ioutil.WriteFile(file, out, 0644)
+		} else {
+			_, err = os.Stdout.Write(out)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if len(*guidePath) == 0 {
+		os.Stderr.Write(g.bytes())
+	} else if err := ioutil.WriteFile(*guidePath, g.bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// goFiles returns go files of path, which is a file or directory
+func goFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if p != path && (info.Name() == "vendor" || info.Name() == "testdata" || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.HasSuffix(p, ".go") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// note is a change made by fix, or a call which must be checked manually
+type note struct {
+	pos    token.Position
+	manual bool
+	text   string
+}
+
+// guide is the migration guide of rewritten files
+type guide struct {
+	files []string
+	notes map[string][]*note
+}
+
+func (g *guide) add(file string, notes []*note) {
+	if g.notes == nil {
+		g.notes = make(map[string][]*note)
+	}
+	g.files = append(g.files, file)
+	g.notes[file] = notes
+}
+
+func (g *guide) bytes() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Migration to github.com/gopub/sql/v2\n\n")
+	buf.WriteString("- Statements take context first, and return errors wrapping ErrInvalidUsage instead of panicking\n")
+	buf.WriteString("- Rows of Query are bound to its context instead of the default query timeout\n")
+	buf.WriteString("- Insert of v2 doesn't use write-behind buffers, call V1().Insert to keep them\n")
+	buf.WriteString("- APIs not in v2 are kept on v1 by V1(), see package compat for functions taking v1 types\n")
+	buf.WriteString("- Positions below are of files before rewriting\n")
+	if len(g.files) == 0 {
+		buf.WriteString("\nNo file imports github.com/gopub/sql.\n")
+	}
+
+	for _, file := range g.files {
+		fmt.Fprintf(&buf, "\n## %s\n\n", file)
+		var manual int
+		for _, n := range g.notes[file] {
+			if n.manual {
+				manual++
+				continue
+			}
+			fmt.Fprintf(&buf, "- %d:%d %s\n", n.pos.Line, n.pos.Column, n.text)
+		}
+
+		if manual == 0 {
+			continue
+		}
+
+		buf.WriteString("\nCheck manually:\n\n")
+		for _, n := range g.notes[file] {
+			if n.manual {
+				fmt.Fprintf(&buf, "- [ ] %d:%d %s\n", n.pos.Line, n.pos.Column, n.text)
+			}
+		}
+	}
+	return buf.Bytes()
+}
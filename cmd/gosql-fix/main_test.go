@@ -0,0 +1,161 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSource = `package store
+
+import (
+	"context"
+
+	"github.com/gopub/sql"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func New(dsn string) (*Store, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetDefaultQueryTimeout(0)
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Create(ctx context.Context, u *User) error {
+	return s.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := tx.Insert(u); err != nil {
+			return err
+		}
+		return tx.Table("logs").Insert(&Log{})
+	})
+}
+
+func (s *Store) List() ([]*User, error) {
+	var l []*User
+	err := s.db.Table("users").OrderBy("id").Where(sql.Expression("1=1")).Select(&l, "")
+	if err != nil {
+		return nil, err
+	}
+	return l, s.db.MultiSave(l[0], l[1])
+}
+
+func (s *Store) Begin(c *Cache) (*sql.Tx, error) {
+	c.Get("k")
+	return s.db.Begin()
+}
+`
+
+func TestFix(t *testing.T) {
+	out, notes, err := fix("store.go", []byte(testSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := string(out)
+	for _, s := range []string{
+		`"github.com/gopub/sql/v2"`,
+		`db.V1().SetDefaultQueryTimeout(0)`,
+		`return s.db.WithTx(ctx, func(tx *sql.Tx) error {`,
+		`if err := tx.Insert(ctx, u); err != nil {`,
+		`return tx.Table("logs").Insert(ctx, &Log{})`,
+		`s.db.Table("users").OrderBy("id").V1().Where(sql.Expression("1=1")).Select(&l, "")`,
+		`s.db.MultiSave(context.TODO(), sql.UpdateOnConflict, l[0], l[1])`,
+		`return s.db.BeginTx(context.TODO(), nil)`,
+		`c.Get("k")`,
+	} {
+		if !strings.Contains(src, s) {
+			t.Error("missing", s)
+		}
+	}
+
+	if strings.Contains(src, v1Alias) {
+		t.Error("unnecessary v1 import")
+	}
+
+	var manual []string
+	for _, n := range notes {
+		if n.manual {
+			manual = append(manual, n.text)
+		}
+	}
+
+	if len(manual) != 3 || !strings.Contains(manual[1], "receiver of Get") {
+		t.Error(manual)
+	}
+}
+
+func TestFix_imports(t *testing.T) {
+	src := `package store
+
+import "github.com/gopub/sql"
+
+var cache = sql.NewLRUCache(10)
+
+func count(db *sql.DB) (int, error) {
+	return db.Table("users").Count("")
+}
+`
+	out, notes, err := fix("store.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `package store
+
+import (
+	"context"
+	gosqlv1 "github.com/gopub/sql"
+	"github.com/gopub/sql/v2"
+)
+
+var cache = gosqlv1.NewLRUCache(10)
+
+func count(db *sql.DB) (int, error) {
+	return db.Table("users").Count(context.TODO(), "")
+}
+`
+	if string(out) != want {
+		t.Error(string(out))
+	}
+
+	if len(notes) != 3 || notes[0].text != "kept v1 API sql.NewLRUCache" || notes[0].pos.Line != 5 {
+		t.Error(notes)
+	}
+}
+
+func TestFix_skip(t *testing.T) {
+	out, notes, err := fix("main.go", []byte("package main\n\nimport \"database/sql\"\n\nvar db *sql.DB\n"))
+	if err != nil || out != nil || notes != nil {
+		t.Error(out, notes, err)
+	}
+
+	src := "package main\n\nimport (\n\tv1 \"github.com/gopub/sql\"\n\t\"github.com/gopub/sql/v2\"\n)\n\nvar db *v1.DB\n"
+	if out, _, err = fix("main.go", []byte(src)); err != nil || out != nil {
+		t.Error(out, err)
+	}
+}
+
+func TestGuide(t *testing.T) {
+	_, notes, err := fix("store.go", []byte(testSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := &guide{}
+	g.add("store.go", notes)
+	s := string(g.bytes())
+	for _, line := range []string{
+		"## store.go",
+		"- 24:13 Tx.Insert takes context",
+		"- [ ] 41:2 receiver of Get isn't resolved, it may take context first in v2",
+	} {
+		if !strings.Contains(s, line) {
+			t.Error("missing", line, "in", s)
+		}
+	}
+}
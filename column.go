@@ -1,9 +1,10 @@
 package sql
 
 import (
-	"github.com/gopub/mapper"
+	"github.com/gopub/sql/internal/sqltag"
 	"github.com/gopub/utils"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	"unsafe"
@@ -12,27 +13,14 @@ import (
 var _bytesType = reflect.TypeOf([]byte(nil))
 var _int64Type = reflect.TypeOf(int64(0))
 var _typeToColumnInfo = &sync.Map{} //type:*columnInfo
-var _sqlKeywords = map[string]struct{}{
-	"primary":        {},
-	"key":            {},
-	"auto_increment": {},
-	"insert":         {},
-	"create":         {},
-	"table":          {},
-	"database":       {},
-	"select":         {},
-	"update":         {},
-	"unique":         {},
-	"int":            {},
-	"bigint":         {},
-	"bool":           {},
-	"tinyint":        {},
-	"double":         {},
-	"date":           {},
-	"json":           {},
-	"nullable":       {},
+
+// defaultOrdering declares ORDER BY clause used by Select if it's not specified by Table.OrderBy
+type defaultOrdering interface {
+	DefaultOrder() []string
 }
 
+var _defaultOrderingType = reflect.TypeOf((*defaultOrdering)(nil)).Elem()
+
 type fieldIndex []int
 
 func (f fieldIndex) DeepEqual(v fieldIndex) bool {
@@ -64,9 +52,95 @@ type columnInfo struct {
 
 	nullableNames []string
 
+	//string or []byte columns encrypted by Cipher
+	encryptedNames []string
+
+	//slice columns bound and scanned as postgres arrays
+	arrayNames []string
+
+	//columns whose values are redacted in logs
+	sensitiveNames []string
+
+	//column name:size declared by size=n
+	sizes map[string]int
+
+	indexNames  []string
+	uniqueNames []string
+
+	//declared by defaultorder=... separated by ; or DefaultOrder method
+	defaultOrder []string
+
+	//tenant column name declared by tenant, see DB.SetTenantScope
+	tenantName string
+
+	//column name:allowed values declared by enum=... or Enum
+	enums map[string][]string
+
+	//UUID primary key column name:version of generated UUID
+	uuidVersions map[string]int
+
+	//primary key column name:name of id generator declared by id=name
+	idGenerators map[string]string
+
+	//alias:index of fields declared by computed, which are scanned from expressions of Table.SelectExpr
+	computed map[string]fieldIndex
+
+	//expressions selected after columns, whose aliases are the last names
+	exprs []string
+
 	//for speed
 	notPKNames []string
 	notAINames []string
+
+	//field name:relation declared by rel:...
+	relations map[string]*relation
+}
+
+// subset returns info of columns in names, and panics if any column doesn't exist
+func (info *columnInfo) subset(names []string) *columnInfo {
+	sub := &columnInfo{
+		nameToIndex:  make(map[string]fieldIndex, len(names)),
+		pkNames:      info.pkNames,
+		defaultOrder: info.defaultOrder,
+		enums:        info.enums,
+		computed:     info.computed,
+	}
+	for _, name := range names {
+		idx, ok := info.nameToIndex[name]
+		if !ok {
+			// names of NamingStrategy may not be lower case
+			name = strings.ToLower(name)
+			idx, ok = info.nameToIndex[name]
+		}
+
+		if !ok {
+			panic("unknown column: " + name)
+		}
+
+		sub.indexes = append(sub.indexes, idx)
+		sub.names = append(sub.names, name)
+		sub.nameToIndex[name] = idx
+		if utils.IndexOfString(info.jsonNames, name) >= 0 {
+			sub.jsonNames = append(sub.jsonNames, name)
+		}
+
+		if utils.IndexOfString(info.nullableNames, name) >= 0 {
+			sub.nullableNames = append(sub.nullableNames, name)
+		}
+
+		if utils.IndexOfString(info.encryptedNames, name) >= 0 {
+			sub.encryptedNames = append(sub.encryptedNames, name)
+		}
+
+		if utils.IndexOfString(info.sensitiveNames, name) >= 0 {
+			sub.sensitiveNames = append(sub.sensitiveNames, name)
+		}
+
+		if utils.IndexOfString(info.arrayNames, name) >= 0 {
+			sub.arrayNames = append(sub.arrayNames, name)
+		}
+	}
+	return sub
 }
 
 func getColumnInfo(typ reflect.Type) *columnInfo {
@@ -94,11 +168,14 @@ func parseColumnInfo(typ reflect.Type) *columnInfo {
 
 	info := &columnInfo{}
 	info.nameToIndex = make(map[string]fieldIndex, typ.NumField())
+	info.sizes = make(map[string]int)
+	uuidVersions := make(map[string]int)
+	idGenerators := make(map[string]string)
 
 	fields := getAllFields(typ)
 
 	for _, f := range fields {
-		tag := strings.TrimSpace(strings.ToLower(f.Tag.Get("sql")))
+		tag := sqltag.Normalize(f.Tag.Get("sql"))
 		if tag == "-" {
 			continue
 		}
@@ -110,28 +187,48 @@ func parseColumnInfo(typ reflect.Type) *columnInfo {
 			continue
 		}
 
+		if strings.Contains(tag, "rel:") {
+			if info.relations == nil {
+				info.relations = make(map[string]*relation)
+			}
+			info.relations[f.Name] = parseRelation(typ, f, tag)
+			continue
+		}
+
+		if sqltag.HasOption(tag, "computed") {
+			name := strings.TrimSpace(strings.Split(tag, ",")[0])
+			if len(name) == 0 || name == "computed" {
+				name = naming().ColumnName(f.Name)
+			}
+			if info.computed == nil {
+				info.computed = make(map[string]fieldIndex)
+			}
+			info.computed[name] = f.Index
+			continue
+		}
+
 		isJSON := strings.Contains(tag, "json")
 		nullable := strings.Contains(tag, "nullable")
+		isArray := sqltag.HasOption(tag, "array")
+		if isArray && (isJSON || !isArrayType(f.Type)) {
+			panic("array column must be slice of strings, integers, floats or bools: " + f.Name)
+		}
 
-		if !isJSON && !isSupportType(f.Type) {
-			if len(tag) > 0 {
-				panic("invalid type: db column " + typ.Name() + ":" + f.Type.String())
-			}
+		if _, tagged := f.Tag.Lookup("sql"); isTimeType(f.Type) && !tagged {
+			// untagged time fields were never columns, so models without time columns keep working
 			continue
 		}
 
-		var name string
-		if len(tag) > 0 {
-			strs := strings.Split(tag, ",")
-			if len(strs) > 0 {
-				if _, ok := _sqlKeywords[strs[0]]; !ok && mapper.MatchPattern(mapper.PatternVariable, strs[0]) {
-					name = strs[0]
-				}
+		if !isJSON && !isArray && !isSupportType(f.Type) {
+			if len(tag) > 0 {
+				panic("invalid type: db column " + typ.Name() + ":" + f.Type.String())
 			}
+			continue
 		}
 
+		name := sqltag.ColumnName(tag)
 		if len(name) == 0 {
-			name = utils.CamelToSnake(f.Name)
+			name = naming().ColumnName(f.Name)
 		}
 
 		if idx, found := info.nameToIndex[name]; found {
@@ -144,7 +241,7 @@ func parseColumnInfo(typ reflect.Type) *columnInfo {
 			}
 		}
 
-		if strings.Contains(tag, "primary key") {
+		if sqltag.IsPrimaryKey(tag) {
 			if isJSON {
 				panic("json column can't be primary key")
 			}
@@ -172,6 +269,76 @@ func parseColumnInfo(typ reflect.Type) *columnInfo {
 		if nullable {
 			info.nullableNames = append(info.nullableNames, name)
 		}
+
+		if isArray {
+			info.arrayNames = append(info.arrayNames, name)
+		}
+
+		enum := parseEnumTag(f.Tag.Get("sql"))
+		if len(enum) == 0 {
+			enum = enumValues(f.Type)
+		}
+		if len(enum) > 0 {
+			if isJSON || f.Type.Kind() != reflect.String {
+				panic("enum column must be string: " + f.Name)
+			}
+			if info.enums == nil {
+				info.enums = make(map[string][]string)
+			}
+			info.enums[name] = enum
+		}
+
+		if f.Type == _uuidType {
+			uuidVersions[name] = 4
+		}
+
+		for _, s := range strings.Split(tag, ",") {
+			s = strings.TrimSpace(s)
+			switch {
+			case strings.HasPrefix(s, "uuid="):
+				if f.Type != _uuidType || (s != "uuid=v4" && s != "uuid=v7") {
+					panic("invalid uuid: " + s)
+				}
+				uuidVersions[name] = int(s[len("uuid=v")] - '0')
+			case strings.HasPrefix(s, "id="):
+				idGenerators[name] = s[len("id="):]
+			case s == "index":
+				info.indexNames = append(info.indexNames, name)
+			case s == "unique":
+				info.uniqueNames = append(info.uniqueNames, name)
+			case s == "encrypted":
+				if isJSON || (f.Type.Kind() != reflect.String && f.Type != _bytesType) {
+					panic("encrypted column must be string or []byte: " + f.Name)
+				}
+				info.encryptedNames = append(info.encryptedNames, name)
+			case s == "sensitive":
+				info.sensitiveNames = append(info.sensitiveNames, name)
+			case s == "tenant":
+				if len(info.tenantName) > 0 {
+					panic("duplicate tenant")
+				}
+				info.tenantName = name
+			case strings.HasPrefix(s, "defaultorder="):
+				info.defaultOrder = nil
+				for _, o := range strings.Split(s[len("defaultorder="):], ";") {
+					if o = strings.TrimSpace(o); len(o) > 0 {
+						info.defaultOrder = append(info.defaultOrder, o)
+					}
+				}
+			case strings.HasPrefix(s, "size="):
+				size, err := strconv.Atoi(s[len("size="):])
+				if err != nil || size <= 0 {
+					panic("invalid size: " + s)
+				}
+				info.sizes[name] = size
+			}
+		}
+	}
+
+	if typ.Implements(_defaultOrderingType) {
+		info.defaultOrder = reflect.Zero(typ).Interface().(defaultOrdering).DefaultOrder()
+	} else if reflect.PtrTo(typ).Implements(_defaultOrderingType) {
+		info.defaultOrder = reflect.New(typ).Interface().(defaultOrdering).DefaultOrder()
 	}
 
 	if len(info.pkNames) == 0 {
@@ -183,6 +350,25 @@ func parseColumnInfo(typ reflect.Type) *columnInfo {
 		}
 	}
 
+	for _, name := range info.pkNames {
+		if version, ok := uuidVersions[name]; ok {
+			if info.uuidVersions == nil {
+				info.uuidVersions = make(map[string]int)
+			}
+			info.uuidVersions[name] = version
+		}
+	}
+
+	for name, generator := range idGenerators {
+		if utils.IndexOfString(info.pkNames, name) < 0 {
+			panic("id generator must be used by primary key: " + name)
+		}
+		if info.idGenerators == nil {
+			info.idGenerators = make(map[string]string)
+		}
+		info.idGenerators[name] = generator
+	}
+
 	for _, name := range info.names {
 		if utils.IndexOfString(info.pkNames, name) < 0 {
 			info.notPKNames = append(info.notPKNames, name)
@@ -205,6 +391,10 @@ func isSupportType(typ reflect.Type) bool {
 		return false
 	}
 
+	if isTimeType(typ) || isUUIDType(typ) || isGeoType(typ) {
+		return true
+	}
+
 	switch typ.Kind() {
 	case reflect.Bool, reflect.Float32, reflect.Float64, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.String:
@@ -228,8 +418,8 @@ func getAllFields(typ reflect.Type) []reflect.StructField {
 				t = t.Elem()
 			}
 			subFields := getAllFields(t)
-			for i := range subFields {
-				subFields[i].Index = append([]int{i}, subFields[i].Index...)
+			for j := range subFields {
+				subFields[j].Index = append([]int{i}, subFields[j].Index...)
 			}
 			fields = append(fields, subFields...)
 		} else {
@@ -0,0 +1,79 @@
+package sql
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type commentTagsKey struct{}
+
+// WithCommentTags returns a child context of ctx with tags, which are merged into tags of ctx
+func WithCommentTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string)
+	for k, v := range CommentTags(ctx) {
+		merged[k] = v
+	}
+
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, commentTagsKey{}, merged)
+}
+
+// CommentTags returns tags set by WithCommentTags
+func CommentTags(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(commentTagsKey{}).(map[string]string)
+	return tags
+}
+
+// SQLCommenter returns middleware which appends comment in sqlcommenter format to statements,
+// e.g. /*route='%2Fusers',traceparent='00-0af7...-01'*/, so that slow queries can be correlated with traces.
+// Tags are returned by fn from context of the statement, or by CommentTags if fn is nil.
+// Statements which already have comments are left unchanged
+func SQLCommenter(fn func(ctx context.Context) map[string]string) Middleware {
+	if fn == nil {
+		fn = CommentTags
+	}
+
+	return Rewrite(func(ctx context.Context, query string, args []interface{}) (string, []interface{}) {
+		return appendSQLComment(query, fn(ctx)), args
+	})
+}
+
+func appendSQLComment(query string, tags map[string]string) string {
+	if len(tags) == 0 || strings.Contains(query, "/*") {
+		return query
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("/*")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(commentEscape(k))
+		b.WriteString("='")
+		b.WriteString(commentEscape(tags[k]))
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+
+	trimmed := strings.TrimRight(query, " \t\n")
+	if strings.HasSuffix(trimmed, ";") {
+		return trimmed[:len(trimmed)-1] + " " + b.String() + ";"
+	}
+	return trimmed + " " + b.String()
+}
+
+// commentEscape url-encodes s, which also escapes quotes
+func commentEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
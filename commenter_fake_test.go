@@ -0,0 +1,21 @@
+package sql_test
+
+import (
+	"context"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestSQLCommenter(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	db.Use(sql.SQLCommenter(nil))
+	ctx := sql.WithCommentTags(context.Background(), map[string]string{"route": "/items"})
+	if err := db.Table("items").WithContext(ctx).Insert(&fakeItem{ID: 1, Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "INSERT INTO items(id, name) VALUES ($1, $2) /*route='%2Fitems'*/" {
+		t.Error(s.Query)
+	}
+}
@@ -0,0 +1,30 @@
+package sql
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAppendSQLComment(t *testing.T) {
+	tags := map[string]string{"traceparent": "00-0af7651916cd43dd-01", "route": "/users/{id}", "app": "it's"}
+	tests := map[string]string{
+		"SELECT 1":         "SELECT 1 /*app='it%27s',route='%2Fusers%2F%7Bid%7D',traceparent='00-0af7651916cd43dd-01'*/",
+		"SELECT 1;":        "SELECT 1 /*app='it%27s',route='%2Fusers%2F%7Bid%7D',traceparent='00-0af7651916cd43dd-01'*/;",
+		"/* c */ SELECT 1": "/* c */ SELECT 1",
+	}
+	for query, expected := range tests {
+		if got := appendSQLComment(query, tags); got != expected {
+			t.Error(got)
+		}
+	}
+
+	if got := appendSQLComment("SELECT 1", nil); got != "SELECT 1" {
+		t.Error(got)
+	}
+
+	ctx := WithCommentTags(context.Background(), map[string]string{"a": "1", "b": "2"})
+	ctx = WithCommentTags(ctx, map[string]string{"b": "3"})
+	if tags := CommentTags(ctx); len(tags) != 2 || tags["a"] != "1" || tags["b"] != "3" {
+		t.Error(tags)
+	}
+}
@@ -0,0 +1,327 @@
+package sql
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Conditions are expressions composed from request parameters safely, e.g.
+//
+//	cond := And(Eq("status", "active"), Or(Gt("age", 18), IsNull("deleted_at")))
+//	db.Select(&users, cond.SQL, cond.Args...)
+//
+// Values are always bound as args, and columns must be identifiers, optionally qualified, e.g. u.name.
+// Empty conditions, e.g. And() and conditions of nil, are true and skipped by And and Or
+
+// Eq returns condition column = value
+func Eq(column string, value interface{}) *Expression {
+	return compare(column, "=", value)
+}
+
+// Ne returns condition column <> value
+func Ne(column string, value interface{}) *Expression {
+	return compare(column, "<>", value)
+}
+
+// Gt returns condition column > value
+func Gt(column string, value interface{}) *Expression {
+	return compare(column, ">", value)
+}
+
+// Gte returns condition column >= value
+func Gte(column string, value interface{}) *Expression {
+	return compare(column, ">=", value)
+}
+
+// Lt returns condition column < value
+func Lt(column string, value interface{}) *Expression {
+	return compare(column, "<", value)
+}
+
+// Lte returns condition column <= value
+func Lte(column string, value interface{}) *Expression {
+	return compare(column, "<=", value)
+}
+
+// Like returns condition column LIKE pattern
+func Like(column string, pattern string) *Expression {
+	return compare(column, "LIKE", pattern)
+}
+
+// IsNull returns condition column IS NULL
+func IsNull(column string) *Expression {
+	return &Expression{SQL: checkCondColumn(column) + " IS NULL"}
+}
+
+// IsNotNull returns condition column IS NOT NULL
+func IsNotNull(column string) *Expression {
+	return &Expression{SQL: checkCondColumn(column) + " IS NOT NULL"}
+}
+
+// Between returns condition column BETWEEN from AND to
+func Between(column string, from, to interface{}) *Expression {
+	return &Expression{SQL: checkCondColumn(column) + " BETWEEN ? AND ?", Args: []interface{}{from, to}}
+}
+
+// In returns condition column IN (values...), values is a slice. It's false if values is empty
+func In(column string, values interface{}) *Expression {
+	return in(column, "IN", values, "1 = 0")
+}
+
+// NotIn returns condition column NOT IN (values...), values is a slice. It's true if values is empty
+func NotIn(column string, values interface{}) *Expression {
+	return in(column, "NOT IN", values, "")
+}
+
+// Contains returns condition column LIKE %s%, whose wildcards in s are escaped
+func Contains(column, s string) *Expression {
+	return like(column, "LIKE", "%"+EscapeLike(s)+"%")
+}
+
+// HasPrefix returns condition column LIKE s%, whose wildcards in s are escaped
+func HasPrefix(column, prefix string) *Expression {
+	return like(column, "LIKE", EscapeLike(prefix)+"%")
+}
+
+// HasSuffix returns condition column LIKE %s, whose wildcards in s are escaped
+func HasSuffix(column, suffix string) *Expression {
+	return like(column, "LIKE", "%"+EscapeLike(suffix))
+}
+
+// likeEscape escapes wildcards in LIKE patterns. Backslash isn't used as it's escaped differently in string literals
+// of mysql and postgres
+const likeEscape = '!'
+
+// EscapeLike escapes %, _, [ and ! in s for patterns of Contains, HasPrefix and HasSuffix, whose escape character is !.
+// Backslash is matched literally
+func EscapeLike(s string) string {
+	if !strings.ContainsAny(s, "%_[!") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '%', '_', '[', likeEscape:
+			b.WriteByte(likeEscape)
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func like(column, op, pattern string) *Expression {
+	return &Expression{
+		SQL:  checkCondColumn(column) + " " + op + " ? ESCAPE '" + string(likeEscape) + "'",
+		Args: []interface{}{pattern},
+	}
+}
+
+// ContainsFold is case-insensitive Contains, which is ILIKE on postgres. LIKE of mysql, sqlite and sqlserver is
+// case-insensitive by default collations, but sqlite only folds ASCII letters
+func (t *Table) ContainsFold(column, s string) *Expression {
+	return like(column, t.likeFold(), "%"+EscapeLike(s)+"%")
+}
+
+// HasPrefixFold is case-insensitive HasPrefix, see ContainsFold
+func (t *Table) HasPrefixFold(column, prefix string) *Expression {
+	return like(column, t.likeFold(), EscapeLike(prefix)+"%")
+}
+
+// HasSuffixFold is case-insensitive HasSuffix, see ContainsFold
+func (t *Table) HasSuffixFold(column, suffix string) *Expression {
+	return like(column, t.likeFold(), "%"+EscapeLike(suffix))
+}
+
+func (t *Table) likeFold() string {
+	if isPostgres(t.driverName) {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+
+// EqFold returns case-insensitive condition lower(column) = lower(value), which is portable across dialects
+// but can't use plain indexes of column. lower of sqlite only folds ASCII letters
+func EqFold(column string, value string) *Expression {
+	return &Expression{SQL: "lower(" + checkCondColumn(column) + ") = lower(?)", Args: []interface{}{value}}
+}
+
+// Collate returns column with collation, which can be the column of conditions, e.g.
+// Eq(Collate("name", "utf8mb4_general_ci"), name) on mysql, Eq(Collate("name", "NOCASE"), name) on sqlite,
+// or Eq(Collate("name", `"und-x-icu"`), name) on postgres. Collation is an identifier, which can be double quoted
+func Collate(column, collation string) string {
+	checkCondColumn(column)
+	if !isCollation(collation) {
+		panic("invalid collation: " + collation)
+	}
+	return column + " COLLATE " + collation
+}
+
+func isCollation(s string) bool {
+	if len(s) > 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return isValidIdent(s[1:len(s)-1], "\"", "\"")
+	}
+
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// And returns conjunction of conds
+func And(conds ...*Expression) *Expression {
+	return join(" AND ", conds)
+}
+
+// Or returns disjunction of conds, which is true if any of conds is empty
+func Or(conds ...*Expression) *Expression {
+	for _, c := range conds {
+		if c == nil || len(c.SQL) == 0 {
+			return &Expression{}
+		}
+	}
+	return join(" OR ", conds)
+}
+
+// Not returns negation of cond. It's false if cond is empty, as empty conditions are true
+func Not(cond *Expression) *Expression {
+	if cond == nil || len(cond.SQL) == 0 {
+		return &Expression{SQL: "1 = 0"}
+	}
+	return &Expression{SQL: "NOT (" + cond.SQL + ")", Args: cond.Args}
+}
+
+// Where returns conjunction of conditions of m, whose keys are columns optionally followed by operators, e.g.
+// Where(map[string]interface{}{"status": "active", "age >=": 21}) is (age >= ? AND status = ?).
+// Operators are =, <>, !=, >, >=, <, <=, LIKE, NOT LIKE, IN and NOT IN. Nil values of = and <> are IS NULL and IS NOT NULL,
+// and slices without operators are IN. Conditions are sorted by keys, so the same keys render the same sql
+func Where(m map[string]interface{}) *Expression {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	conds := make([]*Expression, len(keys))
+	for i, k := range keys {
+		conds[i] = mapCond(k, m[k])
+	}
+	return And(conds...)
+}
+
+// mapCond returns condition of key and value of Where
+func mapCond(key string, value interface{}) *Expression {
+	fields := strings.Fields(key)
+	if len(fields) == 0 {
+		panic("key is empty")
+	}
+
+	column := fields[0]
+	op := strings.ToUpper(strings.Join(fields[1:], " "))
+	if len(op) == 0 {
+		if v := reflect.ValueOf(value); value != nil && v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+			op = "IN"
+		} else {
+			op = "="
+		}
+	}
+
+	switch op {
+	case "=":
+		if value == nil {
+			return IsNull(column)
+		}
+		return Eq(column, value)
+	case "<>", "!=":
+		if value == nil {
+			return IsNotNull(column)
+		}
+		return Ne(column, value)
+	case ">", ">=", "<", "<=", "LIKE", "NOT LIKE":
+		return compare(column, op, value)
+	case "IN":
+		return In(column, value)
+	case "NOT IN":
+		return NotIn(column, value)
+	default:
+		panic("invalid operator: " + key)
+	}
+}
+
+func compare(column, op string, value interface{}) *Expression {
+	return &Expression{SQL: checkCondColumn(column) + " " + op + " ?", Args: []interface{}{value}}
+}
+
+func in(column, op string, values interface{}, empty string) *Expression {
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic("values must be a slice")
+	}
+
+	if v.Len() == 0 {
+		return &Expression{SQL: empty}
+	}
+
+	args := make([]interface{}, v.Len())
+	for i := range args {
+		args[i] = v.Index(i).Interface()
+	}
+	placeholders := strings.Repeat("?, ", len(args))
+	return &Expression{
+		SQL:  checkCondColumn(column) + " " + op + " (" + placeholders[:len(placeholders)-2] + ")",
+		Args: args,
+	}
+}
+
+func join(sep string, conds []*Expression) *Expression {
+	var parts []string
+	var args []interface{}
+	for _, c := range conds {
+		if c == nil || len(c.SQL) == 0 {
+			continue
+		}
+		parts = append(parts, c.SQL)
+		args = append(args, c.Args...)
+	}
+
+	switch len(parts) {
+	case 0:
+		return &Expression{}
+	case 1:
+		return &Expression{SQL: parts[0], Args: args}
+	default:
+		return &Expression{SQL: "(" + strings.Join(parts, sep) + ")", Args: args}
+	}
+}
+
+// checkCondColumn returns column, or panics if it's not an identifier optionally qualified by dots,
+// which may be followed by collation, see Collate
+func checkCondColumn(column string) string {
+	if len(column) == 0 {
+		panic("column is empty")
+	}
+
+	name := column
+	if i := strings.Index(column, " COLLATE "); i > 0 && isCollation(column[i+len(" COLLATE "):]) {
+		name = column[:i]
+	}
+
+	for _, part := range strings.Split(name, ".") {
+		if len(part) == 0 || part[0] >= '0' && part[0] <= '9' {
+			panic("invalid column: " + column)
+		}
+
+		for i := 0; i < len(part); i++ {
+			if !isIdentChar(part[i]) {
+				panic("invalid column: " + column)
+			}
+		}
+	}
+	return column
+}
@@ -0,0 +1,103 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCond(t *testing.T) {
+	c := And(Eq("status", "active"), Or(Gt("age", 18), IsNull("u.deleted_at")), In("role", []string{"a", "b"}))
+	if c.SQL != "(status = ? AND (age > ? OR u.deleted_at IS NULL) AND role IN (?, ?))" ||
+		!reflect.DeepEqual(c.Args, []interface{}{"active", 18, "a", "b"}) {
+		t.Error(c.SQL, c.Args)
+	}
+
+	c = And(nil, Not(Between("age", 1, 2)), NotIn("id", []int64{}), And())
+	if c.SQL != "NOT (age BETWEEN ? AND ?)" || len(c.Args) != 2 {
+		t.Error(c.SQL, c.Args)
+	}
+
+	if c = Or(Eq("a", 1), And()); c.SQL != "" {
+		t.Error(c.SQL)
+	}
+
+	if c = In("id", []int{}); c.SQL != "1 = 0" {
+		t.Error(c.SQL)
+	}
+
+	if c = Not(And()); c.SQL != "1 = 0" || len(c.Args) != 0 {
+		t.Error(c.SQL, c.Args)
+	}
+
+	if c = And(Eq("a", 1), Not(nil)); c.SQL != "(a = ? AND 1 = 0)" {
+		t.Error(c.SQL)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic")
+		}
+	}()
+	Eq("name; DROP TABLE users", 1)
+}
+
+func TestWhere(t *testing.T) {
+	c := Where(map[string]interface{}{
+		"status":        "active",
+		"age >=":        21,
+		"role":          []string{"a", "b"},
+		"deleted_at":    nil,
+		"name not like": "x%",
+	})
+	if c.SQL != "(age >= ? AND deleted_at IS NULL AND name NOT LIKE ? AND role IN (?, ?) AND status = ?)" ||
+		!reflect.DeepEqual(c.Args, []interface{}{21, "x%", "a", "b", "active"}) {
+		t.Error(c.SQL, c.Args)
+	}
+
+	if c = Where(nil); c.SQL != "" {
+		t.Error(c.SQL)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic")
+		}
+	}()
+	Where(map[string]interface{}{"age; --": 1})
+}
+
+func TestContains(t *testing.T) {
+	c := And(Contains("name", `50%_off\`), HasPrefix("code", "a[b"), HasSuffix("email", "@x.com"))
+	if c.SQL != "(name LIKE ? ESCAPE '!' AND code LIKE ? ESCAPE '!' AND email LIKE ? ESCAPE '!')" ||
+		!reflect.DeepEqual(c.Args, []interface{}{`%50!%!_off\%`, "a![b%", "%@x.com"}) {
+		t.Error(c.SQL, c.Args)
+	}
+
+	pg := &Table{driverName: "postgres", name: "users"}
+	if c = pg.ContainsFold("name", "!"); c.SQL != "name ILIKE ? ESCAPE '!'" || c.Args[0] != "%!!%" {
+		t.Error(c.SQL, c.Args)
+	}
+
+	mysql := &Table{driverName: "mysql", name: "users"}
+	if c = mysql.HasPrefixFold("name", "a"); c.SQL != "name LIKE ? ESCAPE '!'" || c.Args[0] != "a%" {
+		t.Error(c.SQL, c.Args)
+	}
+}
+
+func TestCollate(t *testing.T) {
+	c := And(Eq(Collate("u.name", "utf8mb4_general_ci"), "Tom"), In(Collate("code", `"und-x-icu"`), []string{"a"}))
+	if c.SQL != `(u.name COLLATE utf8mb4_general_ci = ? AND code COLLATE "und-x-icu" IN (?))` {
+		t.Error(c.SQL)
+	}
+
+	if c = EqFold("email", "A@x.com"); c.SQL != "lower(email) = lower(?)" || c.Args[0] != "A@x.com" {
+		t.Error(c.SQL, c.Args)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic")
+		}
+	}()
+	Eq("name COLLATE x; DROP TABLE users", 1)
+}
@@ -0,0 +1,319 @@
+package sql
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/gopub/log"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVOptions are options of ImportCSV
+type CSVOptions struct {
+	// Comma is the field delimiter, default is ','
+	Comma rune
+
+	// NoHeader is true if the first row isn't header, then Columns must be set
+	NoHeader bool
+
+	// Columns are table columns of fields in order, which override header. Fields of column "-" are skipped
+	Columns []string
+
+	// Mapping renames header fields to table columns, e.g. {"Product Name": "name"}
+	Mapping map[string]string
+
+	// Record is a struct whose field types coerce values of the same columns, e.g. "1.5" into float64.
+	// Values are strings if it's nil
+	Record interface{}
+
+	// EmptyAsNull imports empty fields as NULL
+	EmptyAsNull bool
+
+	// BatchSize is the number of rows per INSERT, default is 500
+	BatchSize int
+}
+
+// ExportCSV writes rows matching where into w with a header of column names.
+// Columns are set by Columns, default is *. NULL is written as empty field
+func (t *Table) ExportCSV(w io.Writer, where string, args ...interface{}) error {
+	if err := t.checkWhere(nil, where, args); err != nil {
+		log.Error(err)
+		return err
+	}
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(nil, where, args)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	query, args := t.buildSelectQuery(&columnInfo{names: t.columns}, where, args)
+	t.logQuery(query, args)
+
+	ctx, cancel := t.context()
+	defer cancel()
+	rows, err := t.reader.QueryContext(ctx, t.annotate(query), args...)
+	if err != nil {
+		err = t.queryError(query, args, err)
+		log.Error(err)
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err = cw.Write(columns); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	addrs := make([]interface{}, len(columns))
+	for i := range values {
+		addrs[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	for rows.Next() {
+		if err = rows.Scan(addrs...); err != nil {
+			log.Error(err)
+			return err
+		}
+
+		for i, v := range values {
+			record[i] = formatCSVValue(v)
+		}
+
+		if err = cw.Write(record); err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		err = t.queryError(query, args, err)
+		log.Error(err)
+		return err
+	}
+
+	cw.Flush()
+	if err = cw.Error(); err != nil {
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+func formatCSVValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ImportCSV inserts rows read from r in a transaction, and returns the number of inserted rows
+func (t *Table) ImportCSV(r io.Reader, opts *CSVOptions) (int64, error) {
+	if opts == nil {
+		opts = &CSVOptions{}
+	}
+
+	if err := t.checkScopedWrite(); err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+
+	columns := opts.Columns
+	line := 0
+	if !opts.NoHeader {
+		header, err := cr.Read()
+		if err != nil {
+			log.Error(err)
+			return 0, err
+		}
+		line++
+
+		if len(columns) == 0 {
+			columns = make([]string, len(header))
+			for i, h := range header {
+				h = strings.TrimSpace(h)
+				if c, ok := opts.Mapping[h]; ok {
+					columns[i] = c
+				} else {
+					columns[i] = h
+				}
+			}
+		}
+	}
+
+	if len(columns) == 0 {
+		panic("columns are required if there's no header")
+	}
+
+	var fieldIndexes []int
+	var names []string
+	for i, c := range columns {
+		if c != "-" {
+			fieldIndexes = append(fieldIndexes, i)
+			names = append(names, c)
+		}
+	}
+
+	types := make([]reflect.Type, len(names))
+	if opts.Record != nil {
+		typ := reflect.TypeOf(opts.Record)
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		info := getColumnInfo(typ)
+		for i, name := range names {
+			if idx, ok := info.nameToIndex[name]; ok {
+				types[i] = typ.FieldByIndex(idx).Type
+			}
+		}
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	if max := t.maxParams() / len(names); batchSize > max {
+		batchSize = max
+	}
+
+	var n int64
+	err := t.withTx(func(c *Table) error {
+		var values []interface{}
+		rowCount := 0
+		flush := func() error {
+			if rowCount == 0 {
+				return nil
+			}
+
+			query := c.prepareInsertRowsQuery(names, rowCount)
+			c.logQuery(query, values)
+			if _, err := c.exec(query, values...); err != nil {
+				return err
+			}
+			n += int64(rowCount)
+			values = values[:0]
+			rowCount = 0
+			return nil
+		}
+
+		for {
+			record, err := cr.Read()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return err
+			}
+			line++
+
+			for i, fi := range fieldIndexes {
+				if fi >= len(record) {
+					return fmt.Errorf("line %d: missing field of column %s", line, names[i])
+				}
+
+				v, err := coerceCSVValue(record[fi], types[i], opts.EmptyAsNull)
+				if err != nil {
+					return fmt.Errorf("line %d, column %s: %w", line, names[i], err)
+				}
+				values = append(values, v)
+			}
+
+			if rowCount++; rowCount == batchSize {
+				if err = flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return flush()
+	})
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// prepareInsertRowsQuery returns INSERT query of columns with placeholders of n rows
+func (t *Table) prepareInsertRowsQuery(columns []string, n int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("INSERT INTO ")
+	buf.WriteString(t.quote(t.name))
+	buf.WriteString("(")
+	buf.WriteString(t.quoteColumns(columns))
+	buf.WriteString(") VALUES ")
+	buf.WriteString(strings.TrimSuffix(strings.Repeat(row+", ", n), ", "))
+	return buf.String()
+}
+
+var _csvTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+// coerceCSVValue parses s into value of typ. s is returned if typ is nil or not a basic type
+func coerceCSVValue(s string, typ reflect.Type, emptyAsNull bool) (interface{}, error) {
+	if s == "" && (emptyAsNull || (typ != nil && typ.Kind() == reflect.Ptr)) {
+		return nil, nil
+	}
+
+	if typ == nil {
+		return s, nil
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == reflect.TypeOf(time.Time{}) {
+		for _, layout := range _csvTimeLayouts {
+			if v, err := time.Parse(layout, s); err == nil {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid time: %s", s)
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(s, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(s, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	default:
+		return s, nil
+	}
+}
+
+func (d *DB) ExportCSV(table string, w io.Writer, where string, args ...interface{}) error {
+	return d.Table(table).ExportCSV(w, where, args...)
+}
+
+func (d *DB) ImportCSV(table string, r io.Reader, opts *CSVOptions) (int64, error) {
+	return d.Table(table).ImportCSV(r, opts)
+}
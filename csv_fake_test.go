@@ -0,0 +1,53 @@
+package sql_test
+
+import (
+	"bytes"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTable_CSV(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a,b"}, []interface{}{2, nil})
+	var buf bytes.Buffer
+	if err := db.ExportCSV("items", &buf, "id > ?", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "id,name\n1,\"a,b\"\n2,\n" {
+		t.Errorf("%q", buf.String())
+	}
+
+	f.Reset()
+	opts := &sql.CSVOptions{
+		Mapping:     map[string]string{"Item Name": "name"},
+		Record:      &fakeItem{},
+		EmptyAsNull: true,
+		BatchSize:   2,
+	}
+	n, err := db.ImportCSV("items", strings.NewReader("id,Item Name\n1,a\n2,\n3,c\n"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 3 {
+		t.Error(n)
+	}
+
+	statements := f.Statements()
+	if len(statements) != 4 || statements[0].Query != "BEGIN" || statements[3].Query != "COMMIT" {
+		t.Fatal(statements)
+	}
+
+	if s := statements[1]; s.Query != "INSERT INTO items(id, name) VALUES (?, ?), (?, ?)" ||
+		!reflect.DeepEqual(s.Args, []interface{}{int64(1), "a", int64(2), nil}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	if _, err = db.ImportCSV("items", strings.NewReader("id,name\nx,a\n"), opts); err == nil {
+		t.Error("expected error")
+	}
+}
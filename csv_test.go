@@ -0,0 +1,39 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCoerceCSVValue(t *testing.T) {
+	tests := []struct {
+		s           string
+		typ         reflect.Type
+		emptyAsNull bool
+		expected    interface{}
+	}{
+		{"12", reflect.TypeOf(0), false, int64(12)},
+		{"1.5", reflect.TypeOf(float32(0)), false, 1.5},
+		{"true", reflect.TypeOf(false), false, true},
+		{"2020-01-02", reflect.TypeOf(time.Time{}), false, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"", reflect.TypeOf(new(int)), false, nil},
+		{"", reflect.TypeOf(""), false, ""},
+		{"", nil, true, nil},
+		{"12", nil, false, "12"},
+	}
+	for _, test := range tests {
+		v, err := coerceCSVValue(test.s, test.typ, test.emptyAsNull)
+		if err != nil {
+			t.Fatal(test.s, err)
+		}
+
+		if !reflect.DeepEqual(v, test.expected) {
+			t.Error(test.s, v)
+		}
+	}
+
+	if _, err := coerceCSVValue("x", reflect.TypeOf(0), false); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,81 @@
+package sql
+
+import (
+	"strings"
+)
+
+// commonTable is a common table expression declared by WITH clause
+type commonTable struct {
+	name      string
+	sub       *Expression
+	recursive bool
+}
+
+// With returns a copy of t whose Select, SelectOne, Iterate and Count declare common table expression name as sub,
+// which can be referenced by t, its joins and where clause, e.g.
+//
+//	recent := db.Table("orders").Subquery("created_at > ?", since)
+//	db.Table("recent").With("recent", recent).Select(&orders, "amount > ?", 100)
+func (t *Table) With(name string, sub *Expression) *Table {
+	return t.appendWith(name, sub, false)
+}
+
+// WithRecursive declares recursive common table expression name as sub, which references name itself, e.g. a category tree
+//
+//	tree := sql.Expr("SELECT id, parent_id FROM categories WHERE id = ? " +
+//		"UNION ALL SELECT c.id, c.parent_id FROM categories c JOIN tree ON c.parent_id = tree.id", rootID)
+//	db.Table("tree").WithRecursive("tree", tree).Select(&categories, "")
+func (t *Table) WithRecursive(name string, sub *Expression) *Table {
+	return t.appendWith(name, sub, true)
+}
+
+func (t *Table) appendWith(name string, sub *Expression, recursive bool) *Table {
+	if !isPlainIdent(name) {
+		panic("invalid name: " + name)
+	}
+
+	if sub == nil {
+		panic("sub is nil")
+	}
+
+	c := *t
+	c.ctes = append(append([]commonTable(nil), t.ctes...), commonTable{name: name, sub: sub, recursive: recursive})
+
+	// common tables aren't qualified by default schema
+	if fields := strings.Fields(t.name); len(fields) > 0 {
+		if _, table := splitQualified(fields[0]); table == name {
+			fields[0] = name
+			c.name = strings.Join(fields, " ")
+		}
+	}
+	return &c
+}
+
+// withClause returns WITH clause followed by a space, or empty string if there's no common table
+func (t *Table) withClause() string {
+	if len(t.ctes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("WITH ")
+	for _, c := range t.ctes {
+		// sqlserver doesn't have RECURSIVE keyword
+		if c.recursive && t.driverName != "sqlserver" && t.driverName != "mssql" {
+			b.WriteString("RECURSIVE ")
+			break
+		}
+	}
+
+	for i, c := range t.ctes {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(c.name)
+		b.WriteString(" AS (")
+		b.WriteString(c.sub.SQL)
+		b.WriteString(")")
+	}
+	b.WriteString(" ")
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package sql
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/gopub/log"
+	"reflect"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor encodes values into an opaque url-safe token
+func EncodeCursor(values ...interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes values from cursor encoded by EncodeCursor. Numbers are decoded as int64 or float64,
+// and times are decoded as strings in RFC 3339 format
+func DecodeCursor(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var values []interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err = dec.Decode(&values); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, v := range values {
+		// json.Number is bound as string, which may not be compared with numeric column
+		if n, ok := v.(json.Number); ok {
+			if x, err := n.Int64(); err == nil {
+				values[i] = x
+			} else if f, err := n.Float64(); err == nil {
+				values[i] = f
+			}
+		}
+	}
+	return values, nil
+}
+
+// SelectAfter selects at most limit rows after cursor in order of column, e.g. "created_at DESC", and returns cursor of
+// the next page, which is empty if there are no more rows. cursor is empty for the first page.
+// Primary key is the tiebreaker if column isn't unique
+func (t *Table) SelectAfter(records interface{}, column, cursor string, limit int, where string, args ...interface{}) (string, error) {
+	if limit <= 0 {
+		panic("limit must be positive")
+	}
+
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		panic("must be a pointer to slice")
+	}
+
+	info := getColumnInfo(getSliceElemType(records))
+	fields := strings.Fields(column)
+	if len(fields) == 0 || len(fields) > 2 {
+		panic("invalid column: " + column)
+	}
+
+	keys := []string{fields[0]}
+	if _, ok := info.nameToIndex[keys[0]]; !ok {
+		panic("unknown column: " + keys[0])
+	}
+
+	if pk := singlePKName(info); pk != keys[0] {
+		keys = append(keys, pk)
+	}
+
+	op, dir := ">", ""
+	if len(fields) == 2 && strings.ToUpper(fields[1]) == "DESC" {
+		op, dir = "<", " DESC"
+	}
+
+	orderBy := make([]string, len(keys))
+	for i, k := range keys {
+		orderBy[i] = t.quote(k) + dir
+	}
+
+	cond := where
+	if len(cursor) > 0 {
+		values, err := DecodeCursor(cursor)
+		if err == nil && len(values) != len(keys) {
+			err = ErrInvalidCursor
+		}
+
+		if err != nil {
+			log.Error(err)
+			return "", err
+		}
+
+		var c string
+		if len(keys) == 1 {
+			c = t.quote(keys[0]) + " " + op + " ?"
+		} else {
+			c = "(" + t.quoteColumns(keys) + ") " + op + " (?, ?)"
+		}
+
+		if len(where) > 0 {
+			cond = "(" + where + ") AND " + c
+		} else {
+			cond = c
+		}
+		args = append(append([]interface{}(nil), args...), values...)
+	}
+
+	v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	if err := t.OrderBy(orderBy...).Limit(limit+1).Select(records, cond, args...); err != nil {
+		return "", err
+	}
+
+	l := v.Elem()
+	if l.Len() <= limit {
+		return "", nil
+	}
+
+	l.Set(l.Slice(0, limit))
+	last := reflect.Indirect(l.Index(limit - 1))
+	values := make([]interface{}, len(keys))
+	for i, k := range keys {
+		values[i] = last.FieldByIndex(info.nameToIndex[k]).Interface()
+	}
+	return EncodeCursor(values...), nil
+}
+
+func (d *DB) SelectAfter(records interface{}, column, cursor string, limit int, where string, args ...interface{}) (string, error) {
+	return d.Table(getTableNameBySlice(records)).SelectAfter(records, column, cursor, limit, where, args...)
+}
+
+func (t *Tx) SelectAfter(records interface{}, column, cursor string, limit int, where string, args ...interface{}) (string, error) {
+	return t.Table(getTableNameBySlice(records)).SelectAfter(records, column, cursor, limit, where, args...)
+}
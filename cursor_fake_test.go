@@ -0,0 +1,39 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+func TestTable_SelectAfter(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	columns := []string{"id", "name"}
+	f.On("SELECT").Returns(columns, []interface{}{3, "c"}, []interface{}{2, "b"}, []interface{}{1, "a"}).Once()
+	f.On("SELECT").Returns(columns, []interface{}{1, "a"}).Once()
+
+	var items []*fakeItem
+	cursor, err := db.Table("items").SelectAfter(&items, "name DESC", "", 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 || items[1].Name != "b" || len(cursor) == 0 {
+		t.Fatal(items, cursor)
+	}
+
+	cursor, err = db.Table("items").SelectAfter(&items, "name DESC", cursor, 2, "id > ?", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 || items[0].Name != "a" || len(cursor) != 0 {
+		t.Error(items, cursor)
+	}
+
+	s := f.LastStatement()
+	if s.Query != `SELECT id, name FROM items WHERE (id > $1) AND (name, id) < ($2, $3) ORDER BY name DESC, id DESC LIMIT 3` ||
+		!reflect.DeepEqual(s.Args, []interface{}{int64(0), "b", int64(2)}) {
+		t.Error(s.Query, s.Args)
+	}
+}
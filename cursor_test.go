@@ -0,0 +1,24 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := EncodeCursor(int64(1)<<60, 1.5, "it's")
+	values, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(values, []interface{}{int64(1) << 60, 1.5, "it's"}) {
+		t.Error(values)
+	}
+
+	for _, c := range []string{"!", EncodeCursor()[:1] + "x"} {
+		if _, err = DecodeCursor(c); err != ErrInvalidCursor {
+			t.Error(c, err)
+		}
+	}
+}
@@ -1,18 +1,100 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/gopub/log"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 )
 
 var ErrNoRows = sql.ErrNoRows
+var ErrTooManyRows = errors.New("too many rows")
 
 var _tableNamingType = reflect.TypeOf((*tableNaming)(nil)).Elem()
 
 type DB struct {
 	db         *sql.DB
 	driverName string
+
+	//multiStatements is enabled in data source name
+	multiStatements bool
+	batchSize       int
+
+	writeBuffers sync.Map //table name:*writeBuffer
+
+	replicas *replicaSet
+
+	notificationTableCreated int32
+	notificationsPrunedAt    int64
+
+	opts *options
+}
+
+// options are shared by DB and its Tx and Table
+type options struct {
+	strictOrder  bool
+	strictWhere  bool
+	queryTimeout time.Duration
+
+	//interpolatedLog logs statements with args substituted
+	interpolatedLog bool
+
+	middlewares []Middleware
+
+	//tenant extracts tenant id from context, see DB.SetTenantScope
+	tenant func(ctx context.Context) (interface{}, bool)
+
+	//tenantTables maps tables of models passed to SetTenantScope to their tenant columns, which are empty for shared tables
+	tenantTables map[string]string
+
+	//cipher of encrypted columns
+	cipher Cipher
+
+	//cache stores results of tables set by Table.Cache
+	cache CacheStore
+
+	//schema qualifies table names, see DB.SetSchema
+	schema string
+
+	//pollInterval is interval of polling notifications, see DB.Listen
+	pollInterval time.Duration
+
+	//notificationRetention is how long polled notifications are kept, see DB.SetNotificationRetention
+	notificationRetention time.Duration
+
+	//timeLocation is location of time text without time zone, see DB.SetTimeLocation
+	timeLocation *time.Location
+
+	//bindArgs binds args of driver specific types, see argBinder
+	bindArgs func(args []interface{}) []interface{}
+
+	//validation is level of validating statements, see DB.SetValidation
+	validation ValidationLevel
+
+	//safeMode rejects statements changing all rows without Table.AllRows, see DB.SetSafeMode
+	safeMode bool
+
+	//readOnly rejects statements changing data, see DB.ReadOnly
+	readOnly bool
+
+	//idGenerators overrides built-in generators of ids, see DB.SetIDGenerator
+	idGenerators map[string]IDGenerator
+
+	//keys resolves primary keys of tables for after images of audit, see Audit
+	keys *keyResolver
+}
+
+// context returns child context of parent with the default query timeout, cancel must be called after the statement is done
+func (o *options) context(parent context.Context) (context.Context, context.CancelFunc) {
+	if o.queryTimeout > 0 {
+		return context.WithTimeout(parent, o.queryTimeout)
+	}
+	return parent, func() {}
 }
 
 // Open opens database
@@ -23,94 +105,206 @@ func Open(driverName, dataSourceName string) (*DB, error) {
 		return nil, err
 	}
 
-	return &DB{
+	d := OpenDB(driverName, db)
+	d.multiStatements = driverName == "mysql" && hasMultiStatements(dataSourceName)
+	return d, nil
+}
+
+// OpenDB wraps db which is opened by driver of any name, e.g. a test double, driverName decides the sql dialect
+func OpenDB(driverName string, db *sql.DB) *DB {
+	d := &DB{
 		db:         db,
 		driverName: driverName,
-	}, nil
+		opts:       &options{bindArgs: argBinder(driverName)},
+	}
+	d.opts.keys = &keyResolver{db: d}
+	return d
 }
 
 func MustOpen(driverName, dataSourceName string) *DB {
-	db, err := sql.Open(driverName, dataSourceName)
+	db, err := Open(driverName, dataSourceName)
 	if err != nil {
 		panic(err)
 	}
-
-	return &DB{
-		db:         db,
-		driverName: driverName,
-	}
+	return db
 }
 
 func (d *DB) SQLDB() *sql.DB {
 	return d.db
 }
 
+// DriverName returns name of the driver, which decides the sql dialect
+func (d *DB) DriverName() string {
+	return d.driverName
+}
+
 func (d *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
-	log.Debug(query, args)
-	return d.db.Exec(query, args...)
+	return d.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContext executes query in ctx with the default query timeout
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	d.opts.logQuery(d.driverName, query, args)
+	ctx, cancel := d.opts.context(ctx)
+	defer cancel()
+	return d.opts.wrap(d.db).ExecContext(ctx, query, args...)
+}
+
+// Query executes a query on replica if there is any. The default query timeout isn't applied, as rows are read
+// after Query returns, use Session with a context of deadline instead
+func (d *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext executes a query in ctx like Query
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	d.opts.logQuery(d.driverName, query, args)
+	return d.opts.wrap(d.reader()).QueryContext(ctx, query, args...)
+}
+
+// QueryRow executes a query on replica if there is any. The default query timeout isn't applied like Query
+func (d *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes a query in ctx like QueryRow
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	d.opts.logQuery(d.driverName, query, args)
+	return d.opts.wrap(d.reader()).QueryRowContext(ctx, query, args...)
 }
 
 func (d *DB) MustExec(query string, args ...interface{}) {
-	_, err := d.db.Exec(query, args...)
+	_, err := d.Exec(query, args...)
 	if err != nil {
 		panic(err)
 	}
 }
 
 func (d *DB) Begin() (*Tx, error) {
-	tx, err := d.db.Begin()
+	return d.BeginTx(context.Background(), nil)
+}
+
+// BeginTx begins a transaction with opts, e.g. &TxOptions{Isolation: LevelSerializable, ReadOnly: true}.
+// nil opts means driver's default. The transaction is rolled back once ctx is done before it's committed,
+// then its statements return TxAbortedError
+func (d *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
+	tx, err := d.db.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Tx{
-		tx:         tx,
-		driverName: d.driverName,
-	}, nil
+	return newTx(tx, ctx, d.driverName, d.opts), nil
+}
+
+// SetStrictOrder makes Select append primary key columns to ORDER BY as tiebreaker,
+// so that paginated results are stable when rows have identical sort values
+func (d *DB) SetStrictOrder(strict bool) {
+	d.opts.strictOrder = strict
+}
+
+// SetDefaultQueryTimeout sets timeout of every statement, after which the statement is canceled.
+// It can be overridden by Table.Timeout and per-call options. d <= 0 disables the timeout.
+// It isn't applied to Query and QueryRow, whose rows are read by callers after they return
+func (d *DB) SetDefaultQueryTimeout(timeout time.Duration) {
+	d.opts.queryTimeout = timeout
+}
+
+// SetInterpolatedLog makes debug logs render statements with args substituted as escaped literals,
+// which can be copied into a sql client. Statements are still executed with bound args
+func (d *DB) SetInterpolatedLog(interpolated bool) {
+	d.opts.interpolatedLog = interpolated
+}
+
+// SetStrictWhere makes statements return WhereError if placeholders of where clause don't match args, or quotes and parentheses aren't balanced.
+// Select and SelectOne also return WhereError if where clause references columns not mapped by the struct
+func (d *DB) SetStrictWhere(strict bool) {
+	d.opts.strictWhere = strict
 }
 
+// SetSchema sets schema of tables whose names aren't qualified, e.g. Table("events") is analytics.events
+// if schema is analytics. It's database for mysql, and attached database for sqlite3.
+// It's also used by introspection and package migrate, so it must be called before d is used
+func (d *DB) SetSchema(schema string) {
+	if len(schema) > 0 && (strings.ContainsAny(schema, ".'\"`[]") || !isValidIdent(schema, "\"", "\"")) {
+		panic("invalid schema: " + schema)
+	}
+	d.opts.schema = schema
+}
+
+// Close flushes write-behind buffers and closes database
 func (d *DB) Close() error {
+	d.writeBuffers.Range(func(key, value interface{}) bool {
+		value.(*writeBuffer).close()
+		return true
+	})
+	if d.replicas != nil {
+		d.replicas.close()
+	}
 	return d.db.Close()
 }
 
 func (d *DB) Table(name string) *Table {
 	return &Table{
-		exe:        d.db,
+		exe:        d.opts.wrap(d.db),
+		reader:     d.opts.wrap(d.reader()),
+		db:         d.db,
 		driverName: d.driverName,
-		name:       name,
+		name:       d.opts.qualify(name),
+		opts:       d.opts,
 	}
 }
 
 func (d *DB) Insert(record interface{}) error {
-	return d.Table(getTableName(record)).Insert(record)
+	name := getTableName(record)
+	if b, ok := d.writeBuffers.Load(name); ok {
+		return b.(*writeBuffer).add(record)
+	}
+	return d.Table(name).Insert(record)
 }
 
+// Flush blocks until records in write-behind buffers are inserted
+func (d *DB) Flush() {
+	d.writeBuffers.Range(func(key, value interface{}) bool {
+		value.(*writeBuffer).flush()
+		return true
+	})
+}
+
+// MultiInsert inserts values within a transaction, which is rolled back at the first failed record.
+// The failed record is reported by MultiError unless statements are batched, see SetMultiStatementBatchSize
 func (d *DB) MultiInsert(values ...interface{}) error {
-	tx, err := d.Begin()
-	for _, v := range values {
-		err = tx.Insert(v)
-		if err != nil {
-			tx.Rollback()
-			return err
-		}
+	if d.batchSize > 1 {
+		return d.batchExec(values, (*Table).prepareInsertQuery, (*Tx).Insert)
 	}
-	return tx.Commit()
+
+	return d.multiExec(values, (*Tx).Insert)
 }
 
 func (d *DB) Update(record interface{}) error {
 	return d.Table(getTableName(record)).Update(record)
 }
 
+// MultiUpdate updates values within a transaction like MultiInsert
 func (d *DB) MultiUpdate(values ...interface{}) error {
-	tx, err := d.Begin()
-	for _, v := range values {
-		err = tx.Update(v)
-		if err != nil {
-			tx.Rollback()
-			return err
-		}
+	if d.batchSize > 1 {
+		return d.batchExec(values, (*Table).prepareUpdateQuery, nil)
 	}
-	return tx.Commit()
+
+	return d.multiExec(values, (*Tx).Update)
+}
+
+// Delete deletes the row of record by its primary key
+func (d *DB) Delete(record interface{}) error {
+	return d.Table(getTableName(record)).DeleteRecord(record)
+}
+
+// MultiDelete deletes rows of values by their primary keys within a transaction like MultiInsert
+func (d *DB) MultiDelete(values ...interface{}) error {
+	if d.batchSize > 1 {
+		return d.batchExec(values, (*Table).prepareDeleteRecordQuery, nil)
+	}
+
+	return d.multiExec(values, (*Tx).Delete)
 }
 
 func (d *DB) Save(record interface{}) error {
@@ -118,21 +312,64 @@ func (d *DB) Save(record interface{}) error {
 }
 
 func (d *DB) MultiSave(values ...interface{}) error {
-	tx, err := d.Begin()
-	for _, v := range values {
-		err = tx.Save(v)
-		if err != nil {
-			tx.Rollback()
+	return d.MultiSaveWithStrategy(UpdateOnConflict, values...)
+}
+
+// MultiSaveWithStrategy saves values within a transaction like MultiInsert, conflicting records are handled by strategy
+func (d *DB) MultiSaveWithStrategy(strategy ConflictStrategy, values ...interface{}) error {
+	switch strategy {
+	case UpdateOnConflict:
+		if d.batchSize > 1 {
+			return d.batchExec(values, (*Table).prepareMysqlSaveQuery, (*Tx).Save)
+		}
+		return d.multiExec(values, (*Tx).Save)
+	case SkipConflicts:
+		insertIgnore := func(tx *Tx, record interface{}) error {
+			_, err := tx.InsertIgnore(record)
 			return err
 		}
+		if d.batchSize > 1 {
+			return d.batchExec(values, (*Table).prepareInsertIgnoreQuery, insertIgnore)
+		}
+		return d.multiExec(values, insertIgnore)
+	case FailFast:
+		return d.MultiInsert(values...)
+	default:
+		err := fmt.Errorf("invalid conflict strategy: %d", strategy)
+		log.Error(err)
+		return err
 	}
-	return tx.Commit()
+}
+
+// From returns a table selecting from subquery sub as alias, e.g.
+// db.From(db.Table("orders").Columns("user_id").GroupBy("user_id").Subquery(""), "t")
+func (d *DB) From(sub *Expression, alias string) *Table {
+	t := d.Table(alias)
+	t.name = alias
+	t.source = sub
+	return t
 }
 
 func (d *DB) Select(records interface{}, where string, args ...interface{}) error {
 	return d.Table(getTableNameBySlice(records)).Select(records, where, args...)
 }
 
+func (d *DB) SelectColumns(records interface{}, columns []string, where string, args ...interface{}) error {
+	return d.Table(getTableNameBySlice(records)).SelectColumns(records, columns, where, args...)
+}
+
+func (d *DB) FindInBatches(records interface{}, batchSize int, fn func(batch int) error, where string, args ...interface{}) error {
+	return d.Table(getTableNameBySlice(records)).FindInBatches(records, batchSize, fn, where, args...)
+}
+
+func (d *DB) SelectAtMost(records interface{}, n int, where string, args ...interface{}) error {
+	return d.Table(getTableNameBySlice(records)).SelectAtMost(records, n, where, args...)
+}
+
+func (d *DB) Get(record interface{}, pk ...interface{}) error {
+	return d.Table(getTableName(record)).Get(record, pk...)
+}
+
 func (d *DB) SelectOne(record interface{}, where string, args ...interface{}) error {
 	return d.Table(getTableName(record)).SelectOne(record, where, args...)
 }
@@ -1,10 +1,15 @@
 package sql_test
 
 import (
+	"context"
+	"errors"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
 	"github.com/gopub/types"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -51,6 +56,11 @@ func (i Item) TableName() string {
 	return "products"
 }
 
+type fakeItem struct {
+	ID   int64 `sql:"primary key"`
+	Name string
+}
+
 func TestMain(m *testing.M) {
 	var err error
 	_testDB, err = sql.Open("mysql", "root:7815@tcp(localhost:3306)/test")
@@ -61,6 +71,17 @@ func TestMain(m *testing.M) {
 	os.Exit(r)
 }
 
+func TestDB_HealthCheck(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status, err := _testDB.HealthCheck(ctx, true)
+	if err != nil {
+		t.Error(err)
+		t.Fail()
+	}
+	t.Log(status.Latency, status.OpenConnections)
+}
+
 func TestDB_Exec(t *testing.T) {
 	_testDB.MustExec("drop table products")
 	_testDB.Exec(`CREATE TABLE IF NOT EXISTS products(
@@ -79,6 +100,25 @@ func TestDB_Exec(t *testing.T) {
 	)`)
 }
 
+func TestDB_Columns(t *testing.T) {
+	tables, err := _testDB.Tables()
+	if err != nil {
+		t.Error(err)
+		t.Fail()
+	}
+	t.Log(tables)
+
+	columns, err := _testDB.Columns("products")
+	if err != nil {
+		t.Error(err)
+		t.Fail()
+	}
+
+	if len(columns) != 6 || columns[0].Name != "id" || columns[0].Key != "PRI" || !columns[4].Nullable {
+		t.Fail()
+	}
+}
+
 var _testProduct = &Product{
 	Name:      "apple",
 	Price:     0.1,
@@ -238,6 +278,83 @@ func TestExecutor_Select(t *testing.T) {
 	}
 }
 
+func TestDB_SelectAtMost(t *testing.T) {
+	var items []*Product
+	err := _testDB.SelectAtMost(&items, 0, "")
+	if err != sql.ErrTooManyRows {
+		t.Error(err)
+		t.Fail()
+	}
+
+	if len(items) != 0 {
+		t.Fail()
+	}
+}
+
+func TestTable_Paginate(t *testing.T) {
+	_testDB.SetStrictOrder(true)
+	defer _testDB.SetStrictOrder(false)
+	var items []*Product
+	err := _testDB.Table("products").OrderBy("price DESC").Paginate(2, 10).Select(&items, "")
+	if err != nil {
+		t.Error(err)
+		t.Fail()
+	}
+
+	if len(items) > 10 {
+		t.Fail()
+	}
+}
+
+func TestDB_Get(t *testing.T) {
+	p := &Product{Name: "get", Price: 2}
+	if err := _testDB.Insert(p); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	var got *Product
+	if err := _testDB.Get(&got, p.ID); err != nil || got.Name != p.Name {
+		t.Error(err)
+		t.Fail()
+	}
+
+	if err := _testDB.Delete(p); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if err := _testDB.Get(&got, p.ID); err != sql.ErrNoRows {
+		t.Error(err)
+		t.Fail()
+	}
+}
+
+func TestTypedTable(t *testing.T) {
+	products := sql.NewTable[Product](_testDB)
+	p := &Product{Name: "typed", Price: 1.5}
+	if err := products.Insert(p); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	got, err := products.Get(p.ID)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+
+	if got.Name != p.Name {
+		t.Fail()
+	}
+
+	l, err := products.Find("name=?", "typed")
+	if err != nil || len(l) == 0 {
+		t.Error(err)
+		t.Fail()
+	}
+}
+
 func TestExecutor_SelectOne(t *testing.T) {
 	{
 		var p *Product
@@ -291,3 +408,149 @@ func TestExecutor_SelectOne(t *testing.T) {
 		}
 	}
 }
+
+func TestTable_FindInBatches(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	columns := []string{"id", "name"}
+	f.On("SELECT").Returns(columns, []interface{}{1, "a"}, []interface{}{2, "b"}).Once()
+	f.On("SELECT").Returns(columns, []interface{}{3, "c"}, []interface{}{4, "d"}).Once()
+	f.On("SELECT").Returns(columns, []interface{}{5, "e"}).Once()
+
+	var items []*fakeItem
+	var ids []int64
+	err := db.Table("items").FindInBatches(&items, 2, func(batch int) error {
+		if len(items) > 2 {
+			t.Error(batch, len(items))
+		}
+
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return nil
+	}, "name <> ?", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(ids, []int64{1, 2, 3, 4, 5}) {
+		t.Error(ids)
+	}
+
+	statements := f.Statements()
+	if len(statements) != 3 {
+		t.Fatal(len(statements))
+	}
+
+	if s := statements[0]; s.Query != "SELECT id, name FROM items WHERE name <> ? ORDER BY id LIMIT 2" {
+		t.Error(s.Query)
+	}
+
+	if s := statements[2]; s.Query != "SELECT id, name FROM items WHERE (name <> ?) AND id > ? ORDER BY id LIMIT 2" ||
+		!reflect.DeepEqual(s.Args, []interface{}{"", int64(4)}) {
+		t.Error(s.Query, s.Args)
+	}
+}
+
+func TestDB_Query_timeout(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetDefaultQueryTimeout(time.Millisecond)
+	f.On("SELECT").Returns([]string{"id"}, []interface{}{1})
+	rows, err := db.Query("SELECT id FROM items")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	time.Sleep(10 * time.Millisecond)
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+}
+
+func TestDB_SetSchema(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	db.SetSchema("analytics")
+	var items []*fakeItem
+	if err := db.Table("items").Select(&items, "id > ?", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "SELECT id, name FROM analytics.items WHERE id > $1" {
+		t.Error(s.Query)
+	}
+
+	if _, err := db.Columns("items"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); !strings.Contains(s.Query, "c.table_schema = $1 AND c.table_name = $2") ||
+		!reflect.DeepEqual(s.Args, []interface{}{"analytics", "items"}) {
+		t.Error(s.Query, s.Args)
+	}
+}
+
+func TestDB_MultiInsert_error(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("BEGIN").Fails(errors.New("too many connections")).Once()
+	if err := db.MultiInsert(&fakeItem{ID: 1}); err == nil || err.Error() != "too many connections" {
+		t.Fatal(err)
+	}
+
+	dup := errors.New("duplicate")
+	f.On("VALUES (?, ?)").Once()
+	f.On("VALUES (?, ?)").Fails(dup).Once()
+	err := db.MultiInsert(&fakeItem{ID: 1}, &fakeItem{ID: 2}, &fakeItem{ID: 3})
+	var me *sql.MultiError
+	if !errors.As(err, &me) || !errors.Is(err, dup) || me.Total != 3 || len(me.Errors) != 1 || me.Errors[0].Index != 1 {
+		t.Fatal(err)
+	}
+	if last := f.LastStatement(); last.Query != "ROLLBACK" {
+		t.Error(last)
+	}
+
+	f.Reset()
+	f.On("VALUES (?, ?)").Fails(dup).Once()
+	f.On("VALUES (?, ?)").Once()
+	f.On("VALUES (?, ?)").Fails(dup).Once()
+	err = db.ContinueOnError().Insert(&fakeItem{ID: 1}, &fakeItem{ID: 2}, &fakeItem{ID: 3})
+	if !errors.As(err, &me) || len(me.Errors) != 2 || me.Errors[1].Index != 2 || me.Records()[1].(*fakeItem).ID != 3 {
+		t.Fatal(err)
+	}
+	if len(f.Statements()) != 3 {
+		t.Error(f.Statements())
+	}
+}
+
+func TestDB_MultiSave(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	items := []interface{}{&fakeItem{ID: 1}, &fakeItem{ID: 2}}
+	if err := db.MultiSave(items...); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.Statements()[1].Query; !strings.Contains(s, "ON DUPLICATE KEY UPDATE") {
+		t.Error(s)
+	}
+
+	f.Reset()
+	f.On("INSERT IGNORE").Affects(0, 0)
+	if err := db.MultiSaveWithStrategy(sql.SkipConflicts, items...); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.Statements(); len(s) != 4 || !strings.HasPrefix(s[2].Query, "INSERT IGNORE INTO fake_items") {
+		t.Error(s)
+	}
+
+	f.Reset()
+	f.On("INSERT INTO").Fails(errors.New("duplicate")).Once()
+	var me *sql.MultiError
+	if err := db.MultiSaveWithStrategy(sql.FailFast, items...); !errors.As(err, &me) || me.Errors[0].Index != 0 {
+		t.Fatal(err)
+	}
+	if s := f.Statements(); len(s) != 3 || strings.Contains(s[1].Query, "ON DUPLICATE") {
+		t.Error(s)
+	}
+
+	if err := db.MultiSaveWithStrategy(sql.ConflictStrategy(9), items...); err == nil {
+		t.Error("no error of invalid strategy")
+	}
+}
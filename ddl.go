@@ -0,0 +1,221 @@
+package sql
+
+import (
+	"fmt"
+	"github.com/gopub/log"
+	"github.com/gopub/utils"
+	"reflect"
+	"strings"
+)
+
+func isPostgres(driverName string) bool {
+	return driverName == "postgres" || driverName == "pgx"
+}
+
+// CreateTable creates table for record if it doesn't exist
+func (d *DB) CreateTable(record interface{}) error {
+	return d.Table(getTableName(record)).CreateTable(record)
+}
+
+// CreateTable creates table if it doesn't exist. Columns are declared according to record's fields:
+// primary key, auto_increment, unique and nullable tags are honored,
+// size=n declares length of string column, and index creates an index on the column.
+func (t *Table) CreateTable(record interface{}) error {
+	v := getStructValue(record)
+	for _, query := range t.createTableQueries(v.Type()) {
+		log.Debug(query)
+		if _, err := t.exec(query); err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Table) createTableQueries(typ reflect.Type) []string {
+	info := getColumnInfo(typ)
+	inlinePK := t.driverName == "sqlite3" && len(info.aiName) > 0
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("CREATE TABLE IF NOT EXISTS ")
+	buf.WriteString(t.quote(t.name))
+	buf.WriteString("(\n")
+	for i, name := range info.names {
+		if i > 0 {
+			buf.WriteString(",\n")
+		}
+		buf.WriteString("\t")
+		buf.WriteString(t.quote(name))
+		buf.WriteString(" ")
+		if name == info.aiName {
+			switch {
+			case inlinePK:
+				buf.WriteString("INTEGER PRIMARY KEY AUTOINCREMENT")
+				continue
+			case isPostgres(t.driverName):
+				buf.WriteString("BIGSERIAL")
+				continue
+			}
+		}
+
+		ft := typ.FieldByIndex(info.nameToIndex[name]).Type
+		size := info.sizes[name]
+		if utils.IndexOfString(info.encryptedNames, name) >= 0 {
+			// ciphertext is longer than size of value
+			size = 0
+		}
+		buf.WriteString(t.columnType(ft, size, utils.IndexOfString(info.jsonNames, name) >= 0))
+		if utils.IndexOfString(info.nullableNames, name) < 0 {
+			buf.WriteString(" NOT NULL")
+		}
+
+		if name == info.aiName {
+			buf.WriteString(" AUTO_INCREMENT")
+		}
+
+		if utils.IndexOfString(info.uniqueNames, name) >= 0 {
+			buf.WriteString(" UNIQUE")
+		}
+	}
+
+	if len(info.pkNames) > 0 && !inlinePK {
+		buf.WriteString(",\n\tPRIMARY KEY(")
+		buf.WriteString(t.quoteColumns(info.pkNames))
+		buf.WriteString(")")
+	}
+
+	if t.driverName == "mysql" {
+		for _, name := range info.indexNames {
+			buf.WriteString(fmt.Sprintf(",\n\tINDEX %s(%s)", t.indexName(name), t.quote(name)))
+		}
+	}
+	buf.WriteString("\n)")
+
+	queries := []string{buf.String()}
+	if t.driverName != "mysql" {
+		for _, name := range info.indexNames {
+			queries = append(queries, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s(%s)",
+				t.indexName(name), t.quote(t.name), t.quote(name)))
+		}
+	}
+	return queries
+}
+
+func (t *Table) indexName(column string) string {
+	return t.quote("idx_" + strings.Replace(t.name, ".", "_", -1) + "_" + column)
+}
+
+func (t *Table) columnType(typ reflect.Type, size int, isJSON bool) string {
+	pg := isPostgres(t.driverName)
+	sqlite := t.driverName == "sqlite3"
+	if isJSON {
+		switch {
+		case pg:
+			return "JSONB"
+		case sqlite:
+			return "TEXT"
+		default:
+			return "JSON"
+		}
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == _pointType || typ == _polygonType {
+		kind := "POINT"
+		if typ == _polygonType {
+			kind = "POLYGON"
+		}
+
+		switch {
+		case pg:
+			return fmt.Sprintf("GEOMETRY(%s, %d)", kind, SRID)
+		case t.driverName == "mysql":
+			return kind
+		default:
+			panic("unsupported type: " + typ.String())
+		}
+	}
+
+	if pg && isArrayType(typ) {
+		return t.columnType(typ.Elem(), size, false) + "[]"
+	}
+
+	if typ == _uuidType {
+		switch {
+		case pg:
+			return "UUID"
+		case t.driverName == "mysql":
+			return "BINARY(16)"
+		case t.driverName == "sqlserver" || t.driverName == "mssql":
+			return "UNIQUEIDENTIFIER"
+		default:
+			return "BLOB"
+		}
+	}
+
+	if typ == _timeType {
+		switch {
+		case pg:
+			return "TIMESTAMPTZ"
+		case t.driverName == "mysql":
+			return "DATETIME(6)"
+		case t.driverName == "sqlserver" || t.driverName == "mssql":
+			return "DATETIME2"
+		default:
+			return "DATETIME"
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		if pg {
+			return "BOOLEAN"
+		}
+		return "BOOL"
+	case reflect.Int8, reflect.Uint8:
+		if pg || sqlite {
+			return "SMALLINT"
+		}
+		return "TINYINT"
+	case reflect.Int16, reflect.Uint16:
+		return "SMALLINT"
+	case reflect.Int32, reflect.Uint32:
+		if pg || sqlite {
+			return "INTEGER"
+		}
+		return "INT"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Float32:
+		if pg || sqlite {
+			return "REAL"
+		}
+		return "FLOAT"
+	case reflect.Float64:
+		if pg {
+			return "DOUBLE PRECISION"
+		}
+		return "DOUBLE"
+	case reflect.String:
+		if size > 0 {
+			return fmt.Sprintf("VARCHAR(%d)", size)
+		}
+
+		if pg || sqlite {
+			return "TEXT"
+		}
+		return "VARCHAR(255)"
+	default:
+		if typ.ConvertibleTo(_bytesType) {
+			if pg {
+				return "BYTEA"
+			}
+			return "BLOB"
+		}
+	}
+	panic("unsupported type: " + typ.String())
+}
@@ -0,0 +1,52 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+type ddlProduct struct {
+	ID    int64  `sql:"primary key,auto_increment"`
+	Name  string `sql:"size=64,index"`
+	Code  string `sql:"unique,size=16"`
+	Price float64
+	Note  string   `sql:"nullable"`
+	Tags  []string `sql:"json"`
+}
+
+func TestTable_createTableQueries(t *testing.T) {
+	typ := reflect.TypeOf(ddlProduct{})
+	{
+		tbl := &Table{driverName: "mysql", name: "products"}
+		queries := tbl.createTableQueries(typ)
+		expected := `CREATE TABLE IF NOT EXISTS products(
+	id BIGINT NOT NULL AUTO_INCREMENT,
+	name VARCHAR(64) NOT NULL,
+	code VARCHAR(16) NOT NULL UNIQUE,
+	price DOUBLE NOT NULL,
+	note VARCHAR(255),
+	tags JSON NOT NULL,
+	PRIMARY KEY(id),
+	INDEX idx_products_name(name)
+)`
+		if len(queries) != 1 || queries[0] != expected {
+			t.Error(queries)
+		}
+	}
+
+	{
+		tbl := &Table{driverName: "sqlite3", name: "products"}
+		queries := tbl.createTableQueries(typ)
+		expected := `CREATE TABLE IF NOT EXISTS products(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(64) NOT NULL,
+	code VARCHAR(16) NOT NULL UNIQUE,
+	price DOUBLE NOT NULL,
+	note TEXT,
+	tags TEXT NOT NULL
+)`
+		if len(queries) != 2 || queries[0] != expected || queries[1] != "CREATE INDEX IF NOT EXISTS idx_products_name ON products(name)" {
+			t.Error(queries)
+		}
+	}
+}
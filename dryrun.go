@@ -0,0 +1,201 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Statement is a query with its args, which is built by SelectStatement etc. or recorded by DryRun
+type Statement struct {
+	Query string
+	Args  []interface{}
+
+	driverName string
+}
+
+// String returns the statement with args interpolated, sensitive values are redacted
+func (s Statement) String() string {
+	return interpolate(s.driverName, s.Query, s.Args)
+}
+
+// DryRunLog records statements of a dry run DB
+type DryRunLog struct {
+	mu         sync.Mutex
+	driverName string
+	statements []Statement
+	lastID     int64
+}
+
+// Statements returns recorded statements in order
+func (l *DryRunLog) Statements() []Statement {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Statement(nil), l.statements...)
+}
+
+// Last returns the last recorded statement, or nil if there's none
+func (l *DryRunLog) Last() *Statement {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.statements) == 0 {
+		return nil
+	}
+	s := l.statements[len(l.statements)-1]
+	return &s
+}
+
+// Reset removes recorded statements
+func (l *DryRunLog) Reset() {
+	l.mu.Lock()
+	l.statements = nil
+	l.mu.Unlock()
+}
+
+func (l *DryRunLog) add(query string, args []driver.NamedValue) {
+	s := Statement{Query: query, driverName: l.driverName}
+	for _, a := range args {
+		s.Args = append(s.Args, a.Value)
+	}
+	l.mu.Lock()
+	l.statements = append(l.statements, s)
+	l.mu.Unlock()
+}
+
+// DryRun returns a DB with a copy of options of d, which records statements generated in dialect of d into the returned log
+// instead of executing them, e.g. to test query generation. Queries return no rows, and other statements affect no rows
+// with fake increasing insert ids. The returned DB should be closed when it's no longer used
+func (d *DB) DryRun() (*DB, *DryRunLog) {
+	l := &DryRunLog{driverName: d.driverName}
+	opts := *d.opts
+	dry := &DB{
+		db:              sql.OpenDB(dryRunConnector{l: l}),
+		driverName:      d.driverName,
+		multiStatements: d.multiStatements,
+		batchSize:       d.batchSize,
+		opts:            &opts,
+	}
+	opts.keys = &keyResolver{db: dry}
+	return dry, l
+}
+
+type dryRunConnector struct {
+	l *DryRunLog
+}
+
+func (c dryRunConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &dryRunConn{l: c.l}, nil
+}
+
+func (c dryRunConnector) Driver() driver.Driver {
+	return dryRunDriver(c)
+}
+
+type dryRunDriver struct {
+	l *DryRunLog
+}
+
+func (d dryRunDriver) Open(name string) (driver.Conn, error) {
+	return &dryRunConn{l: d.l}, nil
+}
+
+type dryRunConn struct {
+	l *DryRunLog
+}
+
+func (c *dryRunConn) Prepare(query string) (driver.Stmt, error) {
+	return &dryRunStmt{c: c, query: query}, nil
+}
+
+func (c *dryRunConn) Close() error {
+	return nil
+}
+
+func (c *dryRunConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *dryRunConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.l.add("BEGIN", nil)
+	return dryRunTx{l: c.l}, nil
+}
+
+// CheckNamedValue records args as they are passed
+func (c *dryRunConn) CheckNamedValue(v *driver.NamedValue) error {
+	return nil
+}
+
+func (c *dryRunConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.l.add(query, args)
+	return dryRunResult{id: atomic.AddInt64(&c.l.lastID, 1)}, nil
+}
+
+func (c *dryRunConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.l.add(query, args)
+	return dryRunRows{}, nil
+}
+
+// dryRunResult affects no rows, and has a fake increasing insert id so that auto increment fields are filled
+type dryRunResult struct {
+	id int64
+}
+
+func (r dryRunResult) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+func (r dryRunResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
+
+type dryRunTx struct {
+	l *DryRunLog
+}
+
+func (t dryRunTx) Commit() error {
+	t.l.add("COMMIT", nil)
+	return nil
+}
+
+func (t dryRunTx) Rollback() error {
+	t.l.add("ROLLBACK", nil)
+	return nil
+}
+
+type dryRunStmt struct {
+	c     *dryRunConn
+	query string
+}
+
+func (s *dryRunStmt) Close() error {
+	return nil
+}
+
+func (s *dryRunStmt) NumInput() int {
+	return -1
+}
+
+func (s *dryRunStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *dryRunStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+type dryRunRows struct{}
+
+func (r dryRunRows) Columns() []string {
+	return nil
+}
+
+func (r dryRunRows) Close() error {
+	return nil
+}
+
+func (r dryRunRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
@@ -0,0 +1,74 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+func TestDB_DryRun(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	dry, l := db.DryRun()
+	defer dry.Close()
+	dry.SetSchema("test")
+	dry.SetStrictOrder(true)
+	if err := dry.Insert(&fakeItem{ID: 1, Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var items []*fakeItem
+	if err := dry.Table("items").Select(&items, "name = ?", sql.Sensitive("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.Statements()) != 0 {
+		t.Error(f.Statements())
+	}
+
+	statements := l.Statements()
+	if len(statements) != 2 {
+		t.Fatal(statements)
+	}
+
+	if s := statements[0]; s.Query != "INSERT INTO test.fake_items(id, name) VALUES ($1, $2)" ||
+		!reflect.DeepEqual(s.Args, []interface{}{int64(1), "a"}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	if s := l.Last().String(); s != "SELECT id, name FROM test.items WHERE name = '[REDACTED]'" {
+		t.Error(s)
+	}
+
+	if err := db.Table("items").Select(&items, "name = ?", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Query != "SELECT id, name FROM items WHERE name = $1" {
+		t.Error(s.Query)
+	}
+}
+
+type dryRunItem struct {
+	ID   int64 `sql:"primary key,auto_increment"`
+	Name string
+}
+
+func TestDB_DryRun_autoIncrement(t *testing.T) {
+	db, _ := gosqltest.New("mysql")
+	dry, l := db.DryRun()
+	a, b := &dryRunItem{Name: "a"}, &dryRunItem{Name: "b"}
+	if err := dry.Insert(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := dry.Insert(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if a.ID == 0 || b.ID == 0 || a.ID == b.ID {
+		t.Error(a.ID, b.ID)
+	}
+
+	if s := l.Last(); s.Query != "INSERT INTO dry_run_items(name) VALUES (?)" {
+		t.Error(s.Query)
+	}
+}
@@ -0,0 +1,66 @@
+package sql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Enum is implemented by string types whose values are restricted, e.g.
+//
+//	type Status string
+//
+//	func (Status) EnumValues() []string {
+//		return []string{"active", "inactive", "banned"}
+//	}
+//
+// Allowed values can also be declared by tag, e.g. `sql:"status,enum=active|inactive|banned"`
+type Enum interface {
+	EnumValues() []string
+}
+
+var _enumType = reflect.TypeOf((*Enum)(nil)).Elem()
+
+// EnumError is returned if value of an enum column isn't allowed, which is checked when it's written or read
+type EnumError struct {
+	Column  string
+	Value   string
+	Allowed []string
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("invalid value %q of column %s, allowed values: %s", e.Value, e.Column, strings.Join(e.Allowed, ", "))
+}
+
+// enumValues returns allowed values of field type typ if it implements Enum
+func enumValues(typ reflect.Type) []string {
+	if typ.Implements(_enumType) {
+		return reflect.Zero(typ).Interface().(Enum).EnumValues()
+	}
+	return nil
+}
+
+// parseEnumTag returns allowed values declared by enum=a|b|c in tag, whose case is kept
+func parseEnumTag(tag string) []string {
+	for _, s := range strings.Split(tag, ",") {
+		s = strings.TrimSpace(s)
+		if len(s) > len("enum=") && strings.EqualFold(s[:len("enum=")], "enum=") {
+			return strings.Split(s[len("enum="):], "|")
+		}
+	}
+	return nil
+}
+
+// checkEnum returns EnumError if v isn't in allowed values. Empty value of nullable column is NULL
+func checkEnum(column string, allowed []string, v string, nullable bool) error {
+	if nullable && len(v) == 0 {
+		return nil
+	}
+
+	for _, a := range allowed {
+		if v == a {
+			return nil
+		}
+	}
+	return &EnumError{Column: column, Value: v, Allowed: allowed}
+}
@@ -0,0 +1,51 @@
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type enumStatus string
+
+func (enumStatus) EnumValues() []string {
+	return []string{"active", "banned"}
+}
+
+type enumRecord struct {
+	ID     int64 `sql:"primary key"`
+	Status enumStatus
+	Role   string `sql:"role,nullable,enum=Admin|Member"`
+}
+
+func TestEnum(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(enumRecord{}))
+	if !reflect.DeepEqual(info.enums, map[string][]string{"status": {"active", "banned"}, "role": {"Admin", "Member"}}) {
+		t.Fatal(info.enums)
+	}
+
+	tbl := &Table{driverName: "mysql", name: "enum_records", opts: &options{}}
+	v := reflect.ValueOf(enumRecord{ID: 1, Status: "active"})
+	if k, err := tbl.fieldValue(v, info, "role", false); err != nil || k != nil {
+		t.Error(k, err)
+	}
+
+	v = reflect.ValueOf(enumRecord{ID: 1, Status: "deleted"})
+	_, err := tbl.fieldValue(v, info, "status", false)
+	var e *EnumError
+	if !errors.As(err, &e) || e.Value != "deleted" || e.Error() != `invalid value "deleted" of column status, allowed values: active, banned` {
+		t.Error(err)
+	}
+
+	var r enumRecord
+	row := fakeRow{int64(1), enumStatus("banned"), sql.NullString{String: "Member", Valid: true}}
+	if err := scanStruct(row, reflect.ValueOf(&r).Elem(), info, nil); err != nil || r.Role != "Member" {
+		t.Error(err, r)
+	}
+
+	row = fakeRow{int64(1), enumStatus("banned"), sql.NullString{String: "admin", Valid: true}}
+	if err := scanStruct(row, reflect.ValueOf(&r).Elem(), info, nil); !errors.As(err, &e) || e.Column != "role" {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,133 @@
+package sql
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// driverError is the code of an error returned by driver. Postgres errors have SQLSTATE, others have numbers
+type driverError struct {
+	dialect string
+	state   string
+	number  int64
+}
+
+// getDriverError finds driver error in err's chain by SQLState method or package path of its type, so drivers aren't imported
+func getDriverError(err error) (driverError, bool) {
+	var s interface{ SQLState() string }
+	if errors.As(err, &s) {
+		return driverError{dialect: "postgres", state: s.SQLState()}, true
+	}
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		for v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+
+		pkg := v.Type().PkgPath()
+		switch {
+		case strings.HasSuffix(pkg, "/lib/pq"):
+			if f := v.FieldByName("Code"); f.Kind() == reflect.String {
+				return driverError{dialect: "postgres", state: f.String()}, true
+			}
+		case strings.Contains(pkg, "mysql"):
+			if n, ok := intField(v, "Number"); ok {
+				return driverError{dialect: "mysql", number: n}, true
+			}
+		case strings.Contains(pkg, "mssql"):
+			if n, ok := intField(v, "Number"); ok {
+				return driverError{dialect: "mssql", number: n}, true
+			}
+		case strings.Contains(pkg, "sqlite"):
+			if n, ok := intField(v, "ExtendedCode"); ok {
+				return driverError{dialect: "sqlite3", number: n}, true
+			}
+			if c, ok := err.(interface{ Code() int }); ok {
+				return driverError{dialect: "sqlite3", number: int64(c.Code())}, true
+			}
+		}
+	}
+	return driverError{}, false
+}
+
+func intField(v reflect.Value, name string) (int64, bool) {
+	f := v.FieldByName(name)
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(f.Uint()), true
+	default:
+		return 0, false
+	}
+}
+
+// is reports whether err is a driver error of one of the postgres states, or numbers of the dialect
+func (e driverError) is(states []string, numbers map[string][]int64) bool {
+	if e.dialect == "postgres" {
+		for _, s := range states {
+			if e.state == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, n := range numbers[e.dialect] {
+		if e.number == n {
+			return true
+		}
+	}
+
+	// sqlite primary result code is the low byte of extended code
+	if e.dialect == "sqlite3" {
+		for _, n := range numbers[e.dialect] {
+			if n < 256 && e.number&0xff == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isDriverError(err error, states []string, numbers map[string][]int64) bool {
+	e, ok := getDriverError(err)
+	return ok && e.is(states, numbers)
+}
+
+// IsDuplicateKey reports whether err violates a primary key or unique constraint
+func IsDuplicateKey(err error) bool {
+	return isDriverError(err, []string{"23505"}, map[string][]int64{
+		"mysql":   {1062, 1586},
+		"mssql":   {2601, 2627},
+		"sqlite3": {1555, 2067},
+	})
+}
+
+// IsForeignKeyViolation reports whether err violates a foreign key constraint
+func IsForeignKeyViolation(err error) bool {
+	return isDriverError(err, []string{"23503"}, map[string][]int64{
+		"mysql":   {1216, 1217, 1451, 1452},
+		"mssql":   {547},
+		"sqlite3": {787},
+	})
+}
+
+// IsDeadlock reports whether err is caused by deadlock or, for sqlite, a locked database
+func IsDeadlock(err error) bool {
+	return isDriverError(err, []string{"40P01"}, map[string][]int64{
+		"mysql":   {1213},
+		"mssql":   {1205},
+		"sqlite3": {5, 6},
+	})
+}
+
+// IsSerializationFailure reports whether err is serialization failure of a serializable or repeatable read transaction
+func IsSerializationFailure(err error) bool {
+	return isDriverError(err, []string{"40001"}, nil)
+}
@@ -0,0 +1,48 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type pgError string
+
+func (e pgError) Error() string {
+	return "pq: " + string(e)
+}
+
+func (e pgError) SQLState() string {
+	return string(e)
+}
+
+func TestIsDuplicateKey(t *testing.T) {
+	err := fmt.Errorf("insert: %w", pgError("23505"))
+	if !IsDuplicateKey(err) || IsForeignKeyViolation(err) || IsDeadlock(err) {
+		t.Error(err)
+	}
+
+	if IsDuplicateKey(errors.New("duplicate entry")) || IsDuplicateKey(nil) {
+		t.Error("plain error is classified")
+	}
+
+	if !IsForeignKeyViolation(pgError("23503")) || !IsDeadlock(pgError("40P01")) || !IsSerializationFailure(pgError("40001")) {
+		t.Error("postgres errors are not classified")
+	}
+}
+
+func TestDriverError_is(t *testing.T) {
+	numbers := map[string][]int64{"mysql": {1062}, "sqlite3": {5, 2067}}
+	if !(driverError{dialect: "mysql", number: 1062}).is(nil, numbers) {
+		t.Error("mysql 1062")
+	}
+
+	if (driverError{dialect: "mssql", number: 1062}).is(nil, numbers) {
+		t.Error("mssql 1062")
+	}
+
+	// SQLITE_BUSY_SNAPSHOT is an extended code of SQLITE_BUSY
+	if !(driverError{dialect: "sqlite3", number: 517}).is(nil, numbers) {
+		t.Error("sqlite 517")
+	}
+}
@@ -1,14 +1,16 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
 	"reflect"
 )
 
-type executor interface {
-	Exec(query string, args ...interface{}) (sql.Result, error)
-	Query(query string, args ...interface{}) (*sql.Rows, error)
-	QueryRow(query string, args ...interface{}) *sql.Row
+// Executor executes statements, which is implemented by *sql.DB and *sql.Tx, and is wrapped by Middleware
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 func getStructValue(i interface{}) reflect.Value {
@@ -0,0 +1,178 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/gopub/log"
+	"sort"
+	"strings"
+)
+
+// Plan is the query plan returned by Explain
+type Plan struct {
+	// Raw is the plan in JSON for mysql and postgres, or lines of detail for sqlite3
+	Raw string
+
+	// Indexes are names of indexes used by the plan, primary key is PRIMARY for mysql and sqlite3
+	Indexes []string
+
+	// FullScan is true if any table is scanned without index
+	FullScan bool
+}
+
+// UsesIndex returns true if index name is used by the plan
+func (p *Plan) UsesIndex(name string) bool {
+	for _, s := range p.Indexes {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plan) String() string {
+	return p.Raw
+}
+
+// Explain returns plan of the select query of t, which selects columns set by Columns, default is *
+func (t *Table) Explain(where string, args ...interface{}) (*Plan, error) {
+	if err := t.checkWhere(nil, where, args); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	where, args = expandExpressions(where, args)
+	query, args := t.buildSelectQuery(&columnInfo{names: t.columns}, where, args)
+	ctx, cancel := t.context()
+	defer cancel()
+	return explain(ctx, t.reader, t.opts, t.driverName, t.annotate(query), args)
+}
+
+// Explain returns plan of query, e.g. db.Explain("SELECT * FROM users WHERE name=?", "bob")
+func (d *DB) Explain(query string, args ...interface{}) (*Plan, error) {
+	ctx, cancel := d.opts.context(context.Background())
+	defer cancel()
+	return explain(ctx, d.opts.wrap(d.reader()), d.opts, d.driverName, query, args)
+}
+
+func (t *Tx) Explain(query string, args ...interface{}) (*Plan, error) {
+	ctx, cancel := t.opts.context(t.ctx)
+	defer cancel()
+	return explain(ctx, t.exe, t.opts, t.driverName, query, args)
+}
+
+func explain(ctx context.Context, exe Executor, opts *options, driverName, query string, args []interface{}) (*Plan, error) {
+	switch {
+	case driverName == "mysql":
+		query = "EXPLAIN FORMAT=JSON " + query
+	case isPostgres(driverName):
+		query = "EXPLAIN (FORMAT JSON) " + query
+	case driverName == "sqlite3":
+		query = "EXPLAIN QUERY PLAN " + query
+	default:
+		panic("EXPLAIN is not supported for driver: " + driverName)
+	}
+
+	opts.logQuery(driverName, query, args)
+	rows, err := exe.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	// sqlite3 returns rows of id, parent, notused and detail, others return the plan in one column
+	var lines []string
+	values := make([]sql.NullString, len(columns))
+	addrs := make([]interface{}, len(columns))
+	for i := range values {
+		addrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(addrs...); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		lines = append(lines, values[len(values)-1].String)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	p, err := parsePlan(driverName, strings.Join(lines, "\n"))
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	return p, nil
+}
+
+func parsePlan(driverName, raw string) (*Plan, error) {
+	p := &Plan{Raw: raw}
+	if driverName == "sqlite3" {
+		// e.g. SEARCH users USING INDEX idx_users_name (name=?), SCAN orders
+		for _, line := range strings.Split(raw, "\n") {
+			if strings.HasPrefix(line, "SCAN ") && !strings.Contains(line, " USING ") {
+				p.FullScan = true
+			}
+
+			if strings.Contains(line, " INTEGER PRIMARY KEY") {
+				p.addIndex("PRIMARY")
+			} else if i := strings.Index(line, " INDEX "); i >= 0 {
+				if fields := strings.Fields(line[i+len(" INDEX "):]); len(fields) > 0 {
+					p.addIndex(fields[0])
+				}
+			}
+		}
+	} else {
+		var v interface{}
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, err
+		}
+		p.walk(v)
+	}
+	sort.Strings(p.Indexes)
+	return p, nil
+}
+
+// walk collects indexes and scan types from mysql or postgres json plan
+func (p *Plan) walk(v interface{}) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, e := range x {
+			switch k {
+			case "key", "Index Name":
+				if s, ok := e.(string); ok {
+					p.addIndex(s)
+				}
+			case "access_type":
+				if e == "ALL" {
+					p.FullScan = true
+				}
+			case "Node Type":
+				if e == "Seq Scan" {
+					p.FullScan = true
+				}
+			}
+			p.walk(e)
+		}
+	case []interface{}:
+		for _, e := range x {
+			p.walk(e)
+		}
+	}
+}
+
+func (p *Plan) addIndex(name string) {
+	if !p.UsesIndex(name) {
+		p.Indexes = append(p.Indexes, name)
+	}
+}
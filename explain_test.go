@@ -0,0 +1,41 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlan(t *testing.T) {
+	tests := []struct {
+		driverName string
+		raw        string
+		indexes    []string
+		fullScan   bool
+	}{
+		{"mysql", `{"query_block": {"nested_loop": [
+			{"table": {"table_name": "o", "access_type": "ALL"}},
+			{"table": {"table_name": "u", "access_type": "eq_ref", "key": "PRIMARY"}}]}}`, []string{"PRIMARY"}, true},
+		{"mysql", `{"query_block": {"table": {"table_name": "users", "access_type": "ref", "key": "idx_users_name"}}}`,
+			[]string{"idx_users_name"}, false},
+		{"postgres", `[{"Plan": {"Node Type": "Index Scan", "Index Name": "idx_users_name", "Relation Name": "users"}}]`,
+			[]string{"idx_users_name"}, false},
+		{"postgres", `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users"}}]`, nil, true},
+		{"sqlite3", "SEARCH users USING INDEX idx_users_name (name=?)\nSEARCH orders USING INTEGER PRIMARY KEY (rowid=?)",
+			[]string{"PRIMARY", "idx_users_name"}, false},
+		{"sqlite3", "SCAN users\nSCAN orders USING COVERING INDEX idx_orders_user_id", []string{"idx_orders_user_id"}, true},
+	}
+	for _, test := range tests {
+		p, err := parsePlan(test.driverName, test.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(p.Indexes, test.indexes) || p.FullScan != test.fullScan {
+			t.Error(test.raw, p.Indexes, p.FullScan)
+		}
+	}
+
+	if _, err := parsePlan("mysql", "not json"); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,167 @@
+package sql
+
+import (
+	"database/sql"
+	"github.com/gopub/log"
+	"sort"
+	"strings"
+)
+
+// Expression is a sql expression with its args, which is rendered verbatim instead of being bound as a value
+type Expression struct {
+	SQL  string
+	Args []interface{}
+}
+
+// Expr returns an expression, e.g. Expr("count + ?", 1).
+// It can also be used as an arg of where clause, e.g. Select(&users, "id IN (?)", Expr("SELECT user_id FROM orders"))
+func Expr(sql string, args ...interface{}) *Expression {
+	return &Expression{SQL: sql, Args: args}
+}
+
+// Subquery returns select query of t as an expression, which can be an arg of where clause or a source of DB.From.
+// Columns are set by Columns and SelectExpr, default is *
+func (t *Table) Subquery(where string, args ...interface{}) *Expression {
+	if err := t.checkWhere(nil, where, args); err != nil {
+		panic(err)
+	}
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(nil, where, args)
+	if err != nil {
+		panic(err)
+	}
+	info := &columnInfo{
+		names: append(append([]string(nil), t.columns...), t.selectExprs...),
+		exprs: t.selectExprs,
+	}
+	query, args := t.buildSelectQuery(info, where, args)
+	return &Expression{SQL: query, Args: args}
+}
+
+// expandExpressions replaces placeholders of *Expression args with their sql, and inlines their args
+func expandExpressions(query string, args []interface{}) (string, []interface{}) {
+	found := false
+	for _, a := range args {
+		if _, ok := a.(*Expression); ok {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return query, args
+	}
+
+	var buf strings.Builder
+	result := make([]interface{}, 0, len(args))
+	var quote byte
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?' && n < len(args):
+			if e, ok := args[n].(*Expression); ok {
+				buf.WriteString(e.SQL)
+				result = append(result, e.Args...)
+			} else {
+				buf.WriteByte(c)
+				result = append(result, args[n])
+			}
+			n++
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String(), append(result, args[n:]...)
+}
+
+// UpdateColumnsMap sets columns to values for rows matching where. A value can be *Expression,
+// e.g. map[string]interface{}{"count": Expr("count + ?", 1)}
+func (t *Table) UpdateColumnsMap(values map[string]interface{}, where string, args ...interface{}) error {
+	_, err := t.updateColumnsMap(values, where, args)
+	return err
+}
+
+func (t *Table) updateColumnsMap(values map[string]interface{}, where string, args []interface{}) (sql.Result, error) {
+	if len(values) == 0 {
+		panic("values is empty")
+	}
+
+	if err := t.checkAllRows(where); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	if err := t.checkWhere(nil, where, args); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	if err := t.validate("UPDATE", nil, where); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(nil, where, args)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	query, queryArgs := t.prepareUpdateColumnsMapQuery(values, where, args)
+	t.logQuery(query, queryArgs)
+
+	result, err := t.exec(query, queryArgs...)
+	if err != nil {
+		log.Error(err)
+	}
+	return result, err
+}
+
+func (t *Table) prepareUpdateColumnsMapQuery(values map[string]interface{}, where string, args []interface{}) (string, []interface{}) {
+	columns := make([]string, 0, len(values))
+	for c := range values {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+
+	queryArgs := make([]interface{}, 0, len(values)+len(args))
+	buf := getBuffer()
+	defer putBuffer(buf)
+	set := func() {
+		buf.WriteString(" SET ")
+		for i, c := range columns {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(t.quote(c))
+			buf.WriteString(" = ")
+			if e, ok := values[c].(*Expression); ok {
+				buf.WriteString(e.SQL)
+				queryArgs = append(queryArgs, e.Args...)
+			} else {
+				buf.WriteString("?")
+				queryArgs = append(queryArgs, values[c])
+			}
+		}
+	}
+	if len(t.joins) > 0 {
+		t.writeJoinUpdate(buf, set, where)
+		return buf.String(), append(queryArgs, args...)
+	}
+
+	buf.WriteString("UPDATE ")
+	buf.WriteString(t.quote(t.name))
+	set()
+	if len(where) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(where)
+	}
+	return buf.String(), append(queryArgs, args...)
+}
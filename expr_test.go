@@ -0,0 +1,84 @@
+package sql
+
+import (
+	"testing"
+)
+
+func TestTable_prepareUpdateColumnsMapQuery(t *testing.T) {
+	tbl := &Table{driverName: "mysql", name: "products"}
+	values := map[string]interface{}{
+		"count": Expr("count + ?", 1),
+		"name":  "apple",
+	}
+	query, args := tbl.prepareUpdateColumnsMapQuery(values, "id = ?", []interface{}{3})
+	if query != "UPDATE products SET count = count + ?, name = ? WHERE id = ?" {
+		t.Error(query)
+	}
+
+	if len(args) != 3 || args[0] != 1 || args[1] != "apple" || args[2] != 3 {
+		t.Error(args)
+	}
+}
+
+func TestTable_Subquery(t *testing.T) {
+	orders := &Table{driverName: "postgres", name: "orders", opts: &options{}}
+	sub := orders.Columns("user_id").Subquery("price > ?", 10)
+	if sub.SQL != "SELECT user_id FROM orders WHERE price > ?" || len(sub.Args) != 1 {
+		t.Error(sub.SQL, sub.Args)
+	}
+
+	where, args := expandExpressions("name = ? AND id IN (?) AND note <> '?'", []interface{}{"Tom", sub})
+	if where != "name = ? AND id IN (SELECT user_id FROM orders WHERE price > ?) AND note <> '?'" {
+		t.Error(where)
+	}
+
+	if len(args) != 2 || args[0] != "Tom" || args[1] != 10 {
+		t.Error(args)
+	}
+
+	users := &Table{driverName: "postgres", name: "t", opts: &options{}, source: orders.GroupBy("user_id").Subquery("")}
+	query, args := users.buildSelectQuery(&columnInfo{names: []string{"user_id"}}, "user_id > ?", []interface{}{1})
+	if query != "SELECT user_id FROM (SELECT * FROM orders GROUP BY user_id) t WHERE user_id > ?" || len(args) != 1 {
+		t.Error(query, args)
+	}
+
+	if rebind(query) != "SELECT user_id FROM (SELECT * FROM orders GROUP BY user_id) t WHERE user_id > $1" {
+		t.Error(rebind(query))
+	}
+}
+
+func TestTable_joinDelete(t *testing.T) {
+	mysql := &Table{driverName: "mysql", name: "orders o", opts: &options{}}
+	query := mysql.Join("users u ON u.id = o.user_id").prepareDeleteQuery("u.banned = ?")
+	if query != "DELETE o FROM orders o JOIN users u ON u.id = o.user_id WHERE u.banned = ?" {
+		t.Error(query)
+	}
+
+	for _, driverName := range []string{"postgres", "pgx"} {
+		pg := &Table{driverName: driverName, name: "orders o", opts: &options{}}
+		query = pg.Join("users u ON u.id = o.user_id").prepareDeleteQuery("u.banned = ?")
+		if query != "DELETE FROM orders o USING users u WHERE (u.id = o.user_id) AND (u.banned = ?)" {
+			t.Error(driverName, query)
+		}
+	}
+}
+
+func TestTable_joinUpdate(t *testing.T) {
+	values := map[string]interface{}{"status": "cancelled"}
+	mysql := &Table{driverName: "mysql", name: "orders o", opts: &options{}}
+	query, args := mysql.Join("users u ON u.id = o.user_id").prepareUpdateColumnsMapQuery(values, "u.banned = ?", []interface{}{true})
+	if query != "UPDATE orders o JOIN users u ON u.id = o.user_id SET status = ? WHERE u.banned = ?" {
+		t.Error(query)
+	}
+	if len(args) != 2 || args[0] != "cancelled" || args[1] != true {
+		t.Error(args)
+	}
+
+	for _, driverName := range []string{"postgres", "pgx"} {
+		pg := &Table{driverName: driverName, name: "orders o", opts: &options{}}
+		query, _ = pg.Join("users u ON u.id = o.user_id").prepareUpdateColumnsMapQuery(values, "u.banned = ?", []interface{}{true})
+		if query != "UPDATE orders o SET status = ? FROM users u WHERE (u.id = o.user_id) AND (u.banned = ?)" {
+			t.Error(driverName, query)
+		}
+	}
+}
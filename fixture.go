@@ -0,0 +1,158 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gopub/log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FixtureOptions are options of LoadFixtures
+type FixtureOptions struct {
+	// Unmarshal decodes fixture files into []map[string]interface{}, default is JSON.
+	// Set it to yaml.Unmarshal to load YAML files
+	Unmarshal func(data []byte, v interface{}) error
+
+	// KeepRows inserts rows without truncating tables
+	KeepRows bool
+}
+
+// LoadFixtures truncates tables and inserts rows of fixture files in a transaction. Each file contains rows of the table
+// named after it, e.g. testdata/users.json is [{"id": 1, "name": "bob"}]. Tables are truncated in reverse order and loaded
+// in order, so files of referenced tables go first
+func (d *DB) LoadFixtures(opts *FixtureOptions, files ...string) error {
+	if opts == nil {
+		opts = &FixtureOptions{}
+	}
+
+	tables := make([]string, len(files))
+	rows := make([][]map[string]interface{}, len(files))
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+
+		if rows[i], err = decodeFixture(data, opts.Unmarshal); err != nil {
+			err = fmt.Errorf("fixture %s: %w", file, err)
+			log.Error(err)
+			return err
+		}
+		name := filepath.Base(file)
+		tables[i] = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	return d.WithTxIsolation(context.Background(), LevelDefault, func(tx *Tx) error {
+		if !opts.KeepRows {
+			for i := len(tables) - 1; i >= 0; i-- {
+				if err := tx.Table(tables[i]).Truncate(&TruncateOptions{RestartIdentity: true}); err != nil {
+					return err
+				}
+			}
+		}
+
+		for i, table := range tables {
+			if err := tx.Table(table).insertFixtureRows(rows[i]); err != nil {
+				return fmt.Errorf("fixture %s: %w", files[i], err)
+			}
+		}
+		return nil
+	})
+}
+
+func decodeFixture(data []byte, unmarshal func(data []byte, v interface{}) error) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	if unmarshal != nil {
+		if err := unmarshal(data, &rows); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&rows); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range rows {
+		for k, v := range row {
+			fv, err := fixtureValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", k, err)
+			}
+			row[k] = fv
+		}
+	}
+	return rows, nil
+}
+
+// fixtureValue converts decoded numbers into int64 or float64, and nested objects or lists into JSON
+func fixtureValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+		data, err := json.Marshal(jsonCompatible(v))
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	default:
+		return v, nil
+	}
+}
+
+// jsonCompatible converts map[interface{}]interface{} decoded by some YAML libraries into map[string]interface{}
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonCompatible(e)
+		}
+		return m
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = jsonCompatible(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = jsonCompatible(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func (t *Table) insertFixtureRows(rows []map[string]interface{}) error {
+	for _, row := range rows {
+		columns := make([]string, 0, len(row))
+		for c := range row {
+			columns = append(columns, c)
+		}
+		sort.Strings(columns)
+
+		values := make([]interface{}, len(columns))
+		for i, c := range columns {
+			values[i] = row[c]
+		}
+
+		query := t.prepareInsertRowsQuery(columns, 1)
+		t.logQuery(query, values)
+		if _, err := t.exec(query, values...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDB_LoadFixtures(t *testing.T) {
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.json")
+	orders := filepath.Join(dir, "orders.json")
+	if err := os.WriteFile(users, []byte(`[{"id": 1, "name": "bob"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(orders, []byte(`[{"id": 7, "user_id": 1, "price": 9.5, "tags": ["a"]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, f := gosqltest.New("postgres")
+	if err := db.LoadFixtures(nil, users, orders); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := f.Statements()
+	if len(statements) != 6 {
+		t.Fatal(statements)
+	}
+
+	if statements[1].Query != "TRUNCATE TABLE orders RESTART IDENTITY" || statements[2].Query != "TRUNCATE TABLE users RESTART IDENTITY" {
+		t.Error(statements[1].Query, statements[2].Query)
+	}
+
+	if s := statements[3]; s.Query != "INSERT INTO users(id, name) VALUES ($1, $2)" ||
+		!reflect.DeepEqual(s.Args, []interface{}{int64(1), "bob"}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	if s := statements[4]; s.Query != "INSERT INTO orders(id, price, tags, user_id) VALUES ($1, $2, $3, $4)" ||
+		!reflect.DeepEqual(s.Args, []interface{}{int64(7), 9.5, `["a"]`, int64(1)}) {
+		t.Error(s.Query, s.Args)
+	}
+}
@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type tableProvider interface {
+	Table(name string) *Table
+}
+
+// TypedTable reads and writes records of struct type T
+type TypedTable[T any] struct {
+	table *Table
+	info  *columnInfo
+}
+
+// NewTable returns a typed table of T in db, which can be *DB or *Tx, e.g.
+//
+//	users := sql.NewTable[User](db)
+//	u, err := users.Get(1)
+//
+// It panics if T isn't a struct, so it's better to be called during initialization
+func NewTable[T any](db tableProvider) *TypedTable[T] {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		panic("not struct: " + typ.String())
+	}
+
+	return &TypedTable[T]{
+		table: db.Table(getTableNameByType(typ)),
+		info:  getColumnInfo(typ),
+	}
+}
+
+// Table returns the underlying table, e.g. to call OrderBy or Paginate
+func (t *TypedTable[T]) Table() *Table {
+	return t.table
+}
+
+// Insert inserts v, and sets its auto_increment field
+func (t *TypedTable[T]) Insert(v *T) error {
+	return t.table.Insert(v)
+}
+
+func (t *TypedTable[T]) InsertIgnore(v *T) (bool, error) {
+	return t.table.InsertIgnore(v)
+}
+
+func (t *TypedTable[T]) Update(v *T) error {
+	return t.table.Update(v)
+}
+
+func (t *TypedTable[T]) Save(v *T) error {
+	return t.table.Save(v)
+}
+
+func (t *TypedTable[T]) Upsert(v *T, opts *UpsertOptions) error {
+	return t.table.Upsert(v, opts)
+}
+
+func (t *TypedTable[T]) Find(where string, args ...interface{}) ([]T, error) {
+	var l []T
+	if err := t.table.Select(&l, where, args...); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// FindOne returns ErrNoRows if no record matches where
+func (t *TypedTable[T]) FindOne(where string, args ...interface{}) (T, error) {
+	var v T
+	err := t.table.SelectOne(&v, where, args...)
+	return v, err
+}
+
+// Get selects record by primary key values, and returns ErrNoRows if it doesn't exist
+func (t *TypedTable[T]) Get(pk ...interface{}) (T, error) {
+	if len(pk) != len(t.info.pkNames) || len(pk) == 0 {
+		var v T
+		return v, fmt.Errorf("%d primary key values are required, got %d", len(t.info.pkNames), len(pk))
+	}
+	var v T
+	err := t.table.Get(&v, pk...)
+	return v, err
+}
+
+func (t *TypedTable[T]) Count(where string, args ...interface{}) (int, error) {
+	return t.table.Count(where, args...)
+}
+
+func (t *TypedTable[T]) Delete(where string, args ...interface{}) error {
+	return t.table.Delete(where, args...)
+}
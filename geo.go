@@ -0,0 +1,385 @@
+package sql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SRID of Point and Polygon, which is WGS 84 of longitude and latitude
+const SRID = 4326
+
+// Point is a location stored as geometry in postgis and mysql, see Table.Distance
+type Point struct {
+	Lng float64
+	Lat float64
+}
+
+// Polygon is an area bounded by a ring of points, which is closed automatically, see Table.Within
+type Polygon []Point
+
+var (
+	_pointType   = reflect.TypeOf(Point{})
+	_polygonType = reflect.TypeOf(Polygon{})
+
+	_ driver.Valuer = Point{}
+	_ driver.Valuer = Polygon{}
+	_ sql.Scanner   = (*Point)(nil)
+	_ sql.Scanner   = (*Polygon)(nil)
+)
+
+const (
+	wkbPoint   = 1
+	wkbPolygon = 3
+
+	// ewkbSRID is flag of geometry type in EWKB of postgis, which is followed by SRID
+	ewkbSRID = 0x20000000
+)
+
+func (p Point) String() string {
+	return "POINT(" + formatCoord(p) + ")"
+}
+
+// Value returns EWKT of p, which is bound as geometry of mysql internal format in mysql
+func (p Point) Value() (driver.Value, error) {
+	return "SRID=" + strconv.Itoa(SRID) + ";" + p.String(), nil
+}
+
+func (p *Point) Scan(src interface{}) error {
+	g, err := scanGeometry(src)
+	if err != nil {
+		return err
+	}
+
+	if g == nil {
+		*p = Point{}
+		return nil
+	}
+
+	v, ok := g.(Point)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into Point", g)
+	}
+	*p = v
+	return nil
+}
+
+func (p Polygon) String() string {
+	var b strings.Builder
+	b.WriteString("POLYGON((")
+	for i, pt := range p.ring() {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(formatCoord(pt))
+	}
+	b.WriteString("))")
+	return b.String()
+}
+
+// Value returns EWKT of p, which is bound as geometry of mysql internal format in mysql
+func (p Polygon) Value() (driver.Value, error) {
+	if len(p) < 3 {
+		return nil, errors.New("polygon must have at least 3 points")
+	}
+	return "SRID=" + strconv.Itoa(SRID) + ";" + p.String(), nil
+}
+
+func (p *Polygon) Scan(src interface{}) error {
+	g, err := scanGeometry(src)
+	if err != nil {
+		return err
+	}
+
+	if g == nil {
+		*p = nil
+		return nil
+	}
+
+	v, ok := g.(Polygon)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into Polygon", g)
+	}
+	*p = v
+	return nil
+}
+
+// ring returns points of p with the first point appended if it isn't closed
+func (p Polygon) ring() []Point {
+	if len(p) > 0 && p[0] != p[len(p)-1] {
+		return append(append([]Point(nil), p...), p[0])
+	}
+	return p
+}
+
+// Distance returns expression of distance in meters between geometry column and p, e.g.
+//
+//	shops.Select(&list, "? < ?", shops.Distance("location", p), 1000)
+func (t *Table) Distance(column string, p Point) *Expression {
+	if isPostgres(t.driverName) {
+		return Expr("ST_Distance("+t.quote(column)+"::geography, ?::geometry::geography)", p)
+	}
+
+	if t.driverName == "mysql" {
+		return Expr("ST_Distance_Sphere("+t.quote(column)+", ?)", p)
+	}
+	panic("distance isn't supported by " + t.driverName)
+}
+
+// Within returns condition that geometry column is within area, e.g. shops.Select(&list, "?", shops.Within("location", area))
+func (t *Table) Within(column string, area Polygon) *Expression {
+	if isPostgres(t.driverName) {
+		return Expr("ST_Within("+t.quote(column)+", ?::geometry)", area)
+	}
+
+	if t.driverName == "mysql" {
+		return Expr("ST_Within("+t.quote(column)+", ?)", area)
+	}
+	panic("within isn't supported by " + t.driverName)
+}
+
+// isGeoType reports whether typ is Point, Polygon or pointer to them
+func isGeoType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ == _pointType || typ == _polygonType
+}
+
+func formatCoord(p Point) string {
+	return strconv.FormatFloat(p.Lng, 'f', -1, 64) + " " + strconv.FormatFloat(p.Lat, 'f', -1, 64)
+}
+
+// scanGeometry returns Point or Polygon of WKT, hex EWKB of postgis, or internal format of mysql which is SRID and WKB
+func scanGeometry(src interface{}) (interface{}, error) {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, fmt.Errorf("cannot scan %T into geometry", src)
+	}
+
+	s := strings.TrimSpace(string(data))
+	if i := strings.IndexByte(s, ';'); i > 0 && strings.HasPrefix(strings.ToUpper(s), "SRID=") {
+		s = s[i+1:]
+	}
+	if strings.HasPrefix(strings.ToUpper(s), "POINT") || strings.HasPrefix(strings.ToUpper(s), "POLYGON") {
+		return parseWKT(s)
+	}
+
+	if decoded, err := hex.DecodeString(s); err == nil && len(decoded) > 0 {
+		data = decoded
+	}
+
+	if g, n, err := parseWKB(data); err == nil && n == len(data) {
+		return g, nil
+	}
+
+	if len(data) > 4 {
+		if g, n, err := parseWKB(data[4:]); err == nil && n == len(data)-4 {
+			return g, nil
+		}
+	}
+	return nil, errors.New("invalid geometry")
+}
+
+func parseWKT(s string) (interface{}, error) {
+	i, j := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if i < 0 || j < i {
+		return nil, fmt.Errorf("invalid geometry: %s", s)
+	}
+
+	kind := strings.ToUpper(strings.TrimSpace(s[:i]))
+	body := strings.TrimSpace(s[i+1 : j])
+	switch kind {
+	case "POINT":
+		return parseCoord(body)
+	case "POLYGON":
+		if strings.Count(body, "(") != 1 || !strings.HasPrefix(body, "(") || !strings.HasSuffix(body, ")") {
+			return nil, fmt.Errorf("unsupported polygon: %s", s)
+		}
+
+		var p Polygon
+		for _, c := range strings.Split(body[1:len(body)-1], ",") {
+			pt, err := parseCoord(c)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, pt)
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unsupported geometry: %s", s)
+	}
+}
+
+func parseCoord(s string) (Point, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Point{}, fmt.Errorf("invalid point: %s", s)
+	}
+
+	lng, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return Point{}, err
+	}
+
+	lat, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{Lng: lng, Lat: lat}, nil
+}
+
+// parseWKB returns geometry of WKB or EWKB data, and number of bytes it takes
+func parseWKB(data []byte) (interface{}, int, error) {
+	if len(data) < 5 || data[0] > 1 {
+		return nil, 0, errors.New("invalid wkb")
+	}
+
+	var order binary.ByteOrder = binary.BigEndian
+	if data[0] == 1 {
+		order = binary.LittleEndian
+	}
+
+	n := 5
+	typ := order.Uint32(data[1:])
+	if typ&ewkbSRID != 0 {
+		n += 4
+		typ &^= ewkbSRID
+	}
+
+	readFloat := func() (float64, bool) {
+		if len(data) < n+8 {
+			return 0, false
+		}
+		f := math.Float64frombits(order.Uint64(data[n:]))
+		n += 8
+		return f, true
+	}
+
+	readPoint := func() (Point, bool) {
+		lng, ok1 := readFloat()
+		lat, ok2 := readFloat()
+		return Point{Lng: lng, Lat: lat}, ok1 && ok2
+	}
+
+	readCount := func() (int, bool) {
+		if len(data) < n+4 {
+			return 0, false
+		}
+		c := int(order.Uint32(data[n:]))
+		n += 4
+		return c, true
+	}
+
+	switch typ {
+	case wkbPoint:
+		p, ok := readPoint()
+		if !ok {
+			return nil, 0, errors.New("invalid wkb")
+		}
+		return p, n, nil
+	case wkbPolygon:
+		rings, ok := readCount()
+		if !ok || rings > 1 {
+			return nil, 0, errors.New("unsupported polygon")
+		}
+
+		var p Polygon
+		if rings == 1 {
+			count, ok := readCount()
+			if !ok || count > (len(data)-n)/16 {
+				return nil, 0, errors.New("invalid wkb")
+			}
+
+			p = make(Polygon, count)
+			for i := range p {
+				p[i], _ = readPoint()
+			}
+		}
+		return p, n, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported geometry type: %d", typ)
+	}
+}
+
+// mysqlGeometry returns value of mysql internal format, which is SRID and WKB of little endian
+func mysqlGeometry(g interface{}) []byte {
+	var b []byte
+	var buf [8]byte
+	appendUint32 := func(v uint32) {
+		binary.LittleEndian.PutUint32(buf[:], v)
+		b = append(b, buf[:4]...)
+	}
+	appendPoint := func(p Point) {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(p.Lng))
+		b = append(b, buf[:]...)
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(p.Lat))
+		b = append(b, buf[:]...)
+	}
+
+	appendUint32(SRID)
+	b = append(b, 1)
+	switch v := g.(type) {
+	case Point:
+		appendUint32(wkbPoint)
+		appendPoint(v)
+	case Polygon:
+		ring := v.ring()
+		appendUint32(wkbPolygon)
+		appendUint32(1)
+		appendUint32(uint32(len(ring)))
+		for _, p := range ring {
+			appendPoint(p)
+		}
+	}
+	return b
+}
+
+// bindGeometries returns args whose Point and Polygon are replaced by mysql internal format,
+// or args itself if there's no geometry
+func bindGeometries(args []interface{}) []interface{} {
+	var bound []interface{}
+	for i, a := range args {
+		var b interface{}
+		switch v := a.(type) {
+		case Point, Polygon:
+			b = mysqlGeometry(v)
+		case *Point:
+			if v == nil {
+				continue
+			}
+			b = mysqlGeometry(*v)
+		case *Polygon:
+			if v == nil {
+				continue
+			}
+			b = mysqlGeometry(*v)
+		default:
+			continue
+		}
+
+		if bound == nil {
+			bound = append([]interface{}(nil), args...)
+		}
+		bound[i] = b
+	}
+
+	if bound == nil {
+		return args
+	}
+	return bound
+}
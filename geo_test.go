@@ -0,0 +1,73 @@
+package sql
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestGeometry(t *testing.T) {
+	p := Point{Lng: 116.4, Lat: 39.9}
+	if v, _ := p.Value(); v != "SRID=4326;POINT(116.4 39.9)" {
+		t.Error(v)
+	}
+
+	area := Polygon{{0, 0}, {1, 0}, {1, 1}}
+	if v, _ := area.Value(); v != "SRID=4326;POLYGON((0 0,1 0,1 1,0 0))" {
+		t.Error(v)
+	}
+
+	// mysql internal format
+	var got Point
+	if err := got.Scan(mysqlGeometry(p)); err != nil || got != p {
+		t.Error(err, got)
+	}
+
+	var poly Polygon
+	if err := poly.Scan(mysqlGeometry(area)); err != nil || !reflect.DeepEqual(poly, Polygon(area.ring())) {
+		t.Error(err, poly)
+	}
+
+	// hex EWKB of postgis
+	ewkb := "0101000020E6100000000000000000F03F0000000000000040"
+	if err := got.Scan([]byte(ewkb)); err != nil || got != (Point{Lng: 1, Lat: 2}) {
+		t.Error(err, got)
+	}
+
+	data, _ := hex.DecodeString(ewkb)
+	if err := got.Scan(data); err != nil || got != (Point{Lng: 1, Lat: 2}) {
+		t.Error(err, got)
+	}
+
+	if err := got.Scan("POINT(3 4)"); err != nil || got != (Point{Lng: 3, Lat: 4}) {
+		t.Error(err, got)
+	}
+
+	if err := got.Scan(nil); err != nil || got != (Point{}) {
+		t.Error(err, got)
+	}
+
+	if err := got.Scan("POLYGON((0 0,1 0,1 1,0 0))"); err == nil {
+		t.Error("no error")
+	}
+
+	if err := poly.Scan([]byte{1, 2, 3}); err == nil {
+		t.Error("no error")
+	}
+
+	tbl := &Table{driverName: "postgres", name: "shops", opts: &options{}}
+	e := tbl.Distance("location", p)
+	if e.SQL != "ST_Distance(location::geography, ?::geometry::geography)" || e.Args[0] != p {
+		t.Error(e)
+	}
+
+	tbl.driverName = "mysql"
+	if e = tbl.Within("location", area); e.SQL != "ST_Within(location, ?)" {
+		t.Error(e.SQL)
+	}
+
+	args := bindGeometries([]interface{}{1, &p})
+	if !reflect.DeepEqual(args, []interface{}{1, mysqlGeometry(p)}) {
+		t.Error(args)
+	}
+}
@@ -1,5 +1,7 @@
 module github.com/gopub/sql
 
+go 1.18
+
 require (
 	github.com/gopub/log v1.0.3
 	github.com/gopub/mapper v1.0.8
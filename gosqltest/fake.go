@@ -0,0 +1,262 @@
+// Package gosqltest provides a fake database to unit test code using github.com/gopub/sql without a live database
+package gosqltest
+
+import (
+	"context"
+	dbsql "database/sql"
+	"database/sql/driver"
+	"github.com/gopub/sql"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Statement is a statement executed by the fake database
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
+// Fake records statements, and responds with results of matched expectations.
+// Statements without expectation get empty rows, or zero result
+type Fake struct {
+	mu           sync.Mutex
+	statements   []Statement
+	expectations []*Expectation
+}
+
+// New returns a DB of driverName dialect, e.g. mysql, whose statements are handled by the returned Fake
+func New(driverName string) (*sql.DB, *Fake) {
+	f := &Fake{}
+	return sql.OpenDB(driverName, dbsql.OpenDB(&connector{f: f})), f
+}
+
+// Expectation is the canned result of statements containing a substring
+type Expectation struct {
+	substr       string
+	columns      []string
+	rows         [][]driver.Value
+	lastInsertID int64
+	rowsAffected int64
+	err          error
+	once         bool
+}
+
+// On returns expectation of statements containing substr, e.g. f.On("FROM users").Returns([]string{"id", "name"}, []interface{}{1, "bob"}).
+// Expectations are matched in the order they are added, e.g. several Once expectations respond to statements in turn
+func (f *Fake) On(substr string) *Expectation {
+	e := &Expectation{substr: substr}
+	f.mu.Lock()
+	f.expectations = append(f.expectations, e)
+	f.mu.Unlock()
+	return e
+}
+
+// Returns sets rows returned by queries
+func (e *Expectation) Returns(columns []string, rows ...[]interface{}) *Expectation {
+	e.columns = columns
+	e.rows = make([][]driver.Value, len(rows))
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			panic("number of values doesn't match columns")
+		}
+
+		e.rows[i] = make([]driver.Value, len(row))
+		for j, v := range row {
+			dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+			if err != nil {
+				panic(err)
+			}
+			e.rows[i][j] = dv
+		}
+	}
+	return e
+}
+
+// Affects sets result of exec statements
+func (e *Expectation) Affects(lastInsertID, rowsAffected int64) *Expectation {
+	e.lastInsertID = lastInsertID
+	e.rowsAffected = rowsAffected
+	return e
+}
+
+// Fails makes matched statements return err
+func (e *Expectation) Fails(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Once removes the expectation after it's matched
+func (e *Expectation) Once() *Expectation {
+	e.once = true
+	return e
+}
+
+// Statements returns executed statements in order
+func (f *Fake) Statements() []Statement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Statement(nil), f.statements...)
+}
+
+// LastStatement returns the latest executed statement, or nil if there isn't any
+func (f *Fake) LastStatement() *Statement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.statements) == 0 {
+		return nil
+	}
+	s := f.statements[len(f.statements)-1]
+	return &s
+}
+
+// Reset clears statements and expectations
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	f.statements = nil
+	f.expectations = nil
+	f.mu.Unlock()
+}
+
+// handle records the statement, and returns its expectation
+func (f *Fake) handle(query string, args []driver.Value) *Expectation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := Statement{Query: query, Args: make([]interface{}, len(args))}
+	for i, a := range args {
+		s.Args[i] = a
+	}
+	f.statements = append(f.statements, s)
+
+	for i, e := range f.expectations {
+		if strings.Contains(query, e.substr) {
+			if e.once {
+				f.expectations = append(f.expectations[:i], f.expectations[i+1:]...)
+			}
+			return e
+		}
+	}
+	return &Expectation{}
+}
+
+type connector struct {
+	f *Fake
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &conn{f: c.f}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return fakeDriver{f: c.f}
+}
+
+type fakeDriver struct {
+	f *Fake
+}
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) {
+	return &conn{f: d.f}, nil
+}
+
+type conn struct {
+	f *Fake
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{f: c.f, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx records BEGIN statement with args of isolation level and read only, which fails by expectation of BEGIN
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if e := c.f.handle("BEGIN", []driver.Value{dbsql.IsolationLevel(opts.Isolation).String(), opts.ReadOnly}); e.err != nil {
+		return nil, e.err
+	}
+	return &tx{f: c.f}, nil
+}
+
+type tx struct {
+	f *Fake
+}
+
+func (t *tx) Commit() error {
+	return t.f.handle("COMMIT", nil).err
+}
+
+func (t *tx) Rollback() error {
+	t.f.handle("ROLLBACK", nil)
+	return nil
+}
+
+type stmt struct {
+	f     *Fake
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	e := s.f.handle(s.query, args)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return result{lastInsertID: e.lastInsertID, rowsAffected: e.rowsAffected}, nil
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	e := s.f.handle(s.query, args)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &rows{columns: e.columns, values: e.rows}, nil
+}
+
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	i       int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.i >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.i])
+	r.i++
+	return nil
+}
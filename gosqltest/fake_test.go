@@ -0,0 +1,168 @@
+package gosqltest
+
+import (
+	"context"
+	"errors"
+	"github.com/gopub/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type user struct {
+	ID   int64 `sql:"primary key,auto_increment"`
+	Name string
+}
+
+func TestFake(t *testing.T) {
+	db, f := New("mysql")
+
+	f.On("INSERT INTO users").Affects(7, 1)
+	u := &user{Name: "bob"}
+	if err := db.Insert(u); err != nil {
+		t.Fatal(err)
+	}
+
+	if u.ID != 7 {
+		t.Error(u.ID)
+	}
+
+	s := f.LastStatement()
+	if s.Query != "INSERT INTO users(name) VALUES (?)" || !reflect.DeepEqual(s.Args, []interface{}{"bob"}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	f.On("SELECT id, name FROM users").Returns([]string{"id", "name"}, []interface{}{1, "bob"}, []interface{}{2, "alice"})
+	var users []*user
+	if err := db.Select(&users, "name <> ?", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users) != 2 || users[1].ID != 2 || users[1].Name != "alice" {
+		t.Error(users)
+	}
+
+	failure := errors.New("failure")
+	f.On("DELETE").Fails(failure).Once()
+	if err := db.Delete(u); !errors.Is(err, failure) {
+		t.Error(err)
+	}
+
+	if err := db.Delete(u); err != nil {
+		t.Error(err)
+	}
+
+	if n := len(f.Statements()); n != 4 {
+		t.Error(n)
+	}
+
+	f.Reset()
+	if f.LastStatement() != nil {
+		t.Error("statements are not reset")
+	}
+}
+
+func TestFake_tx(t *testing.T) {
+	db, f := New("postgres")
+	failure := errors.New("failure")
+	err := db.WithTxIsolation(context.Background(), sql.LevelSerializable, func(tx *sql.Tx) error {
+		if err := tx.Insert(&user{Name: "bob"}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if err != failure {
+		t.Error(err)
+	}
+
+	var queries []string
+	for _, s := range f.Statements() {
+		queries = append(queries, s.Query)
+	}
+	if !reflect.DeepEqual(queries, []string{"BEGIN", "INSERT INTO users(name) VALUES ($1)", "ROLLBACK"}) {
+		t.Error(queries)
+	}
+
+	if args := f.Statements()[0].Args; !reflect.DeepEqual(args, []interface{}{"Serializable", false}) {
+		t.Error(args)
+	}
+}
+
+func TestFake_txCanceled(t *testing.T) {
+	db, f := New("mysql")
+	ctx, cancel := context.WithCancel(context.Background())
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	err = tx.Insert(&user{Name: "bob"})
+	var aborted *sql.TxAbortedError
+	if !errors.As(err, &aborted) || !errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+
+	if err = tx.Commit(); !errors.As(err, &aborted) {
+		t.Error(err)
+	}
+
+	for i := 0; i < 100 && f.LastStatement().Query != "ROLLBACK"; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if s := f.LastStatement(); s.Query != "ROLLBACK" {
+		t.Error(s.Query)
+	}
+}
+
+func TestRunInRollback(t *testing.T) {
+	db, f := New("mysql")
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "bob"})
+	wrapped := 0
+	RunInRollback(t, db, func(tx *sql.Tx) {
+		txDB := tx.DB()
+		defer txDB.Close()
+		txDB.Use(func(next sql.Executor) sql.Executor {
+			wrapped++
+			return next
+		})
+		txDB.SetSchema("test")
+		if err := txDB.Insert(&user{Name: "bob"}); err != nil {
+			t.Fatal(err)
+		}
+
+		var u user
+		if err := txDB.SelectOne(&u, "name = ?", "bob"); err != nil {
+			t.Fatal(err)
+		}
+		if u.ID != 1 || u.Name != "bob" {
+			t.Error(u)
+		}
+
+		err := txDB.WithTxIsolation(context.Background(), sql.LevelDefault, func(tx *sql.Tx) error {
+			return tx.Insert(&user{Name: "alice"})
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	var queries []string
+	for _, s := range f.Statements() {
+		queries = append(queries, s.Query)
+	}
+	expected := []string{"BEGIN", "INSERT INTO test.users(name) VALUES (?)", "SELECT id, name FROM test.users WHERE name = ?",
+		"SAVEPOINT sp_1", "INSERT INTO test.users(name) VALUES (?)", "RELEASE SAVEPOINT sp_1", "ROLLBACK"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Error(queries)
+	}
+
+	n := wrapped
+	if err := db.Insert(&user{Name: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Query != "INSERT INTO users(name) VALUES (?)" || n == 0 || wrapped != n {
+		t.Error(s.Query, n, wrapped)
+	}
+}
@@ -0,0 +1,23 @@
+package gosqltest
+
+import (
+	"github.com/gopub/sql"
+	"testing"
+)
+
+// RunInRollback runs fn in a transaction of db which is always rolled back, so tests don't leak rows into each other.
+// Code depending on *sql.DB can be tested with tx.DB()
+func RunInRollback(t testing.TB, db *sql.DB, fn func(tx *sql.Tx)) {
+	t.Helper()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			t.Error(err)
+		}
+	}()
+	fn(tx)
+}
@@ -0,0 +1,40 @@
+package sql
+
+import (
+	"context"
+	"time"
+)
+
+// HealthStatus describes the result of a health check
+type HealthStatus struct {
+	Latency         time.Duration
+	OpenConnections int
+	InUse           int
+	Idle            int
+}
+
+// Ping verifies the connection to database is still alive
+func (d *DB) Ping(ctx context.Context) error {
+	return d.db.PingContext(ctx)
+}
+
+// HealthCheck pings database and runs "SELECT 1" if withQuery is true.
+// Use a context with deadline to limit the time spent on it, e.g. in readiness probes.
+// Status is returned even if the check failed.
+func (d *DB) HealthCheck(ctx context.Context, withQuery bool) (*HealthStatus, error) {
+	start := time.Now()
+	err := d.db.PingContext(ctx)
+	if err == nil && withQuery {
+		var n int
+		err = d.db.QueryRowContext(ctx, "SELECT 1").Scan(&n)
+	}
+
+	stats := d.db.Stats()
+	status := &HealthStatus{
+		Latency:         time.Since(start),
+		OpenConnections: stats.OpenConnections,
+		InUse:           stats.InUse,
+		Idle:            stats.Idle,
+	}
+	return status, err
+}
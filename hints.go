@@ -0,0 +1,117 @@
+package sql
+
+import (
+	"strings"
+)
+
+// indexHint is USE or FORCE index hint, see UseIndex
+type indexHint struct {
+	force bool
+	names []string
+}
+
+// Hint appends optimizer hints of Select, e.g. Hint("NO_INDEX_MERGE(users)"). They're rendered as /*+ ... */ after
+// SELECT in mysql, before the query in postgres for pg_hint_plan, and as OPTION (...) in sqlserver
+func (t *Table) Hint(hints ...string) *Table {
+	for _, h := range hints {
+		if strings.Contains(h, "*/") || strings.Contains(h, ";") {
+			panic("invalid hint: " + h)
+		}
+	}
+	c := *t
+	c.hints = append(append([]string(nil), t.hints...), hints...)
+	return &c
+}
+
+// UseIndex suggests indexes of Select, e.g. UseIndex("idx_users_email")
+func (t *Table) UseIndex(names ...string) *Table {
+	return t.appendIndexHint(false, names)
+}
+
+// ForceIndex forces Select to use indexes
+func (t *Table) ForceIndex(names ...string) *Table {
+	return t.appendIndexHint(true, names)
+}
+
+func (t *Table) appendIndexHint(force bool, names []string) *Table {
+	if len(names) == 0 {
+		panic("no index")
+	}
+	c := *t
+	c.indexHints = append(append([]indexHint(nil), t.indexHints...), indexHint{force: force, names: names})
+	return &c
+}
+
+// hintTarget returns alias of t if there is one, otherwise its name, which are referenced by pg_hint_plan hints
+func (t *Table) hintTarget() string {
+	fields := strings.Fields(t.name)
+	return fields[len(fields)-1]
+}
+
+// optimizerHints returns hints in the comment following SELECT of mysql, or preceding the query of postgres
+func (t *Table) optimizerHints() []string {
+	var hints []string
+	if ms := t.maxExecutionMillis(); ms > 0 && t.driverName == "mysql" {
+		hints = append(hints, t.maxExecutionHint())
+	}
+	hints = append(hints, t.hints...)
+	if isPostgres(t.driverName) {
+		for _, h := range t.indexHints {
+			hints = append(hints, "IndexScan("+t.hintTarget()+" "+strings.Join(h.names, " ")+")")
+		}
+	}
+	return hints
+}
+
+// hintComment returns optimizer hints as /*+ ... */ followed by a space, or empty string if there's none
+func (t *Table) hintComment() string {
+	if t.driverName == "sqlserver" || t.driverName == "mssql" {
+		return ""
+	}
+
+	hints := t.optimizerHints()
+	if len(hints) == 0 {
+		return ""
+	}
+	return "/*+ " + strings.Join(hints, " ") + " */ "
+}
+
+// hintOption returns OPTION clause of sqlserver preceded by a space
+func (t *Table) hintOption() string {
+	if len(t.hints) == 0 || (t.driverName != "sqlserver" && t.driverName != "mssql") {
+		return ""
+	}
+	return " OPTION (" + strings.Join(t.hints, ", ") + ")"
+}
+
+// indexHintClause returns index hints following the table in FROM clause preceded by a space
+func (t *Table) indexHintClause() string {
+	if len(t.indexHints) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, h := range t.indexHints {
+		switch t.driverName {
+		case "mysql":
+			if h.force {
+				b.WriteString(" FORCE INDEX (")
+			} else {
+				b.WriteString(" USE INDEX (")
+			}
+			b.WriteString(t.quoteColumns(h.names))
+			b.WriteString(")")
+		case "sqlserver", "mssql":
+			b.WriteString(" WITH (INDEX(")
+			b.WriteString(t.quoteColumns(h.names))
+			b.WriteString("))")
+		case "sqlite3":
+			if len(h.names) > 1 || len(t.indexHints) > 1 {
+				panic("sqlite3 supports only one index hint")
+			}
+			b.WriteString(" INDEXED BY ")
+			b.WriteString(t.quote(h.names[0]))
+		}
+	}
+	return b.String()
+}
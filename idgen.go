@@ -0,0 +1,185 @@
+package sql
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// IDGenerator generates ids of primary keys tagged by id=name, e.g. `sql:"primary key,id=snowflake"`.
+// Zero ids are generated on insert. Built-in generators are snowflake of int64, ulid and ksuid of string
+type IDGenerator interface {
+	NextID() interface{}
+}
+
+// IDGeneratorFunc is a function implementing IDGenerator
+type IDGeneratorFunc func() interface{}
+
+func (f IDGeneratorFunc) NextID() interface{} {
+	return f()
+}
+
+var _idGenerators = map[string]IDGenerator{
+	"snowflake": NewSnowflake(randomNode()),
+	"ulid":      IDGeneratorFunc(func() interface{} { return NewULID() }),
+	"ksuid":     IDGeneratorFunc(func() interface{} { return NewKSUID() }),
+}
+
+// SetIDGenerator sets generator of id=name, which overrides the built-in one of the same name,
+// e.g. db.SetIDGenerator("snowflake", sql.NewSnowflake(nodeID)) with a node id unique among instances
+func (d *DB) SetIDGenerator(name string, g IDGenerator) {
+	// generators are copied, which may be shared by DB of Tx.DB and DryRun
+	generators := make(map[string]IDGenerator, len(d.opts.idGenerators)+1)
+	for k, v := range d.opts.idGenerators {
+		generators[k] = v
+	}
+	generators[name] = g
+	d.opts.idGenerators = generators
+}
+
+func (o *options) idGenerator(name string) IDGenerator {
+	if g, ok := o.idGenerators[name]; ok {
+		return g
+	}
+
+	if g, ok := _idGenerators[name]; ok {
+		return g
+	}
+	panic("no id generator: " + name)
+}
+
+// generatedID returns value of f, which is set to a generated id if it's zero
+func (t *Table) generatedID(f reflect.Value, generator string) interface{} {
+	if !f.IsZero() {
+		return f.Interface()
+	}
+
+	id := reflect.ValueOf(t.opts.idGenerator(generator).NextID())
+	if !id.Type().ConvertibleTo(f.Type()) {
+		panic(fmt.Sprintf("id of %s is %v, which can't be converted to %v", generator, id.Type(), f.Type()))
+	}
+	id = id.Convert(f.Type())
+	if f.CanSet() {
+		f.Set(id)
+	}
+	return id.Interface()
+}
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	maxSnowflakeNode      = 1<<snowflakeNodeBits - 1
+	maxSnowflakeSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+// snowflakeEpoch is 2020-01-01 in milliseconds, which leaves 41 bits of timestamp for about 69 years
+var snowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+
+// Snowflake generates int64 ids composed of millisecond timestamp, node and sequence in the millisecond,
+// which are ordered by time
+type Snowflake struct {
+	mu       sync.Mutex
+	node     int64
+	last     int64
+	sequence int64
+}
+
+// NewSnowflake returns a snowflake generator of node, which must be in [0, 1023] and unique among instances
+func NewSnowflake(node int64) *Snowflake {
+	if node < 0 || node > maxSnowflakeNode {
+		panic(fmt.Sprintf("invalid node: %d", node))
+	}
+	return &Snowflake{node: node}
+}
+
+func (s *Snowflake) NextID() interface{} {
+	return s.Next()
+}
+
+// Next returns the next id, it waits for the next millisecond if ids of the current one run out
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UnixNano()/int64(time.Millisecond) - snowflakeEpoch
+	if now < s.last {
+		// clock moved backwards, keep ids increasing
+		now = s.last
+	}
+
+	if now == s.last {
+		s.sequence = (s.sequence + 1) & maxSnowflakeSequence
+		if s.sequence == 0 {
+			for now <= s.last {
+				time.Sleep(time.Millisecond / 10)
+				now = time.Now().UnixNano()/int64(time.Millisecond) - snowflakeEpoch
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.last = now
+	return now<<(snowflakeNodeBits+snowflakeSequenceBits) | s.node<<snowflakeSequenceBits | s.sequence
+}
+
+func randomNode() int64 {
+	var b [2]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return int64(binary.BigEndian.Uint16(b[:])) & maxSnowflakeNode
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a ULID of 26 characters, which is composed of 48 bits millisecond timestamp and 80 random bits,
+// and is ordered by time
+func NewULID() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	binary.BigEndian.PutUint16(b[:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic(err)
+	}
+
+	// 128 bits are encoded by 26 characters of 5 bits, the first of which has 3 bits
+	var s [26]byte
+	n := new(big.Int).SetBytes(b[:])
+	mask := big.NewInt(31)
+	for i := len(s) - 1; i >= 0; i-- {
+		s[i] = crockfordBase32[new(big.Int).And(n, mask).Int64()]
+		n.Rsh(n, 5)
+	}
+	return string(s[:])
+}
+
+const (
+	base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	// ksuidEpoch is 2014-05-13 in seconds
+	ksuidEpoch = 1400000000
+)
+
+// NewKSUID returns a KSUID of 27 characters, which is composed of 32 bits second timestamp and 128 random bits,
+// and is ordered by time
+func NewKSUID() string {
+	var b [20]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(time.Now().Unix()-ksuidEpoch))
+	if _, err := rand.Read(b[4:]); err != nil {
+		panic(err)
+	}
+
+	var s [27]byte
+	n := new(big.Int).SetBytes(b[:])
+	base := big.NewInt(62)
+	rem := new(big.Int)
+	for i := len(s) - 1; i >= 0; i-- {
+		n.DivMod(n, base, rem)
+		s[i] = base62[rem.Int64()]
+	}
+	return string(s[:])
+}
@@ -0,0 +1,95 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+type idRecord struct {
+	ID   int64 `sql:"primary key,id=snowflake"`
+	Name string
+}
+
+type ulidRecord struct {
+	ID   string `sql:"primary key,id=ulid"`
+	Name string
+}
+
+func TestSnowflake(t *testing.T) {
+	s := NewSnowflake(5)
+	prev := int64(0)
+	for i := 0; i < 10000; i++ {
+		id := s.Next()
+		if id <= prev {
+			t.Fatal(id, prev)
+		}
+		if id>>snowflakeSequenceBits&maxSnowflakeNode != 5 {
+			t.Fatal(id)
+		}
+		prev = id
+	}
+}
+
+func TestULID_KSUID(t *testing.T) {
+	a, b := NewULID(), NewULID()
+	if len(a) != 26 || a == b || a[:8] > b[:8] {
+		t.Error(a, b)
+	}
+
+	a, b = NewKSUID(), NewKSUID()
+	if len(a) != 27 || a == b || a[:4] > b[:4] {
+		t.Error(a, b)
+	}
+}
+
+func TestIDGenerator_insert(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(idRecord{}))
+	if !reflect.DeepEqual(info.idGenerators, map[string]string{"id": "snowflake"}) {
+		t.Fatal(info.idGenerators)
+	}
+
+	tbl := &Table{driverName: "mysql", name: "id_records", opts: &options{}}
+	r := &idRecord{Name: "a"}
+	_, values, err := tbl.prepareInsertQuery(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID == 0 || values[0] != r.ID {
+		t.Error(r, values)
+	}
+
+	id := r.ID
+	if _, values, _ = tbl.prepareInsertQuery(r); r.ID != id || values[0] != id {
+		t.Error("non-zero id is changed", r.ID, id)
+	}
+
+	tbl.opts = &options{idGenerators: map[string]IDGenerator{
+		"ulid": IDGeneratorFunc(func() interface{} { return "fixed" }),
+	}}
+	u := &ulidRecord{}
+	if _, values, _ = tbl.prepareInsertQuery(u); u.ID != "fixed" || values[0] != "fixed" {
+		t.Error(u, values)
+	}
+}
+
+func TestIDGenerator_update(t *testing.T) {
+	tbl := &Table{driverName: "mysql", name: "id_records", opts: &options{}}
+	r := &idRecord{Name: "a"}
+	if _, _, err := tbl.prepareUpdateQuery(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.ID != 0 {
+		t.Error("id is generated by update", r.ID)
+	}
+
+	v := reflect.ValueOf(r).Elem()
+	info := getColumnInfo(v.Type())
+	if id, err := tbl.fieldValue(v, info, "id", false); err != nil || id != int64(0) || r.ID != 0 {
+		t.Error(id, err)
+	}
+
+	if id, err := tbl.fieldValue(v, info, "id", true); err != nil || id == int64(0) || id != r.ID {
+		t.Error(id, err)
+	}
+}
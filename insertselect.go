@@ -0,0 +1,49 @@
+package sql
+
+import (
+	"github.com/gopub/log"
+)
+
+// InsertFromSelect inserts rows selected by sel into columns of t on server side, e.g. archiving old orders
+//
+//	old := db.Table("orders").Columns("id", "amount", "created_at").Subquery("created_at < ?", cutoff)
+//	n, err := db.Table("archived_orders").InsertFromSelect([]string{"id", "amount", "created_at"}, old)
+//
+// Columns selected by sel must match columns, which are all columns of t if it's empty.
+// It returns the number of inserted rows
+func (t *Table) InsertFromSelect(columns []string, sel *Expression) (int64, error) {
+	if sel == nil {
+		panic("sel is nil")
+	}
+
+	if err := t.checkScopedWrite(); err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("INSERT INTO ")
+	buf.WriteString(t.quote(t.name))
+	if len(columns) > 0 {
+		buf.WriteString("(")
+		buf.WriteString(t.quoteColumns(columns))
+		buf.WriteString(")")
+	}
+	buf.WriteString(" ")
+	buf.WriteString(sel.SQL)
+	query := buf.String()
+
+	t.logQuery(query, sel.Args)
+	result, err := t.exec(query, sel.Args...)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		log.Error(err)
+	}
+	return n, err
+}
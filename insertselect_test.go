@@ -0,0 +1,22 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+func TestTable_InsertFromSelect(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	f.On("INSERT INTO archived_items").Affects(0, 2)
+	old := db.Table("items").Columns("id", "name").Subquery("id < ?", 10)
+	n, err := db.Table("archived_items").InsertFromSelect([]string{"id", "name"}, old)
+	if err != nil || n != 2 {
+		t.Fatal(err, n)
+	}
+
+	s := f.LastStatement()
+	if s.Query != "INSERT INTO archived_items(id, name) SELECT id, name FROM items WHERE id < $1" || !reflect.DeepEqual(s.Args, []interface{}{int64(10)}) {
+		t.Error(s)
+	}
+}
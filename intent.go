@@ -0,0 +1,111 @@
+package sql
+
+import (
+	"errors"
+	"github.com/gopub/log"
+	"time"
+)
+
+var ErrIntentExists = errors.New("intent exists")
+
+const (
+	IntentPending = "pending"
+	IntentDone    = "done"
+	IntentFailed  = "failed"
+)
+
+// Intent records an external side effect, e.g. a payment request.
+// Table schema:
+//
+//	CREATE TABLE intents(
+//		id VARCHAR(64) PRIMARY KEY,
+//		status VARCHAR(16) NOT NULL,
+//		payload BLOB NOT NULL,
+//		created_at BIGINT NOT NULL,
+//		updated_at BIGINT NOT NULL
+//	)
+type Intent struct {
+	ID        string `sql:"primary key"`
+	Status    string
+	Payload   []byte
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// AtMostOnce saves intent as pending before calling fn, then marks it done or failed according to fn's result.
+// If intent.ID has been saved before, fn is not called and ErrIntentExists is returned.
+// If the process exits while fn is running, intent stays pending and should be resolved by ReconcileIntents.
+func (d *DB) AtMostOnce(table string, intent *Intent, fn func() error) error {
+	now := time.Now().Unix()
+	intent.Status = IntentPending
+	intent.CreatedAt = now
+	intent.UpdatedAt = now
+	if err := d.Table(table).Insert(intent); err != nil {
+		// replicas may not have the intent yet
+		primary := (&SelectOptions{UsePrimary: true}).apply(d.Table(table))
+		if n, cerr := primary.Count("id=?", intent.ID); cerr == nil && n > 0 {
+			return ErrIntentExists
+		}
+		return err
+	}
+
+	fnErr := fn()
+	status := IntentDone
+	if fnErr != nil {
+		status = IntentFailed
+	}
+
+	if err := d.resolveIntent(table, intent, status); err != nil {
+		log.Error(err)
+		if fnErr == nil {
+			return err
+		}
+	}
+	return fnErr
+}
+
+// ReconcileIntents passes intents which have been pending for longer than age to resolve,
+// and saves the status returned by resolve, which is usually IntentDone or IntentFailed.
+// Status of an intent which isn't pending any more, e.g. completed by AtMostOnce or another reconciler
+// while resolve is running, is left unchanged
+func (d *DB) ReconcileIntents(table string, age time.Duration, resolve func(intent *Intent) (string, error)) error {
+	var intents []*Intent
+	err := d.Table(table).Select(&intents, "status=? AND created_at<?", IntentPending, time.Now().Add(-age).Unix())
+	if err != nil {
+		return err
+	}
+
+	for _, intent := range intents {
+		status, err := resolve(intent)
+		if err != nil {
+			return err
+		}
+
+		if err = d.resolveIntent(table, intent, status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveIntent sets status of intent if it's still pending
+func (d *DB) resolveIntent(table string, intent *Intent, status string) error {
+	now := time.Now().Unix()
+	result, err := d.Table(table).updateColumnsMap(map[string]interface{}{"status": status, "updated_at": now},
+		"id=? AND status=?", []interface{}{intent.ID, IntentPending})
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if n > 0 {
+		intent.Status = status
+		intent.UpdatedAt = now
+	}
+	return nil
+}
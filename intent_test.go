@@ -0,0 +1,70 @@
+package sql_test
+
+import (
+	"errors"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDB_AtMostOnce(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("UPDATE").Affects(0, 1)
+	intent := &sql.Intent{ID: "pay-1", Payload: []byte("{}")}
+	called := false
+	if err := db.AtMostOnce("intents", intent, func() error { called = true; return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if !called || intent.Status != sql.IntentDone {
+		t.Error(called, intent.Status)
+	}
+
+	s := f.LastStatement()
+	if s.Query != "UPDATE intents SET status = ?, updated_at = ? WHERE id=? AND status=?" ||
+		!reflect.DeepEqual([]interface{}{s.Args[0], s.Args[2], s.Args[3]}, []interface{}{sql.IntentDone, "pay-1", sql.IntentPending}) {
+		t.Error(s.Query, s.Args)
+	}
+}
+
+func TestDB_AtMostOnce_exists(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("INSERT").Fails(errors.New("duplicate entry"))
+	f.On("SELECT COUNT").Returns([]string{"count"}, []interface{}{1})
+	called := false
+	err := db.AtMostOnce("intents", &sql.Intent{ID: "pay-1"}, func() error { called = true; return nil })
+	if err != sql.ErrIntentExists || called {
+		t.Fatal(err, called)
+	}
+}
+
+func TestDB_ReconcileIntents(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("SELECT").Returns([]string{"id", "status", "payload", "created_at", "updated_at"},
+		[]interface{}{"pay-1", sql.IntentPending, []byte("{}"), 1, 1},
+		[]interface{}{"pay-2", sql.IntentPending, []byte("{}"), 1, 1})
+	// pay-1 is completed by AtMostOnce meanwhile
+	f.On("UPDATE").Affects(0, 0).Once()
+	f.On("UPDATE").Affects(0, 1).Once()
+
+	var resolved []*sql.Intent
+	err := db.ReconcileIntents("intents", time.Minute, func(intent *sql.Intent) (string, error) {
+		resolved = append(resolved, intent)
+		return sql.IntentFailed, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resolved) != 2 || resolved[0].Status != sql.IntentPending || resolved[1].Status != sql.IntentFailed {
+		t.Fatal(resolved)
+	}
+
+	for _, s := range f.Statements()[1:] {
+		if s.Query != "UPDATE intents SET status = ?, updated_at = ? WHERE id=? AND status=?" || s.Args[3] != sql.IntentPending {
+			t.Error(s.Query, s.Args)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Package sqltag parses sql tags of struct fields
+package sqltag
+
+import (
+	"github.com/gopub/mapper"
+	"strings"
+)
+
+// Keywords are options of sql tags, which can't be column names
+var Keywords = map[string]struct{}{
+	"primary":        {},
+	"key":            {},
+	"auto_increment": {},
+	"insert":         {},
+	"create":         {},
+	"table":          {},
+	"database":       {},
+	"select":         {},
+	"update":         {},
+	"unique":         {},
+	"int":            {},
+	"bigint":         {},
+	"bool":           {},
+	"tinyint":        {},
+	"double":         {},
+	"date":           {},
+	"json":           {},
+	"nullable":       {},
+	"index":          {},
+	"tenant":         {},
+	"encrypted":      {},
+	"sensitive":      {},
+	"array":          {},
+	"computed":       {},
+}
+
+// Normalize returns tag in lower case without surrounding spaces
+func Normalize(tag string) string {
+	return strings.TrimSpace(strings.ToLower(tag))
+}
+
+// ColumnName returns column name declared by the first option of normalized tag, or empty string if it's not a name
+func ColumnName(tag string) string {
+	name := strings.Split(tag, ",")[0]
+	if _, ok := Keywords[name]; ok || !mapper.MatchPattern(mapper.PatternVariable, name) {
+		return ""
+	}
+	return name
+}
+
+// HasOption reports whether comma separated tag contains option
+func HasOption(tag, option string) bool {
+	for _, s := range strings.Split(tag, ",") {
+		if strings.TrimSpace(s) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPrimaryKey reports whether tag declares primary key
+func IsPrimaryKey(tag string) bool {
+	return strings.Contains(tag, "primary key")
+}
@@ -0,0 +1,156 @@
+package sql
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExplainString returns query with args substituted as escaped literals, which can be copied into a sql client.
+// It's only for debugging, statements are always executed with bound args
+func (d *DB) ExplainString(query string, args ...interface{}) string {
+	return interpolate(d.driverName, query, args)
+}
+
+// ExplainString returns the interpolated select query of Select(records, where, args...).
+// records is a pointer to struct or slice of structs
+func (t *Table) ExplainString(records interface{}, where string, args ...interface{}) string {
+	typ := reflect.TypeOf(records)
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		panic("not pointer to a struct or slice of structs")
+	}
+
+	info := t.getSelectColumnInfo(typ)
+	if err := t.checkWhere(info, where, args); err != nil {
+		panic(err)
+	}
+	where, args = expandExpressions(where, args)
+	query, args := t.buildSelectQuery(t.selectInfo(info), where, args)
+	return interpolate(t.driverName, t.annotate(query), args)
+}
+
+// interpolate replaces placeholders ? (and $n for postgres) in query with literals of args. Placeholders in quoted strings or
+// identifiers are kept, so are placeholders without args
+func interpolate(driverName, query string, args []interface{}) string {
+	if len(args) == 0 {
+		return query
+	}
+
+	var buf strings.Builder
+	var quote byte
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?' && n < len(args):
+			buf.WriteString(sqlLiteral(driverName, args[n]))
+			n++
+			continue
+		case c == '$' && isPostgres(driverName) && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			k, _ := strconv.Atoi(query[i+1 : j])
+			if k >= 1 && k <= len(args) {
+				buf.WriteString(sqlLiteral(driverName, args[k-1]))
+				i = j - 1
+				continue
+			}
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
+
+// sqlLiteral returns v as a sql literal of driverName
+func sqlLiteral(driverName string, v interface{}) string {
+	if v == nil {
+		return "NULL"
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return "NULL"
+	}
+
+	if _, ok := v.(SensitiveValue); ok {
+		return quoteString(driverName, redacted)
+	}
+
+	if u, ok := v.(UUID); ok && isBinaryUUID(driverName) {
+		return sqlLiteral(driverName, u[:])
+	}
+
+	if isPostgres(driverName) && isArrayType(rv.Type()) {
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return quoteString(driverName, formatArray(rv))
+	}
+
+	if dv, ok := v.(driver.Valuer); ok {
+		val, err := dv.Value()
+		if err != nil {
+			return quoteString(driverName, fmt.Sprint(v))
+		}
+		return sqlLiteral(driverName, val)
+	}
+
+	switch x := v.(type) {
+	case []byte:
+		if isPostgres(driverName) {
+			return `'\x` + hex.EncodeToString(x) + "'"
+		}
+		return "X'" + hex.EncodeToString(x) + "'"
+	case time.Time:
+		if isPostgres(driverName) {
+			return quoteString(driverName, x.Format("2006-01-02 15:04:05.999999-07:00"))
+		}
+		return quoteString(driverName, x.Format("2006-01-02 15:04:05.999999"))
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		return sqlLiteral(driverName, rv.Elem().Interface())
+	case reflect.Bool:
+		if rv.Bool() {
+			return "TRUE"
+		}
+		return "FALSE"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+	case reflect.String:
+		return quoteString(driverName, rv.String())
+	default:
+		return quoteString(driverName, fmt.Sprint(v))
+	}
+}
+
+// quoteString returns s as a quoted string literal. mysql treats backslash as escape character by default
+func quoteString(driverName, s string) string {
+	s = strings.Replace(s, "'", "''", -1)
+	if driverName == "mysql" {
+		s = strings.Replace(s, `\`, `\\`, -1)
+		s = strings.Replace(s, "\x00", `\0`, -1)
+	}
+	return "'" + s + "'"
+}
@@ -0,0 +1,48 @@
+package sql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterpolate(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	name := "bob"
+	var nilName *string
+	tests := []struct {
+		driverName string
+		query      string
+		args       []interface{}
+		expected   string
+	}{
+		{"mysql", "SELECT * FROM users WHERE id = ? AND name = ?", []interface{}{1, "it's"},
+			"SELECT * FROM users WHERE id = 1 AND name = 'it''s'"},
+		{"mysql", "name = ? AND code = '?'", []interface{}{`a\b`}, `name = 'a\\b' AND code = '?'`},
+		{"sqlite3", "name = ?", []interface{}{`a\b`}, `name = 'a\b'`},
+		{"mysql", "data = ? AND deleted = ?", []interface{}{[]byte("ab"), false}, "data = X'6162' AND deleted = FALSE"},
+		{"postgres", "data = $1 AND id IN ($2, $2)", []interface{}{[]byte("ab"), int64(7)}, `data = '\x6162' AND id IN (7, 7)`},
+		{"mysql", "name = ? AND nick = ? AND score = ?", []interface{}{&name, nilName, 1.5}, "name = 'bob' AND nick = NULL AND score = 1.5"},
+		{"mysql", "created_at > ?", []interface{}{at}, "created_at > '2020-01-02 03:04:05'"},
+		{"mysql", "id = ? AND name = ?", []interface{}{nil}, "id = NULL AND name = ?"},
+	}
+	for _, test := range tests {
+		if got := interpolate(test.driverName, test.query, test.args); got != test.expected {
+			t.Error(test.query, got)
+		}
+	}
+}
+
+func TestTable_ExplainString(t *testing.T) {
+	type product struct {
+		ID    int64 `sql:"primary key"`
+		Name  string
+		Price float64
+	}
+
+	tbl := &Table{driverName: "postgres", name: "products", opts: &options{}}
+	got := tbl.Limit(10).ExplainString(&[]*product{}, "name = ? AND price > ?", "it's", 9.5)
+	expected := "SELECT id, name, price FROM products WHERE name = 'it''s' AND price > 9.5 LIMIT 10"
+	if got != expected {
+		t.Error(got)
+	}
+}
@@ -0,0 +1,198 @@
+package sql
+
+import (
+	"context"
+	"github.com/gopub/log"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+var _cursorID int64
+
+// FetchSize returns a copy of t whose Iterate fetches n rows per round trip by a server-side cursor in postgres.
+// Rows of other drivers are streamed by Iterate regardless of n
+func (t *Table) FetchSize(n int) *Table {
+	if n <= 0 {
+		panic("fetch size must be positive")
+	}
+	c := *t
+	c.fetchSize = n
+	return &c
+}
+
+// Iterate scans rows matching where into record one by one, and calls fn after each row, e.g. for exporting
+// large result sets without holding them in memory. record is a pointer to struct, and it stops if fn returns error.
+// The query timeout applies to the whole iteration, which can be disabled by Timeout(-1)
+func (t *Table) Iterate(record interface{}, fn func() error, where string, args ...interface{}) error {
+	rv := reflect.ValueOf(record)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		panic("not pointer to a struct")
+	}
+	elem := rv.Elem()
+
+	info := t.getSelectColumnInfo(elem.Type())
+	if err := t.checkWhere(info, where, args); err != nil {
+		log.Error(err)
+		return err
+	}
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(info, where, args)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	info = t.selectInfo(info)
+	query, args := t.buildSelectQuery(info, where, args)
+	s := newStructScanner(getScanPlan(elem.Type(), info), t.opts)
+
+	if isPostgres(t.driverName) && t.fetchSize > 0 {
+		err = t.withTx(func(c *Table) error {
+			return c.iterateCursor(s, elem, fn, query, args)
+		})
+	} else if t.needsTimeoutTx() {
+		err = t.withTx(func(c *Table) error {
+			return c.iterateRows(s, elem, fn, query, args)
+		})
+	} else {
+		err = t.iterateRows(s, elem, fn, query, args)
+	}
+
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+// Progress is progress of SelectForEachRow
+type Progress struct {
+	// Rows is number of rows processed
+	Rows int64
+
+	// Elapsed is duration since the query started
+	Elapsed time.Duration
+}
+
+// SelectForEachRow is Iterate bound to ctx, which is checked between rows so that long exports can be cancelled,
+// e.g. by signals of CLIs. progress is called after fn of each row if it's not nil, and it returns ctx.Err() if ctx is done
+func (t *Table) SelectForEachRow(ctx context.Context, record interface{}, fn func() error, progress func(Progress),
+	where string, args ...interface{}) error {
+	start := time.Now()
+	var n int64
+	return t.WithContext(ctx).Iterate(record, func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(); err != nil {
+			return err
+		}
+
+		n++
+		if progress != nil {
+			progress(Progress{Rows: n, Elapsed: time.Since(start)})
+		}
+		return nil
+	}, where, args...)
+}
+
+// iterateRows streams rows of query
+func (t *Table) iterateRows(s *structScanner, elem reflect.Value, fn func() error, query string, args []interface{}) error {
+	t.logQuery(query, args)
+	ctx, cancel := t.context()
+	defer cancel()
+	if err := t.setStatementTimeout(ctx); err != nil {
+		return err
+	}
+
+	rows, err := t.reader.QueryContext(ctx, t.annotate(query), args...)
+	if err != nil {
+		return t.queryError(query, args, err)
+	}
+	defer rows.Close()
+
+	_, err = iterate(rows, s, elem, fn)
+	return t.txError(err)
+}
+
+// iterateCursor fetches rows of query by a cursor of transaction of t
+func (t *Table) iterateCursor(s *structScanner, elem reflect.Value, fn func() error, query string, args []interface{}) error {
+	cursor := "gosql_cursor_" + strconv.FormatInt(atomic.AddInt64(&_cursorID, 1), 10)
+	declare := "DECLARE " + cursor + " NO SCROLL CURSOR FOR " + query
+	t.logQuery(declare, args)
+	ctx, cancel := t.context()
+	defer cancel()
+	if err := t.setStatementTimeout(ctx); err != nil {
+		return err
+	}
+
+	if _, err := t.exe.ExecContext(ctx, t.annotate(declare), args...); err != nil {
+		return t.queryError(declare, args, err)
+	}
+
+	fetch := "FETCH FORWARD " + strconv.Itoa(t.fetchSize) + " FROM " + cursor
+	for {
+		t.logQuery(fetch, nil)
+		rows, err := t.exe.QueryContext(ctx, fetch)
+		if err != nil {
+			return t.queryError(fetch, nil, err)
+		}
+
+		n, err := iterate(rows, s, elem, fn)
+		rows.Close()
+		if err != nil {
+			return t.txError(err)
+		}
+
+		if n < t.fetchSize {
+			break
+		}
+	}
+
+	closeCursor := "CLOSE " + cursor
+	_, err := t.exe.ExecContext(ctx, closeCursor)
+	return t.queryError(closeCursor, nil, err)
+}
+
+// iterate scans rows into elem, and calls fn after each row. It returns number of scanned rows
+func iterate(rows rowIterator, s *structScanner, elem reflect.Value, fn func() error) (int, error) {
+	n := 0
+	zero := reflect.Zero(elem.Type())
+	for rows.Next() {
+		elem.Set(zero)
+		if err := s.scan(rows, elem); err != nil {
+			return n, err
+		}
+		n++
+
+		if err := fn(); err != nil {
+			return n, err
+		}
+	}
+	return n, rows.Err()
+}
+
+type rowIterator interface {
+	rowScanner
+	Next() bool
+	Err() error
+}
+
+func (d *DB) Iterate(record interface{}, fn func() error, where string, args ...interface{}) error {
+	return d.Table(getTableName(record)).Iterate(record, fn, where, args...)
+}
+
+func (t *Tx) Iterate(record interface{}, fn func() error, where string, args ...interface{}) error {
+	return t.Table(getTableName(record)).Iterate(record, fn, where, args...)
+}
+
+func (d *DB) SelectForEachRow(ctx context.Context, record interface{}, fn func() error, progress func(Progress),
+	where string, args ...interface{}) error {
+	return d.Table(getTableName(record)).SelectForEachRow(ctx, record, fn, progress, where, args...)
+}
+
+func (t *Tx) SelectForEachRow(ctx context.Context, record interface{}, fn func() error, progress func(Progress),
+	where string, args ...interface{}) error {
+	return t.Table(getTableName(record)).SelectForEachRow(ctx, record, fn, progress, where, args...)
+}
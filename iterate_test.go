@@ -0,0 +1,85 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTable_Iterate(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	columns := []string{"id", "name"}
+	f.On("FETCH").Returns(columns, []interface{}{1, "a"}, []interface{}{2, "b"}).Once()
+	f.On("FETCH").Returns(columns, []interface{}{3, "c"}).Once()
+
+	var item fakeItem
+	var names []string
+	err := db.Table("items").FetchSize(2).Iterate(&item, func() error {
+		names = append(names, item.Name)
+		return nil
+	}, "id > ?", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(names, []string{"a", "b", "c"}) {
+		t.Error(names)
+	}
+
+	var queries []string
+	for _, s := range f.Statements() {
+		queries = append(queries, strings.Split(s.Query, " gosql_cursor_")[0])
+	}
+	expected := []string{"BEGIN", "DECLARE", "FETCH FORWARD 2 FROM", "FETCH FORWARD 2 FROM", "CLOSE", "COMMIT"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Error(queries)
+	}
+
+	if s := f.Statements()[1]; !strings.HasSuffix(s.Query, " NO SCROLL CURSOR FOR SELECT id, name FROM items WHERE id > $1") {
+		t.Error(s.Query)
+	}
+
+	db, f = gosqltest.New("mysql")
+	f.On("SELECT").Returns(columns, []interface{}{1, "a"}, []interface{}{2, "b"})
+	n := 0
+	err = db.Table("items").Iterate(&item, func() error {
+		n++
+		return io.EOF
+	}, "")
+	if err != io.EOF || n != 1 {
+		t.Error(err, n)
+	}
+}
+
+func TestTable_SelectForEachRow(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	columns := []string{"id", "name"}
+	f.On("SELECT").Returns(columns, []interface{}{1, "a"}, []interface{}{2, "b"}, []interface{}{3, "c"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var item fakeItem
+	var names []string
+	var rows []int64
+	err := db.Table("items").SelectForEachRow(ctx, &item, func() error {
+		names = append(names, item.Name)
+		return nil
+	}, func(p sql.Progress) {
+		rows = append(rows, p.Rows)
+		if p.Rows == 2 {
+			cancel()
+		}
+	}, "id > ?", 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+
+	if !reflect.DeepEqual(names, []string{"a", "b"}) || !reflect.DeepEqual(rows, []int64{1, 2}) {
+		t.Error(names, rows)
+	}
+}
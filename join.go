@@ -0,0 +1,180 @@
+package sql
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var _typeToJoinColumnInfo = &sync.Map{} //type:*columnInfo
+
+// Join appends INNER JOIN clause, e.g. db.Table("orders o").Join("users u ON u.id = o.user_id").
+// Rows of joined tables are scanned into composite structs, see getJoinColumnInfo
+func (t *Table) Join(clause string) *Table {
+	return t.appendJoin("JOIN " + clause)
+}
+
+// LeftJoin appends LEFT JOIN clause
+func (t *Table) LeftJoin(clause string) *Table {
+	return t.appendJoin("LEFT JOIN " + clause)
+}
+
+func (t *Table) appendJoin(clause string) *Table {
+	c := *t
+	c.joins = append(append([]string(nil), t.joins...), clause)
+	return &c
+}
+
+// As returns a copy of t aliased as alias, whose selected columns are prefixed with alias, e.g.
+// db.Table("users").As("u") selects u.id, u.name FROM users u
+func (t *Table) As(alias string) *Table {
+	if !isPlainIdent(alias) {
+		panic("invalid alias: " + alias)
+	}
+
+	c := *t
+	if t.source != nil {
+		c.name = alias
+	} else {
+		c.name = strings.Fields(t.name)[0] + " " + alias
+	}
+	c.qualified = true
+	return &c
+}
+
+// qualifyColumns returns columns prefixed with alias of t, expressions and qualified columns are kept as they are
+func (t *Table) qualifyColumns(columns []string) []string {
+	alias := t.hintTarget()
+	l := make([]string, len(columns))
+	for i, c := range columns {
+		if strings.ContainsAny(c, ".( ") {
+			l[i] = c
+		} else {
+			l[i] = alias + "." + c
+		}
+	}
+	return l
+}
+
+// from returns quoted table name followed by its alias if declared, e.g. orders o
+func (t *Table) from() string {
+	if t.source != nil {
+		return "(" + t.source.SQL + ") " + t.quote(t.name)
+	}
+
+	fields := strings.Fields(t.name)
+	switch {
+	case len(fields) == 2:
+		return t.quote(fields[0]) + t.systemTime() + " " + t.quote(fields[1])
+	case len(fields) == 3 && strings.ToLower(fields[1]) == "as":
+		return t.quote(fields[0]) + t.systemTime() + " AS " + t.quote(fields[2])
+	default:
+		return t.quote(t.name) + t.systemTime()
+	}
+}
+
+// fromArgs prepends args of common tables, and args of source or AsOf to args
+func (t *Table) fromArgs(args []interface{}) []interface{} {
+	var prefix []interface{}
+	for _, c := range t.ctes {
+		prefix = append(prefix, c.sub.Args...)
+	}
+
+	if t.asOf != nil {
+		prefix = append(prefix, *t.asOf)
+	} else if t.source != nil {
+		prefix = append(prefix, t.source.Args...)
+	}
+
+	if len(prefix) == 0 {
+		return args
+	}
+	return append(prefix, args...)
+}
+
+// getSelectColumnInfo returns column info of struct type selected by t
+func (t *Table) getSelectColumnInfo(typ reflect.Type) *columnInfo {
+	if len(t.joins) > 0 {
+		return getJoinColumnInfo(typ)
+	}
+	return getColumnInfo(typ)
+}
+
+// getJoinColumnInfo returns column info of a composite struct, whose struct fields are scanned from joined tables, e.g.
+//
+//	type OrderUser struct {
+//		Order *Order `sql:"o"`
+//		User  *User  `sql:"u"`
+//	}
+//
+// Columns of a field are qualified by the table alias in its tag, or by its table name if there's no tag
+func getJoinColumnInfo(typ reflect.Type) *columnInfo {
+	if fi, ok := _typeToJoinColumnInfo.Load(typ); ok {
+		return fi.(*columnInfo)
+	}
+
+	info := &columnInfo{
+		nameToIndex: make(map[string]fieldIndex),
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := strings.TrimSpace(f.Tag.Get("sql"))
+		if len(f.PkgPath) > 0 || tag == "-" {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() != reflect.Struct {
+			panic("field of joined row must be a struct: " + f.Name)
+		}
+
+		qualifier := tag
+		if len(qualifier) == 0 {
+			qualifier = getTableNameByType(ft)
+		}
+
+		sub := getColumnInfo(ft)
+		for j, name := range sub.names {
+			idx := append(fieldIndex{i}, sub.indexes[j]...)
+			qualified := qualifier + "." + name
+			info.indexes = append(info.indexes, idx)
+			info.names = append(info.names, qualified)
+			info.nameToIndex[qualified] = idx
+		}
+
+		for _, name := range sub.jsonNames {
+			info.jsonNames = append(info.jsonNames, qualifier+"."+name)
+		}
+
+		for _, name := range sub.nullableNames {
+			info.nullableNames = append(info.nullableNames, qualifier+"."+name)
+		}
+
+		for _, name := range sub.encryptedNames {
+			info.encryptedNames = append(info.encryptedNames, qualifier+"."+name)
+		}
+
+		for _, name := range sub.arrayNames {
+			info.arrayNames = append(info.arrayNames, qualifier+"."+name)
+		}
+
+		for name, allowed := range sub.enums {
+			if info.enums == nil {
+				info.enums = make(map[string][]string)
+			}
+			info.enums[qualifier+"."+name] = allowed
+		}
+
+		// joined rows are scoped by tenant of the first tagged struct, others are expected to be joined on it
+		if len(info.tenantName) == 0 && len(sub.tenantName) > 0 {
+			info.tenantName = qualifier + "." + sub.tenantName
+		}
+	}
+
+	_typeToJoinColumnInfo.Store(typ, info)
+	return info
+}
@@ -0,0 +1,67 @@
+package sql
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+type joinUser struct {
+	ID   int64 `sql:"primary key"`
+	Name string
+}
+
+type joinOrder struct {
+	ID     int64 `sql:"primary key"`
+	UserID int64
+	Note   string `sql:"nullable"`
+}
+
+type joinOrderUser struct {
+	Order joinOrder `sql:"o"`
+	User  *joinUser `sql:"u"`
+}
+
+type fakeRow []interface{}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r[i]))
+	}
+	return nil
+}
+
+func TestTable_Join(t *testing.T) {
+	tbl := &Table{driverName: "mysql", name: "orders o", opts: &options{}}
+	c := tbl.Join("join_users u ON u.id = o.user_id")
+	info := c.getSelectColumnInfo(reflect.TypeOf(joinOrderUser{}))
+	query, _ := c.buildSelectQuery(info, "u.name = ?", []interface{}{"Tom"})
+	expected := "SELECT o.id, o.user_id, o.note, u.id, u.name FROM orders o JOIN join_users u ON u.id = o.user_id WHERE u.name = ?"
+	if query != expected {
+		t.Error(query)
+	}
+
+	var v joinOrderUser
+	row := fakeRow{int64(1), int64(2), sql.NullString{String: "fast", Valid: true}, int64(2), "Tom"}
+	if err := scanStruct(row, reflect.ValueOf(&v).Elem(), info, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Order.ID != 1 || v.Order.Note != "fast" || v.User == nil || v.User.Name != "Tom" {
+		t.Error(v)
+	}
+}
+
+func TestTable_As(t *testing.T) {
+	tbl := (&Table{driverName: "mysql", name: "join_users", opts: &options{}}).As("u")
+	info := tbl.getSelectColumnInfo(reflect.TypeOf(joinUser{}))
+	query, _ := tbl.buildSelectQuery(info, "u.name = ?", []interface{}{"Tom"})
+	if query != "SELECT u.id, u.name FROM join_users u WHERE u.name = ?" {
+		t.Error(query)
+	}
+
+	c := tbl.As("v").Join("orders o ON o.user_id = v.id")
+	if c.from() != "join_users v" || len(c.joins) != 1 {
+		t.Error(c.from())
+	}
+}
@@ -0,0 +1,120 @@
+package sql
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// target returns the alias of t if there is one, otherwise its quoted name
+func (t *Table) target() string {
+	fields := strings.Fields(t.name)
+	if len(fields) > 1 {
+		return t.quote(fields[len(fields)-1])
+	}
+	return t.quote(t.name)
+}
+
+// innerJoins splits joins into tables and ON conditions, for USING and FROM clauses of postgres
+func (t *Table) innerJoins() (tables, conds []string) {
+	for _, j := range t.joins {
+		if !strings.HasPrefix(j, "JOIN ") {
+			panic(fmt.Sprintf("%s doesn't support %s in DELETE or UPDATE", t.driverName, j))
+		}
+		clause := j[len("JOIN "):]
+		i := strings.Index(strings.ToUpper(clause), " ON ")
+		if i < 0 {
+			panic("missing ON condition: " + j)
+		}
+		tables = append(tables, strings.TrimSpace(clause[:i]))
+		conds = append(conds, "("+strings.TrimSpace(clause[i+len(" ON "):])+")")
+	}
+	return tables, conds
+}
+
+func (t *Table) writeJoinWhere(buf *bytes.Buffer, conds []string, where string) {
+	buf.WriteString(" WHERE ")
+	buf.WriteString(strings.Join(conds, " AND "))
+	if len(where) > 0 {
+		buf.WriteString(" AND (")
+		buf.WriteString(where)
+		buf.WriteString(")")
+	}
+}
+
+// prepareJoinDeleteQuery builds multi-table DELETE, e.g.
+// mysql: DELETE o FROM orders o JOIN users u ON u.id = o.user_id WHERE u.banned = ?
+// postgres: DELETE FROM orders o USING users u WHERE (u.id = o.user_id) AND (u.banned = ?)
+func (t *Table) prepareJoinDeleteQuery(where string) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	switch {
+	case t.driverName == "mysql" || t.driverName == "sqlserver" || t.driverName == "mssql":
+		buf.WriteString("DELETE ")
+		buf.WriteString(t.target())
+		buf.WriteString(" FROM ")
+		buf.WriteString(t.from())
+		for _, j := range t.joins {
+			buf.WriteString(" ")
+			buf.WriteString(j)
+		}
+		if len(where) > 0 {
+			buf.WriteString(" WHERE ")
+			buf.WriteString(where)
+		}
+	case isPostgres(t.driverName):
+		tables, conds := t.innerJoins()
+		buf.WriteString("DELETE FROM ")
+		buf.WriteString(t.from())
+		buf.WriteString(" USING ")
+		buf.WriteString(strings.Join(tables, ", "))
+		t.writeJoinWhere(buf, conds, where)
+	default:
+		panic(t.driverName + " doesn't support DELETE with JOIN")
+	}
+	return buf.String()
+}
+
+// writeJoinUpdate writes multi-table UPDATE around SET clause, e.g.
+// mysql: UPDATE orders o JOIN users u ON u.id = o.user_id SET ... WHERE u.banned = ?
+// postgres: UPDATE orders o SET ... FROM users u WHERE (u.id = o.user_id) AND (u.banned = ?)
+func (t *Table) writeJoinUpdate(buf *bytes.Buffer, set func(), where string) {
+	switch {
+	case t.driverName == "mysql":
+		buf.WriteString("UPDATE ")
+		buf.WriteString(t.from())
+		for _, j := range t.joins {
+			buf.WriteString(" ")
+			buf.WriteString(j)
+		}
+		set()
+		if len(where) > 0 {
+			buf.WriteString(" WHERE ")
+			buf.WriteString(where)
+		}
+	case t.driverName == "sqlserver" || t.driverName == "mssql":
+		buf.WriteString("UPDATE ")
+		buf.WriteString(t.target())
+		set()
+		buf.WriteString(" FROM ")
+		buf.WriteString(t.from())
+		for _, j := range t.joins {
+			buf.WriteString(" ")
+			buf.WriteString(j)
+		}
+		if len(where) > 0 {
+			buf.WriteString(" WHERE ")
+			buf.WriteString(where)
+		}
+	case isPostgres(t.driverName) || t.driverName == "sqlite3":
+		tables, conds := t.innerJoins()
+		buf.WriteString("UPDATE ")
+		buf.WriteString(t.from())
+		set()
+		buf.WriteString(" FROM ")
+		buf.WriteString(strings.Join(tables, ", "))
+		t.writeJoinWhere(buf, conds, where)
+	default:
+		panic(t.driverName + " doesn't support UPDATE with JOIN")
+	}
+}
@@ -0,0 +1,110 @@
+package sql
+
+import (
+	"github.com/gopub/log"
+	"strings"
+)
+
+// TruncateOptions are options of Truncate
+type TruncateOptions struct {
+	// RestartIdentity resets auto increment counters. It's implied by mysql and sqlserver
+	RestartIdentity bool
+
+	// Cascade also truncates tables referencing this table. Only postgres supports it
+	Cascade bool
+}
+
+// Truncate removes all rows of the table, e.g. to reset fixtures in tests
+func (t *Table) Truncate(opts *TruncateOptions) error {
+	if opts == nil {
+		opts = &TruncateOptions{}
+	}
+
+	if err := t.checkScopedWrite(); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	for _, query := range t.truncateQueries(opts) {
+		log.Debug(query)
+		if _, err := t.exec(query); err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Table) truncateQueries(opts *TruncateOptions) []string {
+	if opts.Cascade && !isPostgres(t.driverName) {
+		panic(t.driverName + " doesn't support TRUNCATE CASCADE")
+	}
+
+	name := t.quote(t.name)
+	switch {
+	case isPostgres(t.driverName):
+		query := "TRUNCATE TABLE " + name
+		if opts.RestartIdentity {
+			query += " RESTART IDENTITY"
+		}
+		if opts.Cascade {
+			query += " CASCADE"
+		}
+		return []string{query}
+	case t.driverName == "sqlite3":
+		queries := []string{"DELETE FROM " + name}
+		if opts.RestartIdentity {
+			queries = append(queries, "DELETE FROM sqlite_sequence WHERE name = '"+strings.ReplaceAll(t.name, "'", "''")+"'")
+		}
+		return queries
+	default:
+		return []string{"TRUNCATE TABLE " + name}
+	}
+}
+
+// Analyze updates statistics of the table for query planner
+func (t *Table) Analyze() error {
+	return t.maintain(t.analyzeQuery())
+}
+
+// Optimize reclaims storage and defragments the table. Postgres runs VACUUM ANALYZE, which can't run in a transaction
+func (t *Table) Optimize() error {
+	return t.maintain(t.optimizeQuery())
+}
+
+func (t *Table) maintain(query string) error {
+	log.Debug(query)
+	_, err := t.exec(query)
+	if err != nil {
+		log.Error(err)
+	}
+	return err
+}
+
+func (t *Table) analyzeQuery() string {
+	name := t.quote(t.name)
+	switch t.driverName {
+	case "mysql":
+		return "ANALYZE TABLE " + name
+	case "sqlserver", "mssql":
+		return "UPDATE STATISTICS " + name
+	default:
+		return "ANALYZE " + name
+	}
+}
+
+func (t *Table) optimizeQuery() string {
+	name := t.quote(t.name)
+	switch {
+	case t.driverName == "mysql":
+		return "OPTIMIZE TABLE " + name
+	case isPostgres(t.driverName):
+		return "VACUUM ANALYZE " + name
+	case t.driverName == "sqlserver" || t.driverName == "mssql":
+		return "ALTER INDEX ALL ON " + name + " REBUILD"
+	case t.driverName == "sqlite3":
+		return "VACUUM"
+	default:
+		panic(t.driverName + " doesn't support Optimize")
+	}
+}
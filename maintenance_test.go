@@ -0,0 +1,49 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestTable_Truncate(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	if err := db.Table("users").Truncate(&sql.TruncateOptions{RestartIdentity: true, Cascade: true}); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Query != "TRUNCATE TABLE users RESTART IDENTITY CASCADE" {
+		t.Error(s.Query)
+	}
+
+	db, f = gosqltest.New("pgx")
+	if err := db.Table("users").Truncate(&sql.TruncateOptions{Cascade: true}); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Query != "TRUNCATE TABLE users CASCADE" {
+		t.Error(s.Query)
+	}
+	if err := db.Table("users").Optimize(); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Query != "VACUUM ANALYZE users" {
+		t.Error(s.Query)
+	}
+
+	db, f = gosqltest.New("sqlite3")
+	if err := db.Table("users").Truncate(&sql.TruncateOptions{RestartIdentity: true}); err != nil {
+		t.Fatal(err)
+	}
+	statements := f.Statements()
+	if len(statements) != 2 || statements[0].Query != "DELETE FROM users" ||
+		statements[1].Query != "DELETE FROM sqlite_sequence WHERE name = 'users'" {
+		t.Error(statements)
+	}
+
+	db, f = gosqltest.New("mysql")
+	if err := db.Table("users").Optimize(); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Query != "OPTIMIZE TABLE users" {
+		t.Error(s.Query)
+	}
+}
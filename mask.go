@@ -0,0 +1,62 @@
+package sql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+const redacted = "[REDACTED]"
+
+// SensitiveValue is bound as its value, but is redacted in logs and interpolated statements.
+// Values of columns tagged by sensitive are bound as SensitiveValue, e.g.
+//
+//	type User struct {
+//		ID       int64
+//		Password string `sql:"sensitive"`
+//	}
+type SensitiveValue struct {
+	v interface{}
+}
+
+var _ driver.Valuer = SensitiveValue{}
+
+// Sensitive returns v as SensitiveValue, e.g. Select(&users, "token = ?", Sensitive(token))
+func Sensitive(v interface{}) SensitiveValue {
+	return SensitiveValue{v: v}
+}
+
+func (s SensitiveValue) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(s.v)
+}
+
+func (s SensitiveValue) String() string {
+	return redacted
+}
+
+func (s SensitiveValue) GoString() string {
+	return redacted
+}
+
+func (s SensitiveValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// Redact returns args with sensitive values replaced by [REDACTED], e.g. for logging in middleware
+func Redact(args []interface{}) []interface{} {
+	var result []interface{}
+	for i, a := range args {
+		if _, ok := a.(SensitiveValue); !ok {
+			continue
+		}
+
+		if result == nil {
+			result = append([]interface{}(nil), args...)
+		}
+		result[i] = redacted
+	}
+
+	if result == nil {
+		return args
+	}
+	return result
+}
@@ -0,0 +1,19 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+func TestSensitive_driver(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	if _, err := db.Exec("UPDATE users SET password = ? WHERE id = ?", sql.Sensitive("secret"), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); !reflect.DeepEqual(s.Args, []interface{}{"secret", int64(1)}) {
+		t.Error(s.Args)
+	}
+}
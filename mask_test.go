@@ -0,0 +1,36 @@
+package sql
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSensitive(t *testing.T) {
+	type user struct {
+		ID       int64  `sql:"primary key"`
+		Password string `sql:"sensitive"`
+	}
+
+	tbl := &Table{driverName: "mysql", name: "user", opts: &options{}}
+	query, args, err := tbl.prepareInsertQuery(&user{ID: 1, Password: "it's"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := interpolate("mysql", query, args); got != "INSERT INTO `user`(id, password) VALUES (1, '[REDACTED]')" {
+		t.Error(got)
+	}
+
+	if got := Redact(args); !reflect.DeepEqual(got, []interface{}{int64(1), "[REDACTED]"}) {
+		t.Error(got)
+	}
+
+	if v, err := args[1].(SensitiveValue).Value(); err != nil || v != "it's" {
+		t.Error(v, err)
+	}
+
+	if data, err := json.Marshal(args); err != nil || string(data) != `[1,"[REDACTED]"]` {
+		t.Error(string(data), err)
+	}
+}
@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// MaxExecutionTime returns a copy of t whose statements are aborted by database server after d, which complements
+// context cancellation for servers that keep executing after client disconnects. It renders MAX_EXECUTION_TIME hint
+// of SELECT statements in mysql, and SET LOCAL statement_timeout before statements in postgres, which run in
+// a transaction if t isn't in one. The transaction is begun on primary, so reads of postgres are routed to primary
+// instead of replicas. The timeout of postgres remains for later statements of the transaction
+func (t *Table) MaxExecutionTime(d time.Duration) *Table {
+	c := *t
+	c.maxExecutionTime = d
+	return &c
+}
+
+// maxExecutionMillis returns milliseconds of max execution time, or 0 if it isn't set
+func (t *Table) maxExecutionMillis() int64 {
+	if t.maxExecutionTime <= 0 {
+		return 0
+	}
+
+	if ms := t.maxExecutionTime.Milliseconds(); ms > 0 {
+		return ms
+	}
+	return 1
+}
+
+// maxExecutionHint returns optimizer hint of max execution time of mysql
+func (t *Table) maxExecutionHint() string {
+	return "MAX_EXECUTION_TIME(" + strconv.FormatInt(t.maxExecutionMillis(), 10) + ")"
+}
+
+// needsTimeoutTx reports whether statements of t must run in a transaction to set statement_timeout of postgres
+func (t *Table) needsTimeoutTx() bool {
+	return isPostgres(t.driverName) && t.maxExecutionMillis() > 0 && t.tx == nil
+}
+
+// setStatementTimeout sets statement_timeout of transaction of t in postgres
+func (t *Table) setStatementTimeout(ctx context.Context) error {
+	ms := t.maxExecutionMillis()
+	if !isPostgres(t.driverName) || ms == 0 || t.tx == nil {
+		return nil
+	}
+
+	query := "SET LOCAL statement_timeout = " + strconv.FormatInt(ms, 10)
+	t.logQuery(query, nil)
+	_, err := t.exe.ExecContext(ctx, query)
+	return t.queryError(query, nil, err)
+}
@@ -0,0 +1,61 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTable_MaxExecutionTime(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	var items []*fakeItem
+	if err := db.Table("items").MaxExecutionTime(1500*time.Millisecond).Select(&items, "id > ?", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var queries []string
+	for _, s := range f.Statements() {
+		queries = append(queries, s.Query)
+	}
+	expected := []string{"BEGIN", "SET LOCAL statement_timeout = 1500", "SELECT id, name FROM items WHERE id > $1", "COMMIT"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Error(queries)
+	}
+
+	db, f = gosqltest.New("mysql")
+	if err := db.Table("items").MaxExecutionTime(time.Second).Select(&items, ""); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.Statements()[0]; !strings.HasPrefix(s.Query, "SELECT /*+ MAX_EXECUTION_TIME(1000) */ id, name FROM items") {
+		t.Error(s.Query)
+	}
+
+	if err := db.Table("items").MaxExecutionTime(time.Second).Delete("id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.Statements()[1]; strings.Contains(s.Query, "MAX_EXECUTION_TIME") {
+		t.Error(s.Query)
+	}
+}
+
+func TestTable_MaxExecutionTime_panic(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a"})
+	var item fakeItem
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("no panic")
+			}
+		}()
+		db.Table("items").MaxExecutionTime(time.Second).Iterate(&item, func() error {
+			panic("bad usage")
+		}, "")
+	}()
+
+	if s := f.LastStatement(); s.Query != "ROLLBACK" {
+		t.Error(s.Query)
+	}
+}
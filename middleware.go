@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Middleware wraps the next executor to inspect or modify statements and their execution,
+// e.g. query hints, comments, tenant filters or audit
+type Middleware func(next Executor) Executor
+
+// Use appends middleware to the chain which statements of d and its Tx and Table pass through.
+// The first one is the outermost. It must be called before d is used. Args passed to middlewares aren't reused
+// by the package, so middlewares can keep them
+func (d *DB) Use(mw ...Middleware) {
+	// full slice expression copies middlewares, which may be shared by DB of Tx.DB and DryRun
+	d.opts.middlewares = append(d.opts.middlewares[:len(d.opts.middlewares):len(d.opts.middlewares)], mw...)
+}
+
+// wrap returns exe wrapped by middlewares
+func (o *options) wrap(exe Executor) Executor {
+	if o.bindArgs != nil {
+		exe = &binder{next: exe, bind: o.bindArgs}
+	}
+	for i := len(o.middlewares) - 1; i >= 0; i-- {
+		exe = o.middlewares[i](exe)
+		if a, ok := exe.(*auditor); ok {
+			a.keys = o.keys
+		}
+	}
+	return exe
+}
+
+// Rewrite returns middleware which rewrites query and args of every statement by fn
+func Rewrite(fn func(ctx context.Context, query string, args []interface{}) (string, []interface{})) Middleware {
+	return func(next Executor) Executor {
+		return &rewriter{next: next, fn: fn}
+	}
+}
+
+type rewriter struct {
+	next Executor
+	fn   func(ctx context.Context, query string, args []interface{}) (string, []interface{})
+}
+
+func (r *rewriter) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args = r.fn(ctx, query, args)
+	return r.next.ExecContext(ctx, query, args...)
+}
+
+func (r *rewriter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	query, args = r.fn(ctx, query, args)
+	return r.next.QueryContext(ctx, query, args...)
+}
+
+func (r *rewriter) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args = r.fn(ctx, query, args)
+	return r.next.QueryRowContext(ctx, query, args...)
+}
+
+// argBinder returns function which binds args of types unsupported by driver, e.g. UUID as 16 bytes and geometries
+// as internal format in mysql, and slices as arrays in postgres. It returns nil if there's no such type
+func argBinder(driverName string) func(args []interface{}) []interface{} {
+	switch {
+	case driverName == "mysql":
+		return func(args []interface{}) []interface{} {
+			return bindGeometries(bindUUIDs(args))
+		}
+	case isBinaryUUID(driverName):
+		return bindUUIDs
+	case isPostgres(driverName):
+		return bindArrays
+	default:
+		return nil
+	}
+}
+
+// binder is the innermost executor binding args by bind, so middlewares see args as they're passed
+type binder struct {
+	next Executor
+	bind func(args []interface{}) []interface{}
+}
+
+func (b *binder) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return b.next.ExecContext(ctx, query, b.bind(args)...)
+}
+
+func (b *binder) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return b.next.QueryContext(ctx, query, b.bind(args)...)
+}
+
+func (b *binder) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return b.next.QueryRowContext(ctx, query, b.bind(args)...)
+}
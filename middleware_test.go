@@ -0,0 +1,88 @@
+package sql_test
+
+import (
+	"context"
+	stdsql "database/sql"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type countingExecutor struct {
+	sql.Executor
+	n *int
+}
+
+func (e *countingExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (stdsql.Result, error) {
+	*e.n++
+	return e.Executor.ExecContext(ctx, query, args...)
+}
+
+func TestDB_Use(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	n := 0
+	db.Use(func(next sql.Executor) sql.Executor {
+		return &countingExecutor{Executor: next, n: &n}
+	}, sql.Rewrite(func(ctx context.Context, query string, args []interface{}) (string, []interface{}) {
+		return query + " /* app */", args
+	}))
+
+	if err := db.Insert(&fakeItem{ID: 1, Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var items []*fakeItem
+	if err := db.Select(&items, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tx.Table("items").Update(&fakeItem{ID: 1, Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = db.Exec("DELETE FROM items"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range f.Statements() {
+		if s.Query != "BEGIN" && s.Query != "COMMIT" && !strings.HasSuffix(s.Query, " /* app */") {
+			t.Error(s.Query)
+		}
+	}
+
+	if n != 3 {
+		t.Error(n)
+	}
+}
+
+func TestDB_Use_keepArgs(t *testing.T) {
+	db, _ := gosqltest.New("mysql")
+	var kept [][]interface{}
+	db.Use(sql.Rewrite(func(ctx context.Context, query string, args []interface{}) (string, []interface{}) {
+		kept = append(kept, args)
+		return query, args
+	}))
+
+	for i := 1; i <= 3; i++ {
+		if err := db.Insert(&fakeItem{ID: int64(i), Name: "a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, args := range kept {
+		if !reflect.DeepEqual(args, []interface{}{int64(i + 1), "a"}) {
+			t.Error(i, args)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package migrate applies versioned schema migrations, whose applied versions are recorded in table schema_migrations.
+// App instances don't migrate concurrently, as migrations run with an advisory lock of sql.DB.WithLock if it's supported.
+//
+// Each migration runs in a transaction. DDL statements commit implicitly in mysql, so a migration which fails after
+// a DDL statement isn't rolled back entirely, and its version isn't recorded. Keep one DDL statement per migration in mysql
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"github.com/gopub/log"
+	"github.com/gopub/sql"
+	"sort"
+	"time"
+)
+
+const tableName = "schema_migrations"
+
+// Migration is a versioned schema change.
+// Up and Down are sql statements, UpFunc and DownFunc are used instead if they are set.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	UpFunc   func(tx *sql.Tx) error
+	DownFunc func(tx *sql.Tx) error
+}
+
+type schemaMigration struct {
+	Version   int64 `sql:"primary key"`
+	Name      string
+	AppliedAt int64
+}
+
+// Migrator applies and reverts registered migrations of db
+type Migrator struct {
+	db         *sql.DB
+	migrations []*Migration
+}
+
+// New returns a migrator of db without migrations
+func New(db *sql.DB) *Migrator {
+	return &Migrator{db: db}
+}
+
+// Register registers migrations which will be applied by Up and To
+func (m *Migrator) Register(migrations ...*Migration) {
+	for _, mg := range migrations {
+		for _, v := range m.migrations {
+			if v.Version == mg.Version {
+				panic(fmt.Sprint("duplicate migration version: ", mg.Version))
+			}
+		}
+		m.migrations = append(m.migrations, mg)
+	}
+	sort.Slice(m.migrations, func(i, j int) bool {
+		return m.migrations[i].Version < m.migrations[j].Version
+	})
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up() error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.To(m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down reverts the latest applied migration
+func (m *Migrator) Down() error {
+	return m.withLock(func() error {
+		applied, err := m.applied()
+		if err != nil || len(applied) == 0 {
+			return err
+		}
+		return m.revert(applied[len(applied)-1].Version)
+	})
+}
+
+// To applies or reverts migrations until schema is at version
+func (m *Migrator) To(version int64) error {
+	return m.withLock(func() error {
+		applied, err := m.applied()
+		if err != nil {
+			return err
+		}
+
+		isApplied := make(map[int64]bool, len(applied))
+		for _, v := range applied {
+			isApplied[v.Version] = true
+		}
+
+		for i := len(applied) - 1; i >= 0 && applied[i].Version > version; i-- {
+			if err = m.revert(applied[i].Version); err != nil {
+				return err
+			}
+		}
+
+		for _, mg := range m.migrations {
+			if mg.Version > version {
+				break
+			}
+
+			if !isApplied[mg.Version] {
+				if err = m.apply(mg); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// applied returns applied migrations in order of version, which are read in a transaction on primary
+func (m *Migrator) applied() ([]*schemaMigration, error) {
+	if err := m.db.Table(tableName).CreateTable(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var applied []*schemaMigration
+	err = tx.Table(tableName).OrderBy("version").Select(&applied, "")
+	return applied, err
+}
+
+func (m *Migrator) apply(mg *Migration) error {
+	log.Info("Apply migration", mg.Version, mg.Name)
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if mg.UpFunc != nil {
+		err = mg.UpFunc(tx)
+	} else {
+		_, err = tx.Exec(mg.Up)
+	}
+
+	if err == nil {
+		err = tx.Table(tableName).Insert(&schemaMigration{
+			Version:   mg.Version,
+			Name:      mg.Name,
+			AppliedAt: time.Now().Unix(),
+		})
+	}
+
+	if err != nil {
+		log.Error(err)
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) revert(version int64) error {
+	var mg *Migration
+	for _, v := range m.migrations {
+		if v.Version == version {
+			mg = v
+			break
+		}
+	}
+
+	if mg == nil {
+		return fmt.Errorf("migration %d is not registered", version)
+	}
+
+	if mg.DownFunc == nil && len(mg.Down) == 0 {
+		return fmt.Errorf("migration %d is irreversible", version)
+	}
+
+	log.Info("Revert migration", mg.Version, mg.Name)
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if mg.DownFunc != nil {
+		err = mg.DownFunc(tx)
+	} else {
+		_, err = tx.Exec(mg.Down)
+	}
+
+	if err == nil {
+		err = tx.Table(tableName).Delete("version=?", mg.Version)
+	}
+
+	if err != nil {
+		log.Error(err)
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// withLock prevents app instances from migrating concurrently, if advisory locks are supported by the driver
+func (m *Migrator) withLock(fn func() error) error {
+	switch m.db.DriverName() {
+	case "mysql", "postgres", "pgx", "sqlserver", "mssql":
+		return m.db.WithLock(context.Background(), m.db.Table(tableName).Name(), func(ctx context.Context) error {
+			return fn()
+		})
+	default:
+		return fn()
+	}
+}
@@ -0,0 +1,110 @@
+package migrate_test
+
+import (
+	"errors"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"github.com/gopub/sql/migrate"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func queries(f *gosqltest.Fake) []string {
+	var l []string
+	for _, s := range f.Statements() {
+		l = append(l, s.Query)
+	}
+	return l
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db, f := gosqltest.New("sqlite3")
+	f.On("FROM schema_migrations").Returns([]string{"version", "name", "applied_at"}, []interface{}{1, "create products", 1})
+	m := migrate.New(db)
+	m.Register(&migrate.Migration{
+		Version: 2,
+		Name:    "fill products",
+		UpFunc: func(tx *sql.Tx) error {
+			_, err := tx.Exec("INSERT INTO products(name) VALUES ('apple')")
+			return err
+		},
+	}, &migrate.Migration{
+		Version: 1,
+		Name:    "create products",
+		Up:      "CREATE TABLE products(id INTEGER PRIMARY KEY, name TEXT)",
+	})
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	l := queries(f)
+	if len(l) != 8 || !strings.HasPrefix(l[0], "CREATE TABLE IF NOT EXISTS schema_migrations") {
+		t.Fatal(l)
+	}
+
+	expected := []string{"BEGIN", "SELECT version, name, applied_at FROM schema_migrations ORDER BY version", "ROLLBACK",
+		"BEGIN", "INSERT INTO products(name) VALUES ('apple')", "INSERT INTO schema_migrations(version, name, applied_at) VALUES (?, ?, ?)", "COMMIT"}
+	if !reflect.DeepEqual(l[1:], expected) {
+		t.Error(l[1:])
+	}
+
+	if s := f.LastStatement(); s.Query != "COMMIT" {
+		t.Error(s.Query)
+	}
+}
+
+func TestMigrator_Up_error(t *testing.T) {
+	db, f := gosqltest.New("sqlite3")
+	f.On("CREATE TABLE products").Fails(errors.New("syntax error"))
+	m := migrate.New(db)
+	m.Register(&migrate.Migration{Version: 1, Up: "CREATE TABLE products"})
+	if err := m.Up(); err == nil {
+		t.Fatal("no error")
+	}
+
+	for _, s := range f.Statements() {
+		if strings.HasPrefix(s.Query, "INSERT") {
+			t.Error("failed migration is recorded")
+		}
+	}
+
+	if s := f.LastStatement(); s.Query != "ROLLBACK" {
+		t.Error(s.Query)
+	}
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("GET_LOCK").Returns([]string{"locked"}, []interface{}{1})
+	f.On("FROM schema_migrations").Returns([]string{"version", "name", "applied_at"},
+		[]interface{}{1, "create products", 1}, []interface{}{2, "fill products", 1})
+	m := migrate.New(db)
+	m.Register(&migrate.Migration{Version: 1, Down: "DROP TABLE products"}, &migrate.Migration{Version: 2})
+	if err := m.Down(); err == nil || !strings.Contains(err.Error(), "irreversible") {
+		t.Fatal(err)
+	}
+
+	f.Reset()
+	f.On("GET_LOCK").Returns([]string{"locked"}, []interface{}{1})
+	f.On("FROM schema_migrations").Returns([]string{"version", "name", "applied_at"}, []interface{}{1, "create products", 1})
+	if err := m.To(0); err != nil {
+		t.Fatal(err)
+	}
+
+	l := queries(f)
+	expected := []string{"BEGIN", "DROP TABLE products", "DELETE FROM schema_migrations WHERE version=?", "COMMIT", "DO RELEASE_LOCK(?)"}
+	if len(l) < len(expected) || l[0] != "SELECT GET_LOCK(?, -1)" || !reflect.DeepEqual(l[len(l)-len(expected):], expected) {
+		t.Error(l)
+	}
+}
+
+func TestMigrator_Register(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate version isn't rejected")
+		}
+	}()
+	db, _ := gosqltest.New("sqlite3")
+	migrate.New(db).Register(&migrate.Migration{Version: 1}, &migrate.Migration{Version: 1})
+}
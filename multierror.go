@@ -0,0 +1,108 @@
+package sql
+
+import (
+	"fmt"
+	"github.com/gopub/log"
+)
+
+// RecordError is the error of a record in MultiInsert, MultiUpdate, MultiSave or MultiDelete
+type RecordError struct {
+	// Index is index of Record in values
+	Index  int
+	Record interface{}
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Index, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError reports failed records of MultiInsert, MultiUpdate, MultiSave or MultiDelete.
+// Errors are in order of records, and errors.Is and errors.As match the first one
+type MultiError struct {
+	// Total is the number of records
+	Total  int
+	Errors []*RecordError
+}
+
+func (e *MultiError) Error() string {
+	return fmt.Sprintf("%d of %d records failed, %v", len(e.Errors), e.Total, e.Errors[0])
+}
+
+func (e *MultiError) Unwrap() error {
+	return e.Errors[0]
+}
+
+// Records returns failed records
+func (e *MultiError) Records() []interface{} {
+	l := make([]interface{}, len(e.Errors))
+	for i, re := range e.Errors {
+		l[i] = re.Record
+	}
+	return l
+}
+
+// multiExec calls fn with values in a transaction, which is rolled back at the first failed record
+func (d *DB) multiExec(values []interface{}, fn func(tx *Tx, record interface{}) error) error {
+	tx, err := d.Begin()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	for i, v := range values {
+		if err = fn(tx, v); err != nil {
+			tx.Rollback()
+			return &MultiError{
+				Total:  len(values),
+				Errors: []*RecordError{{Index: i, Record: v, Err: err}},
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// ContinueOnError returns a writer which writes records one by one without transaction,
+// it continues after failed records and returns MultiError of all of them
+func (d *DB) ContinueOnError() *MultiWriter {
+	return &MultiWriter{db: d}
+}
+
+// MultiWriter writes records one by one without transaction, see DB.ContinueOnError
+type MultiWriter struct {
+	db *DB
+}
+
+func (w *MultiWriter) Insert(values ...interface{}) error {
+	return w.exec(values, w.db.Insert)
+}
+
+func (w *MultiWriter) Update(values ...interface{}) error {
+	return w.exec(values, w.db.Update)
+}
+
+func (w *MultiWriter) Save(values ...interface{}) error {
+	return w.exec(values, w.db.Save)
+}
+
+func (w *MultiWriter) Delete(values ...interface{}) error {
+	return w.exec(values, w.db.Delete)
+}
+
+func (w *MultiWriter) exec(values []interface{}, fn func(record interface{}) error) error {
+	var errs []*RecordError
+	for i, v := range values {
+		if err := fn(v); err != nil {
+			errs = append(errs, &RecordError{Index: i, Record: v, Err: err})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &MultiError{Total: len(values), Errors: errs}
+}
@@ -0,0 +1,80 @@
+package sql
+
+import (
+	"github.com/gopub/utils"
+	"github.com/jinzhu/inflection"
+	"sync/atomic"
+)
+
+// NamingStrategy maps names of struct types and fields to table and column names.
+// Table names declared by TableName method, and column names declared by tag are not mapped
+type NamingStrategy interface {
+	TableName(typeName string) string
+	ColumnName(fieldName string) string
+}
+
+// Naming is the default NamingStrategy, which maps names to snake case, and table names to plural, e.g. OrderItem
+// to order_items
+type Naming struct {
+	// TablePrefix is prepended to table names, e.g. app_
+	TablePrefix string
+
+	// SingularTable disables pluralization of table names
+	SingularTable bool
+
+	// NoSnakeCase keeps names as they're
+	NoSnakeCase bool
+}
+
+var _ NamingStrategy = Naming{}
+
+func (n Naming) TableName(typeName string) string {
+	name := n.ColumnName(typeName)
+	if !n.SingularTable {
+		name = inflection.Plural(name)
+	}
+	return n.TablePrefix + name
+}
+
+func (n Naming) ColumnName(fieldName string) string {
+	if n.NoSnakeCase {
+		return fieldName
+	}
+	return utils.CamelToSnake(fieldName)
+}
+
+// namingStrategy wraps NamingStrategy, as values stored in atomic.Value must be of the same type
+type namingStrategy struct {
+	NamingStrategy
+}
+
+// _naming holds namingStrategy set by SetNamingStrategy, otherwise Naming{} is used
+var _naming atomic.Value
+
+// _namingUsed is set once names are mapped, after which the strategy can't be changed
+var _namingUsed int32
+
+// naming returns the naming strategy, which is frozen by the first call
+func naming() NamingStrategy {
+	if atomic.LoadInt32(&_namingUsed) == 0 {
+		atomic.StoreInt32(&_namingUsed, 1)
+	}
+
+	if s, ok := _naming.Load().(namingStrategy); ok {
+		return s.NamingStrategy
+	}
+	return Naming{}
+}
+
+// SetNamingStrategy sets naming strategy of tables and columns. As column mappings of types are cached,
+// it must be called before any type is used, e.g. in init function, otherwise it panics
+func SetNamingStrategy(s NamingStrategy) {
+	if s == nil {
+		panic("naming strategy is nil")
+	}
+
+	if atomic.LoadInt32(&_namingUsed) != 0 {
+		panic("naming strategy is set after names are mapped")
+	}
+	_naming.Store(namingStrategy{s})
+}
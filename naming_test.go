@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNaming(t *testing.T) {
+	tests := []struct {
+		naming Naming
+		table  string
+		column string
+	}{
+		{Naming{}, "order_items", "unit_price"},
+		{Naming{TablePrefix: "app_", SingularTable: true}, "app_order_item", "unit_price"},
+		{Naming{NoSnakeCase: true, SingularTable: true}, "OrderItem", "UnitPrice"},
+	}
+	for _, test := range tests {
+		if name := test.naming.TableName("OrderItem"); name != test.table {
+			t.Error(name)
+		}
+
+		if name := test.naming.ColumnName("UnitPrice"); name != test.column {
+			t.Error(name)
+		}
+	}
+}
+
+func TestSetNamingStrategy(t *testing.T) {
+	type legacyOrder struct {
+		OrderID int64
+		Price   int `sql:"amount"`
+	}
+
+	// other tests have mapped names, which freezes the strategy
+	atomic.StoreInt32(&_namingUsed, 0)
+	SetNamingStrategy(Naming{TablePrefix: "t_", SingularTable: true, NoSnakeCase: true})
+	defer func() {
+		atomic.StoreInt32(&_namingUsed, 0)
+		SetNamingStrategy(Naming{})
+	}()
+
+	if name := getTableNameByType(reflect.TypeOf(legacyOrder{})); name != "t_legacyOrder" {
+		t.Error(name)
+	}
+
+	if info := getColumnInfo(reflect.TypeOf(legacyOrder{})); !reflect.DeepEqual(info.names, []string{"OrderID", "amount"}) {
+		t.Error(info.names)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic after names are mapped")
+		}
+	}()
+	SetNamingStrategy(Naming{})
+}
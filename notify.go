@@ -0,0 +1,287 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"github.com/gopub/log"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	notificationTable = "gosql_notifications"
+
+	// notificationDelay is how long a polled notification may be committed after it's sent, e.g. in a long Tx,
+	// as ids of notifications aren't committed in order
+	notificationDelay = time.Minute
+
+	// notificationPruneInterval is the min interval of deleting expired notifications
+	notificationPruneInterval = time.Minute
+)
+
+// Notification is a message sent to channel by Notify, or by NOTIFY of postgres, e.g. in triggers of row changes
+type Notification struct {
+	Channel string
+	Payload string
+}
+
+// WaitForNotification waits for a notification on driver connection of postgres, which is driver specific, e.g. for pgx:
+//
+//	sql.WaitForNotification = func(ctx context.Context, driverConn interface{}) (*sql.Notification, error) {
+//		n, err := driverConn.(*stdlib.Conn).Conn().WaitForNotification(ctx)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return &sql.Notification{Channel: n.Channel, Payload: n.Payload}, nil
+//	}
+//
+// Listen and Notify use LISTEN/NOTIFY of postgres if it's set, otherwise they fall back to polling table gosql_notifications
+var WaitForNotification func(ctx context.Context, driverConn interface{}) (*Notification, error)
+
+// notificationRecord is a row of table gosql_notifications, which is polled by Listen if LISTEN/NOTIFY isn't available
+type notificationRecord struct {
+	ID        int64  `sql:"primary key,auto_increment"`
+	Channel   string `sql:"index,size=255"`
+	Payload   string
+	CreatedAt int64
+}
+
+// SetNotificationPollInterval sets interval of polling notifications, default is 1 second
+func (d *DB) SetNotificationPollInterval(interval time.Duration) {
+	if interval <= 0 {
+		panic("interval must be positive")
+	}
+	d.opts.pollInterval = interval
+}
+
+// SetNotificationRetention sets how long polled notifications are kept in table gosql_notifications, default is 24 hours
+func (d *DB) SetNotificationRetention(retention time.Duration) {
+	if retention < 2*notificationDelay {
+		panic("retention must be at least 2 minutes")
+	}
+	d.opts.notificationRetention = retention
+}
+
+// Listen returns notifications sent to channel after it's called, until ctx is done.
+// LISTEN of postgres holds a connection of d until then, see WaitForNotification
+func (d *DB) Listen(ctx context.Context, channel string) (<-chan *Notification, error) {
+	if len(channel) == 0 {
+		panic("channel is empty")
+	}
+
+	if d.usesListen() {
+		return d.listen(ctx, channel)
+	}
+	return d.poll(ctx, channel)
+}
+
+// Notify sends payload to listeners of channel
+func (d *DB) Notify(ctx context.Context, channel, payload string) error {
+	if d.usesListen() {
+		_, err := d.opts.wrap(d.db).ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+		if err != nil {
+			log.Error(err)
+		}
+		return err
+	}
+
+	if err := d.createNotificationTable(ctx); err != nil {
+		return err
+	}
+	err := d.Table(notificationTable).WithContext(ctx).Insert(&notificationRecord{
+		Channel:   channel,
+		Payload:   payload,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	d.pruneNotifications(ctx)
+	return nil
+}
+
+// Notify sends payload to listeners of channel when t is committed. Table gosql_notifications must exist if
+// notifications are polled
+func (t *Tx) Notify(channel, payload string) error {
+	if isPostgres(t.driverName) && WaitForNotification != nil {
+		_, err := t.exe.ExecContext(t.Context(), "SELECT pg_notify($1, $2)", channel, payload)
+		if err != nil {
+			err = txError(t.Context(), err)
+			log.Error(err)
+		}
+		return err
+	}
+
+	// table isn't created in t, as DDL may commit t implicitly. It's created by DB.Listen and DB.Notify
+	return t.Table(notificationTable).Insert(&notificationRecord{
+		Channel:   channel,
+		Payload:   payload,
+		CreatedAt: time.Now().Unix(),
+	})
+}
+
+func (d *DB) usesListen() bool {
+	return isPostgres(d.driverName) && WaitForNotification != nil
+}
+
+func (d *DB) listen(ctx context.Context, channel string) (<-chan *Notification, error) {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	if _, err = conn.ExecContext(ctx, "LISTEN "+quoteChannel(channel)); err != nil {
+		log.Error(err)
+		conn.Close()
+		return nil, err
+	}
+
+	ch := make(chan *Notification, 16)
+	go func() {
+		defer close(ch)
+		defer conn.Close()
+		defer conn.ExecContext(context.Background(), "UNLISTEN "+quoteChannel(channel))
+		for {
+			var n *Notification
+			err := conn.Raw(func(driverConn interface{}) error {
+				var err error
+				n, err = WaitForNotification(ctx, driverConn)
+				return err
+			})
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error(err)
+				}
+				return
+			}
+
+			select {
+			case ch <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (d *DB) poll(ctx context.Context, channel string) (<-chan *Notification, error) {
+	if err := d.createNotificationTable(ctx); err != nil {
+		return nil, err
+	}
+
+	table := d.Table(notificationTable).WithContext(ctx).OrderBy("id DESC")
+	var last notificationRecord
+	if err := table.SelectOne(&last, "channel = ?", channel); err != nil && !errors.Is(err, ErrNoRows) {
+		return nil, err
+	}
+
+	interval := d.opts.pollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ch := make(chan *Notification, 16)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		table = table.OrderBy("id").Limit(100)
+		// notifications after minID are polled again until they're older than notificationDelay,
+		// so that notifications committed out of order of ids aren't skipped
+		minID := last.ID
+		polled := map[int64]int64{} // id:created_at of notifications after minID
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for id := minID; ; {
+				var records []*notificationRecord
+				if err := table.Select(&records, "channel = ? AND id > ?", channel, id); err != nil {
+					// retry at next tick
+					break
+				}
+
+				for _, r := range records {
+					id = r.ID
+					if _, ok := polled[r.ID]; ok {
+						continue
+					}
+					select {
+					case ch <- &Notification{Channel: r.Channel, Payload: r.Payload}:
+						polled[r.ID] = r.CreatedAt
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				if len(records) < 100 {
+					break
+				}
+			}
+
+			minID = advanceNotificationID(minID, polled, time.Now().Add(-notificationDelay).Unix())
+			d.pruneNotifications(ctx)
+		}
+	}()
+	return ch, nil
+}
+
+// advanceNotificationID removes polled notifications before deadline in order of ids, and returns the last removed id
+func advanceNotificationID(minID int64, polled map[int64]int64, deadline int64) int64 {
+	ids := make([]int64, 0, len(polled))
+	for id := range polled {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		if polled[id] >= deadline {
+			break
+		}
+		minID = id
+		delete(polled, id)
+	}
+	return minID
+}
+
+// pruneNotifications deletes notifications older than retention, at most once per notificationPruneInterval
+func (d *DB) pruneNotifications(ctx context.Context) {
+	now := time.Now()
+	last := atomic.LoadInt64(&d.notificationsPrunedAt)
+	if now.UnixNano()-last < int64(notificationPruneInterval) ||
+		!atomic.CompareAndSwapInt64(&d.notificationsPrunedAt, last, now.UnixNano()) {
+		return
+	}
+
+	retention := d.opts.notificationRetention
+	if retention <= 0 {
+		retention = 24 * time.Hour
+	}
+	// error is logged by Delete, and expired notifications are deleted next time
+	_ = d.Table(notificationTable).WithContext(ctx).Delete("created_at < ?", now.Add(-retention).Unix())
+}
+
+// quoteChannel quotes channel of LISTEN/UNLISTEN, as it's an identifier which may be a keyword or contain any characters
+func quoteChannel(channel string) string {
+	return `"` + strings.ReplaceAll(channel, `"`, `""`) + `"`
+}
+
+func (d *DB) createNotificationTable(ctx context.Context) error {
+	if atomic.LoadInt32(&d.notificationTableCreated) == 1 {
+		return nil
+	}
+
+	if err := d.Table(notificationTable).WithContext(ctx).CreateTable(&notificationRecord{}); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&d.notificationTableCreated, 1)
+	return nil
+}
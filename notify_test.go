@@ -0,0 +1,155 @@
+package sql_test
+
+import (
+	"context"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDB_Listen(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetNotificationPollInterval(time.Millisecond)
+	columns := []string{"id", "channel", "payload", "created_at"}
+	f.On("ORDER BY id DESC").Returns(columns, []interface{}{5, "orders", "old", 0}).Once()
+	f.On("id > ?").Returns(columns, []interface{}{6, "orders", "a", 0}, []interface{}{7, "orders", "b", 0}).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := db.Listen(ctx, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, payload := range []string{"a", "b"} {
+		if n := <-ch; n.Channel != "orders" || n.Payload != payload {
+			t.Fatal(n)
+		}
+	}
+
+	if !strings.HasPrefix(f.Statements()[0].Query, "CREATE TABLE IF NOT EXISTS gosql_notifications") {
+		t.Error(f.Statements()[0].Query)
+	}
+
+	for _, s := range f.Statements() {
+		if strings.Contains(s.Query, "id > ?") {
+			if s.Args[1] != int64(5) {
+				t.Error(s.Args)
+			}
+			break
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestDB_Listen_outOfOrder(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetNotificationPollInterval(time.Millisecond)
+	columns := []string{"id", "channel", "payload", "created_at"}
+	now := time.Now().Unix()
+	f.On("ORDER BY id DESC").Returns(columns, []interface{}{5, "orders", "old", 0}).Once()
+	f.On("id > ?").Returns(columns, []interface{}{7, "orders", "b", now}).Once()
+	f.On("id > ?").Returns(columns, []interface{}{6, "orders", "a", now}, []interface{}{7, "orders", "b", now}).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := db.Listen(ctx, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, payload := range []string{"b", "a"} {
+		if n := <-ch; n.Payload != payload {
+			t.Fatal(n)
+		}
+	}
+
+	cancel()
+	for n := range ch {
+		t.Error(n)
+	}
+
+	for _, s := range f.Statements() {
+		if strings.Contains(s.Query, "id > ?") && s.Args[1] != int64(5) {
+			t.Error(s.Args)
+		}
+	}
+}
+
+func TestDB_Notify_prune(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetNotificationRetention(time.Hour)
+	if err := db.Notify(context.Background(), "orders", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	s := f.LastStatement()
+	if s.Query != "DELETE FROM gosql_notifications WHERE created_at < ?" {
+		t.Fatal(s.Query)
+	}
+
+	if d := time.Now().Add(-time.Hour).Unix() - s.Args[0].(int64); d < 0 || d > 1 {
+		t.Error(s.Args)
+	}
+
+	if err := db.Notify(context.Background(), "orders", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); !strings.HasPrefix(s.Query, "INSERT") {
+		t.Error(s.Query)
+	}
+}
+
+func TestDB_Listen_postgres(t *testing.T) {
+	notifications := make(chan *sql.Notification, 1)
+	sql.WaitForNotification = func(ctx context.Context, driverConn interface{}) (*sql.Notification, error) {
+		select {
+		case n := <-notifications:
+			return n, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	defer func() {
+		sql.WaitForNotification = nil
+	}()
+
+	db, f := gosqltest.New("postgres")
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := db.Listen(ctx, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = db.Notify(ctx, "orders", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "SELECT pg_notify($1, $2)" || !reflect.DeepEqual(s.Args, []interface{}{"orders", "a"}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	notifications <- &sql.Notification{Channel: "orders", Payload: "a"}
+	if n := <-ch; n.Payload != "a" {
+		t.Error(n)
+	}
+
+	cancel()
+	for range ch {
+	}
+
+	if s := f.Statements()[0]; s.Query != `LISTEN "orders"` {
+		t.Error(s.Query)
+	}
+
+	if s := f.LastStatement(); s.Query != `UNLISTEN "orders"` {
+		t.Error(s.Query)
+	}
+}
@@ -0,0 +1,259 @@
+package sql
+
+import (
+	"github.com/gopub/log"
+	"time"
+)
+
+// InsertOptions are per-call options of Insert and Save.
+// Reads are routed by SelectOptions.UsePrimary, there's no routing of writes which always go to primary.
+//
+// Methods named XWithOptions take arguments of X with opts inserted right after the destination or record, before where and args,
+// e.g. SelectWithOptions(records, opts, where, args...) and DeleteRecordWithOptions(record, opts).
+// Table.DeleteWithOptions deletes by where like Table.Delete, so opts is the first
+type InsertOptions struct {
+	// Timeout cancels the statement if it's exceeded
+	Timeout time.Duration
+
+	// Comment is prepended to the statement as /* comment */
+	Comment string
+
+	// OnConflict upserts the record like Upsert
+	OnConflict *UpsertOptions
+
+	// Returning is a pointer to struct, which the inserted row is scanned into like InsertReturning
+	Returning interface{}
+}
+
+// UpdateOptions are per-call options of Update
+type UpdateOptions struct {
+	Timeout time.Duration
+	Comment string
+
+	// Returning is a pointer to struct, which the updated row is scanned into like UpdateReturning
+	Returning interface{}
+}
+
+// SelectOptions are per-call options of Select and SelectOne
+type SelectOptions struct {
+	Timeout time.Duration
+	Comment string
+
+	// UsePrimary reads from primary instead of replicas, e.g. to read own writes
+	UsePrimary bool
+
+	// CacheTTL caches results like Table.Cache
+	CacheTTL time.Duration
+}
+
+// DeleteOptions are per-call options of Delete
+type DeleteOptions struct {
+	Timeout time.Duration
+	Comment string
+
+	// Returning is a pointer to slice, which deleted rows are appended to like DeleteReturning
+	Returning interface{}
+}
+
+func (t *Table) withStatementOptions(timeout time.Duration, comment string) *Table {
+	c := *t
+	if timeout > 0 {
+		c.timeout = timeout
+	}
+
+	if len(comment) > 0 {
+		c.comment = comment
+	}
+	return &c
+}
+
+func (o *InsertOptions) apply(t *Table) *Table {
+	if o == nil {
+		return t
+	}
+	return t.withStatementOptions(o.Timeout, o.Comment)
+}
+
+func (o *UpdateOptions) apply(t *Table) *Table {
+	if o == nil {
+		return t
+	}
+	return t.withStatementOptions(o.Timeout, o.Comment)
+}
+
+func (o *SelectOptions) apply(t *Table) *Table {
+	if o == nil {
+		return t
+	}
+	c := t.withStatementOptions(o.Timeout, o.Comment)
+	if o.UsePrimary {
+		c.reader = c.exe
+	}
+
+	if o.CacheTTL > 0 {
+		c = c.Cache(o.CacheTTL, c.cacheTables...)
+	}
+	return c
+}
+
+func (o *DeleteOptions) apply(t *Table) *Table {
+	if o == nil {
+		return t
+	}
+	return t.withStatementOptions(o.Timeout, o.Comment)
+}
+
+// InsertWithOptions inserts record with opts
+func (t *Table) InsertWithOptions(record interface{}, opts *InsertOptions) error {
+	if opts == nil {
+		return t.Insert(record)
+	}
+	return opts.apply(t).insertWithOptions(record, opts.OnConflict, opts.Returning)
+}
+
+// SaveWithOptions saves record with opts, which is the same as InsertWithOptions if OnConflict is set
+func (t *Table) SaveWithOptions(record interface{}, opts *InsertOptions) error {
+	if opts == nil {
+		return t.Save(record)
+	}
+
+	onConflict := opts.OnConflict
+	if onConflict == nil && opts.Returning != nil {
+		onConflict = &UpsertOptions{}
+	}
+
+	c := opts.apply(t)
+	if onConflict == nil {
+		return c.Save(record)
+	}
+	return c.insertWithOptions(record, onConflict, opts.Returning)
+}
+
+func (t *Table) insertWithOptions(record interface{}, onConflict *UpsertOptions, returning interface{}) error {
+	if returning == nil {
+		if onConflict == nil {
+			return t.Insert(record)
+		}
+		return t.Upsert(record, onConflict)
+	}
+
+	var query string
+	var values []interface{}
+	var err error
+	if onConflict == nil {
+		query, values, err = t.prepareInsertQuery(record)
+	} else {
+		query, values, err = t.prepareUpsertQuery(record, onConflict)
+	}
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	return t.execReturning(query, values, returning)
+}
+
+// UpdateWithOptions updates record with opts
+func (t *Table) UpdateWithOptions(record interface{}, opts *UpdateOptions) error {
+	if opts != nil && opts.Returning != nil {
+		return opts.apply(t).UpdateReturning(record, opts.Returning)
+	}
+	return opts.apply(t).Update(record)
+}
+
+// SelectWithOptions selects records with opts
+func (t *Table) SelectWithOptions(records interface{}, opts *SelectOptions, where string, args ...interface{}) error {
+	return opts.apply(t).Select(records, where, args...)
+}
+
+// SelectOneWithOptions selects one record with opts
+func (t *Table) SelectOneWithOptions(record interface{}, opts *SelectOptions, where string, args ...interface{}) error {
+	return opts.apply(t).SelectOne(record, where, args...)
+}
+
+// DeleteWithOptions deletes rows matching where with opts, which is Delete with opts preceding where
+func (t *Table) DeleteWithOptions(opts *DeleteOptions, where string, args ...interface{}) error {
+	if opts != nil && opts.Returning != nil {
+		return opts.apply(t).DeleteReturning(opts.Returning, where, args...)
+	}
+	return opts.apply(t).Delete(where, args...)
+}
+
+// DeleteRecordWithOptions deletes record by its primary key with opts, which is DeleteRecord with opts following record
+func (t *Table) DeleteRecordWithOptions(record interface{}, opts *DeleteOptions) error {
+	if opts == nil || opts.Returning == nil {
+		return opts.apply(t).DeleteRecord(record)
+	}
+
+	v := getStructValue(record)
+	info := getColumnInfo(v.Type())
+	if len(info.pkNames) == 0 {
+		panic("no primary key. please use Delete with where")
+	}
+
+	args := make([]interface{}, 0, len(info.pkNames))
+	for _, name := range info.pkNames {
+		args = append(args, v.FieldByIndex(info.nameToIndex[name]).Interface())
+	}
+	c := opts.apply(t)
+	return c.DeleteReturning(opts.Returning, c.pkWhere(info), args...)
+}
+
+// InsertWithOptions inserts record with opts
+func (d *DB) InsertWithOptions(record interface{}, opts *InsertOptions) error {
+	return d.Table(getTableName(record)).InsertWithOptions(record, opts)
+}
+
+// SaveWithOptions saves record with opts
+func (d *DB) SaveWithOptions(record interface{}, opts *InsertOptions) error {
+	return d.Table(getTableName(record)).SaveWithOptions(record, opts)
+}
+
+// UpdateWithOptions updates record with opts
+func (d *DB) UpdateWithOptions(record interface{}, opts *UpdateOptions) error {
+	return d.Table(getTableName(record)).UpdateWithOptions(record, opts)
+}
+
+// SelectWithOptions selects records with opts
+func (d *DB) SelectWithOptions(records interface{}, opts *SelectOptions, where string, args ...interface{}) error {
+	return d.Table(getTableNameBySlice(records)).SelectWithOptions(records, opts, where, args...)
+}
+
+// SelectOneWithOptions selects one record with opts
+func (d *DB) SelectOneWithOptions(record interface{}, opts *SelectOptions, where string, args ...interface{}) error {
+	return d.Table(getTableName(record)).SelectOneWithOptions(record, opts, where, args...)
+}
+
+// DeleteWithOptions deletes record by its primary key with opts
+func (d *DB) DeleteWithOptions(record interface{}, opts *DeleteOptions) error {
+	return d.Table(getTableName(record)).DeleteRecordWithOptions(record, opts)
+}
+
+// InsertWithOptions inserts record with opts
+func (t *Tx) InsertWithOptions(record interface{}, opts *InsertOptions) error {
+	return t.Table(getTableName(record)).InsertWithOptions(record, opts)
+}
+
+// SaveWithOptions saves record with opts
+func (t *Tx) SaveWithOptions(record interface{}, opts *InsertOptions) error {
+	return t.Table(getTableName(record)).SaveWithOptions(record, opts)
+}
+
+// UpdateWithOptions updates record with opts
+func (t *Tx) UpdateWithOptions(record interface{}, opts *UpdateOptions) error {
+	return t.Table(getTableName(record)).UpdateWithOptions(record, opts)
+}
+
+// SelectWithOptions selects records with opts
+func (t *Tx) SelectWithOptions(records interface{}, opts *SelectOptions, where string, args ...interface{}) error {
+	return t.Table(getTableNameBySlice(records)).SelectWithOptions(records, opts, where, args...)
+}
+
+// SelectOneWithOptions selects one record with opts
+func (t *Tx) SelectOneWithOptions(record interface{}, opts *SelectOptions, where string, args ...interface{}) error {
+	return t.Table(getTableName(record)).SelectOneWithOptions(record, opts, where, args...)
+}
+
+// DeleteWithOptions deletes record by its primary key with opts
+func (t *Tx) DeleteWithOptions(record interface{}, opts *DeleteOptions) error {
+	return t.Table(getTableName(record)).DeleteRecordWithOptions(record, opts)
+}
@@ -0,0 +1,67 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDB_WithOptions(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	db.SetCache(sql.NewLRUCache(10))
+	f.On("INSERT").Returns([]string{"id", "name"}, []interface{}{1, "b"}).Once()
+	var inserted fakeItem
+	err := db.InsertWithOptions(&fakeItem{ID: 1, Name: "a"}, &sql.InsertOptions{
+		OnConflict: &sql.UpsertOptions{},
+		Returning:  &inserted,
+		Comment:    "sync",
+	})
+	if err != nil || inserted.Name != "b" {
+		t.Fatal(err, inserted)
+	}
+
+	if s := f.LastStatement().Query; s != "/* sync */ INSERT INTO fake_items(id, name) VALUES ($1, $2) "+
+		"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name RETURNING id, name" {
+		t.Error(s)
+	}
+
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a"}).Once()
+	for i := 0; i < 2; i++ {
+		var items []*fakeItem
+		if err = db.SelectWithOptions(&items, &sql.SelectOptions{CacheTTL: time.Minute}, "id = ?", 1); err != nil ||
+			len(items) != 1 {
+			t.Fatal(err, items)
+		}
+	}
+
+	if n := len(f.Statements()); n != 2 {
+		t.Error(f.Statements())
+	}
+
+	f.On("DELETE").Returns([]string{"id", "name"}, []interface{}{1, "a"}).Once()
+	var deleted []*fakeItem
+	if err = db.DeleteWithOptions(&fakeItem{ID: 1}, &sql.DeleteOptions{Returning: &deleted}); err != nil || len(deleted) != 1 {
+		t.Fatal(err, deleted)
+	}
+
+	if s := f.LastStatement(); s.Query != "DELETE FROM fake_items WHERE id = $1 RETURNING id, name" ||
+		!reflect.DeepEqual(s.Args, []interface{}{int64(1)}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	if err = db.Table("items").DeleteRecordWithOptions(&fakeItem{ID: 2}, &sql.DeleteOptions{Comment: "gc"}); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Query != "/* gc */ DELETE FROM items WHERE id = $1" {
+		t.Error(s.Query)
+	}
+
+	if err = db.Table("items").DeleteWithOptions(&sql.DeleteOptions{Comment: "gc"}, "id > ?", 2); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Query != "/* gc */ DELETE FROM items WHERE id > $1" {
+		t.Error(s.Query)
+	}
+}
@@ -0,0 +1,217 @@
+package sql
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type PartitionPeriod int
+
+const (
+	PartitionDaily PartitionPeriod = iota
+	PartitionMonthly
+	PartitionYearly
+)
+
+// suffix returns suffix of partition name containing t, e.g. 2024_06_01, 2024_06 or 2024
+func (p PartitionPeriod) suffix(t time.Time) string {
+	switch p {
+	case PartitionDaily:
+		return t.Format("2006_01_02")
+	case PartitionMonthly:
+		return t.Format("2006_01")
+	case PartitionYearly:
+		return t.Format("2006")
+	default:
+		panic(fmt.Sprintf("invalid partition period: %d", p))
+	}
+}
+
+// start returns start time of partition containing t
+func (p PartitionPeriod) start(t time.Time) time.Time {
+	switch p {
+	case PartitionDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case PartitionMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case PartitionYearly:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	default:
+		panic(fmt.Sprintf("invalid partition period: %d", p))
+	}
+}
+
+// next returns start time of the next partition of start
+func (p PartitionPeriod) next(start time.Time) time.Time {
+	switch p {
+	case PartitionDaily:
+		return start.AddDate(0, 0, 1)
+	case PartitionMonthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(1, 0, 0)
+	}
+}
+
+// PartitionedTable is a set of physical tables named by base name and period of timestamp column,
+// e.g. events_2024_06 holds events created in June 2024 if it's partitioned monthly by created_at
+type PartitionedTable struct {
+	db         *DB
+	name       string
+	column     string
+	period     PartitionPeriod
+	loc        *time.Location
+	autoCreate bool
+	created    *sync.Map
+}
+
+// PartitionedTable returns a table partitioned by timestamp column, whose partitions are named in UTC
+func (d *DB) PartitionedTable(name, column string, period PartitionPeriod) *PartitionedTable {
+	if period < PartitionDaily || period > PartitionYearly {
+		panic(fmt.Sprintf("invalid partition period: %d", period))
+	}
+
+	return &PartitionedTable{
+		db:      d,
+		name:    name,
+		column:  column,
+		period:  period,
+		loc:     time.UTC,
+		created: &sync.Map{},
+	}
+}
+
+// In returns a copy of p whose partitions are named in loc
+func (p *PartitionedTable) In(loc *time.Location) *PartitionedTable {
+	c := *p
+	c.loc = loc
+	return &c
+}
+
+// AutoCreate returns a copy of p which creates missing partitions for records before writing them
+func (p *PartitionedTable) AutoCreate() *PartitionedTable {
+	c := *p
+	c.autoCreate = true
+	return &c
+}
+
+// Name returns name of partition containing t
+func (p *PartitionedTable) Name(t time.Time) string {
+	return p.name + "_" + p.period.suffix(t.In(p.loc))
+}
+
+// Table returns partition containing t
+func (p *PartitionedTable) Table(t time.Time) *Table {
+	return p.db.Table(p.Name(t))
+}
+
+// Tables returns partitions overlapping [from, to) in order of time
+func (p *PartitionedTable) Tables(from, to time.Time) []*Table {
+	var l []*Table
+	for start := p.period.start(from.In(p.loc)); start.Before(to); start = p.period.next(start) {
+		l = append(l, p.db.Table(p.Name(start)))
+	}
+	return l
+}
+
+// Create creates partition containing t for record if it doesn't exist
+func (p *PartitionedTable) Create(t time.Time, record interface{}) error {
+	name := p.Name(t)
+	if err := p.db.Table(name).CreateTable(record); err != nil {
+		return err
+	}
+	p.created.Store(name, true)
+	return nil
+}
+
+// tableOf returns partition of record by its timestamp field, which is created if p creates partitions on demand
+func (p *PartitionedTable) tableOf(record interface{}) (*Table, error) {
+	v := getStructValue(record)
+	info := getColumnInfo(v.Type())
+	index, ok := info.nameToIndex[p.column]
+	if !ok {
+		panic("no partition column " + p.column + " in " + v.Type().String())
+	}
+
+	f := v.FieldByIndex(index)
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			panic("partition column is nil: " + p.column)
+		}
+		f = f.Elem()
+	}
+
+	t, ok := f.Interface().(time.Time)
+	if !ok {
+		panic("partition column isn't time.Time: " + f.Type().String())
+	}
+
+	if p.autoCreate {
+		if _, ok := p.created.Load(p.Name(t)); !ok {
+			if err := p.Create(t, record); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p.Table(t), nil
+}
+
+func (p *PartitionedTable) Insert(record interface{}) error {
+	t, err := p.tableOf(record)
+	if err != nil {
+		return err
+	}
+	return t.Insert(record)
+}
+
+func (p *PartitionedTable) Update(record interface{}) error {
+	t, err := p.tableOf(record)
+	if err != nil {
+		return err
+	}
+	return t.Update(record)
+}
+
+func (p *PartitionedTable) Save(record interface{}) error {
+	t, err := p.tableOf(record)
+	if err != nil {
+		return err
+	}
+	return t.Save(record)
+}
+
+func (p *PartitionedTable) Delete(record interface{}) error {
+	t, err := p.tableOf(record)
+	if err != nil {
+		return err
+	}
+	return t.DeleteRecord(record)
+}
+
+// Select selects from partitions overlapping [from, to) in order of time, rows are matched by where and timestamp column.
+// Missing partitions must be excluded by from and to, otherwise the statement fails
+func (p *PartitionedTable) Select(records interface{}, from, to time.Time, where string, args ...interface{}) error {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		panic("must be a pointer to slice")
+	}
+
+	if len(where) > 0 {
+		where = "(" + where + ") AND "
+	}
+	where += p.column + " >= ? AND " + p.column + " < ?"
+	args = append(args[:len(args):len(args)], from, to)
+
+	l := v.Elem()
+	for _, t := range p.Tables(from, to) {
+		result := reflect.New(l.Type())
+		if err := t.Select(result.Interface(), where, args...); err != nil {
+			return err
+		}
+		l = reflect.AppendSlice(l, result.Elem())
+	}
+	v.Elem().Set(l)
+	return nil
+}
@@ -0,0 +1,46 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDB_PartitionedTable(t *testing.T) {
+	type event struct {
+		ID        int64     `sql:"primary key"`
+		CreatedAt time.Time `sql:"created_at"`
+	}
+
+	db, f := gosqltest.New("mysql")
+	p := db.PartitionedTable("events", "created_at", sql.PartitionMonthly).AutoCreate()
+	at := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	if err := p.Insert(&event{ID: 1, CreatedAt: at}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Insert(&event{ID: 2, CreatedAt: at}); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := f.Statements()
+	if len(statements) != 3 || !strings.HasPrefix(statements[0].Query, "CREATE TABLE IF NOT EXISTS events_2024_06") ||
+		!strings.HasPrefix(statements[1].Query, "INSERT INTO events_2024_06") || !strings.HasPrefix(statements[2].Query, "INSERT") {
+		t.Fatal(statements)
+	}
+
+	f.Reset()
+	f.On("FROM events_2024_05").Returns([]string{"id", "created_at"}, []interface{}{1, at})
+	f.On("FROM events_2024_06").Returns([]string{"id", "created_at"}, []interface{}{2, at})
+	var events []*event
+	err := p.Select(&events, time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), "id > ?", 0)
+	if err != nil || len(events) != 2 || events[1].ID != 2 {
+		t.Fatal(err, events)
+	}
+
+	statements = f.Statements()
+	if len(statements) != 2 || !strings.Contains(statements[0].Query, "WHERE (id > ?) AND created_at >= ? AND created_at < ?") {
+		t.Error(statements)
+	}
+}
@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Buffers and args are reused by statement builders to reduce allocations. Large ones are dropped instead of
+// being pooled, so that a single huge statement doesn't pin memory
+const maxPooledBufferSize = 64 << 10
+
+const maxPooledArgsSize = 1024
+
+var _bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+var _argsPool = sync.Pool{
+	New: func() interface{} {
+		return new([]interface{})
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	b := _bufferPool.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+// putBuffer puts b back to pool, b must not be used after, but strings returned by b.String() can be
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() <= maxPooledBufferSize {
+		_bufferPool.Put(b)
+	}
+}
+
+// getArgs returns an empty slice with capacity of at least n
+func getArgs(n int) []interface{} {
+	p := _argsPool.Get().(*[]interface{})
+	if cap(*p) < n {
+		return make([]interface{}, 0, n)
+	}
+	return (*p)[:0]
+}
+
+// putArgs puts args back to pool after the statement is executed
+func putArgs(args []interface{}) {
+	if cap(args) > maxPooledArgsSize {
+		return
+	}
+
+	for i := range args {
+		args[i] = nil
+	}
+	args = args[:0]
+	_argsPool.Put(&args)
+}
+
+// releaseArgs puts args of a statement executed by t back to pool, unless they were passed to middlewares,
+// which may keep them, e.g. audit sinks or loggers
+func (t *Table) releaseArgs(args []interface{}) {
+	if t.opts != nil && len(t.opts.middlewares) > 0 {
+		return
+	}
+	putArgs(args)
+}
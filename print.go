@@ -8,9 +8,12 @@ func toReadableArgs(args []interface{}) []interface{} {
 	if log.DebugLevel >= log.GetLevel() {
 		readableArgs := make([]interface{}, len(args))
 		for i, a := range args {
-			if b, ok := a.([]byte); ok {
-				readableArgs[i] = string(b)
-			} else {
+			switch x := a.(type) {
+			case []byte:
+				readableArgs[i] = string(x)
+			case SensitiveValue:
+				readableArgs[i] = redacted
+			default:
 				readableArgs[i] = a
 			}
 		}
@@ -18,3 +21,20 @@ func toReadableArgs(args []interface{}) []interface{} {
 	}
 	return args
 }
+
+// logQuery logs query and its args at debug level
+func (o *options) logQuery(driverName, query string, args []interface{}) {
+	if log.GetLevel() > log.DebugLevel {
+		return
+	}
+
+	if o != nil && o.interpolatedLog {
+		log.Debug(interpolate(driverName, query, args))
+		return
+	}
+	log.Debug(query, toReadableArgs(args))
+}
+
+func (t *Table) logQuery(query string, args []interface{}) {
+	t.opts.logQuery(t.driverName, query, args)
+}
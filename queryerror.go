@@ -0,0 +1,84 @@
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// QueryError is the error of a statement executed by Table, with its context. Err is the error returned by driver,
+// which is matched by errors.Is and errors.As, e.g. errors.Is(err, context.DeadlineExceeded)
+type QueryError struct {
+	// Op is the operation of Query, e.g. SELECT, INSERT
+	Op    string
+	Table string
+	Query string
+
+	// Args are a copy of args of Query, whose sensitive values are redacted
+	Args []interface{}
+	Err  error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%s %s: %v [query: %s, args: %d]", e.Op, e.Table, e.Err, e.Query, len(e.Args))
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// queryError returns err of query as *QueryError. ErrNoRows is returned as it is, which callers often compare directly
+func (t *Table) queryError(query string, args []interface{}, err error) error {
+	err = t.txError(err)
+	if err == nil || err == sql.ErrNoRows {
+		return err
+	}
+
+	var qe *QueryError
+	if errors.As(err, &qe) {
+		return err
+	}
+	return &QueryError{
+		Op:    statementOp(query),
+		Table: t.name,
+		Query: query,
+		Args:  Redact(append([]interface{}(nil), args...)),
+		Err:   err,
+	}
+}
+
+// statementOp returns the first keyword of query after leading comments, or the keyword of the main statement
+// following WITH clause, e.g. SELECT
+func statementOp(query string) string {
+	q := strings.TrimSpace(query)
+	for strings.HasPrefix(q, "/*") {
+		i := strings.Index(q, "*/")
+		if i < 0 {
+			break
+		}
+		q = strings.TrimSpace(q[i+2:])
+	}
+
+	fields := strings.Fields(q)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	op := strings.ToUpper(fields[0])
+	if op != "WITH" {
+		return op
+	}
+
+	depth := 0
+	for _, f := range fields[1:] {
+		if depth == 0 {
+			switch w := strings.ToUpper(f); w {
+			case "SELECT", "INSERT", "UPDATE", "DELETE":
+				return w
+			}
+		}
+		depth += strings.Count(f, "(") - strings.Count(f, ")")
+	}
+	return op
+}
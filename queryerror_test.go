@@ -0,0 +1,42 @@
+package sql_test
+
+import (
+	"errors"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryError(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	failure := errors.New("failure")
+	f.On("UPDATE").Fails(failure)
+	err := db.Table("users").UpdateColumnsMap(map[string]interface{}{"token": sql.Sensitive("secret")}, "id = ?", 1)
+	var qe *sql.QueryError
+	if !errors.As(err, &qe) || !errors.Is(err, failure) {
+		t.Fatal(err)
+	}
+
+	if qe.Op != "UPDATE" || qe.Table != "users" || qe.Query != "UPDATE users SET token = ? WHERE id = ?" ||
+		!reflect.DeepEqual(qe.Args, []interface{}{"[REDACTED]", 1}) {
+		t.Error(qe.Op, qe.Table, qe.Query, qe.Args)
+	}
+
+	if strings.Contains(err.Error(), "secret") {
+		t.Error(err)
+	}
+
+	var item fakeItem
+	if err = db.Table("items").SelectOne(&item, "id = ?", 1); err != sql.ErrNoRows {
+		t.Error(err)
+	}
+
+	// args of Insert are pooled, which must not be shared with the error
+	f.On("INSERT").Fails(failure).Once()
+	err = db.Table("items").Insert(&fakeItem{ID: 2, Name: "b"})
+	if !errors.As(err, &qe) || !reflect.DeepEqual(qe.Args, []interface{}{int64(2), "b"}) {
+		t.Error(err, qe.Args)
+	}
+}
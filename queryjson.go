@@ -0,0 +1,105 @@
+package sql
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"github.com/gopub/log"
+	"io"
+)
+
+// QueryJSON streams rows of query into w as a JSON array of objects, whose keys are column names in order.
+// Text and binary values are written as strings, NULL is null
+func (d *DB) QueryJSON(w io.Writer, query string, args ...interface{}) error {
+	return d.queryJSON(w, false, query, args)
+}
+
+// QueryNDJSON streams rows of query into w as newline delimited JSON objects
+func (d *DB) QueryNDJSON(w io.Writer, query string, args ...interface{}) error {
+	return d.queryJSON(w, true, query, args)
+}
+
+func (d *DB) queryJSON(w io.Writer, ndjson bool, query string, args []interface{}) error {
+	rows, err := d.Query(query, args...)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer rows.Close()
+
+	if err = writeJSONRows(w, rows, ndjson); err != nil {
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+func writeJSONRows(w io.Writer, rows *sql.Rows, ndjson bool) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	// keys are encoded once, e.g. ,"name":
+	keys := make([][]byte, len(columns))
+	for i, c := range columns {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			keys[i] = append(keys[i], ',')
+		}
+		keys[i] = append(append(keys[i], data...), ':')
+	}
+
+	bw := bufio.NewWriter(w)
+	values := make([]interface{}, len(columns))
+	addrs := make([]interface{}, len(columns))
+	for i := range values {
+		addrs[i] = &values[i]
+	}
+
+	if !ndjson {
+		bw.WriteByte('[')
+	}
+
+	for n := 0; rows.Next(); n++ {
+		if err = rows.Scan(addrs...); err != nil {
+			return err
+		}
+
+		if n > 0 && !ndjson {
+			bw.WriteByte(',')
+		}
+
+		bw.WriteByte('{')
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			bw.Write(keys[i])
+			bw.Write(data)
+		}
+		bw.WriteByte('}')
+
+		if ndjson {
+			bw.WriteByte('\n')
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	if !ndjson {
+		bw.WriteByte(']')
+	}
+	return bw.Flush()
+}
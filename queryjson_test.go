@@ -0,0 +1,36 @@
+package sql_test
+
+import (
+	"bytes"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestDB_QueryJSON(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, []byte("a")}, []interface{}{2, nil})
+	var buf bytes.Buffer
+	if err := db.QueryJSON(&buf, "SELECT id, name FROM items"); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != `[{"id":1,"name":"a"},{"id":2,"name":null}]` {
+		t.Error(buf.String())
+	}
+
+	buf.Reset()
+	if err := db.QueryNDJSON(&buf, "SELECT id, name FROM items"); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":null}\n" {
+		t.Error(buf.String())
+	}
+
+	buf.Reset()
+	f.Reset()
+	f.On("SELECT").Returns([]string{"id"})
+	if err := db.QueryJSON(&buf, "SELECT id FROM items"); err != nil || buf.String() != "[]" {
+		t.Error(err, buf.String())
+	}
+}
@@ -0,0 +1,129 @@
+package sql
+
+import (
+	"strings"
+)
+
+// _reservedWords are keywords which can't be used as identifiers without quoting
+var _reservedWords = map[string]struct{}{
+	"all": {}, "alter": {}, "and": {}, "as": {}, "asc": {}, "between": {}, "by": {}, "case": {}, "check": {},
+	"column": {}, "constraint": {}, "create": {}, "cross": {}, "default": {}, "delete": {}, "desc": {},
+	"distinct": {}, "drop": {}, "else": {}, "end": {}, "from": {}, "full": {}, "grant": {}, "group": {},
+	"having": {}, "in": {}, "index": {}, "inner": {}, "insert": {}, "interval": {}, "into": {}, "is": {},
+	"join": {}, "key": {}, "left": {}, "like": {}, "limit": {}, "natural": {}, "not": {}, "null": {},
+	"offset": {}, "on": {}, "or": {}, "order": {}, "outer": {}, "primary": {}, "range": {}, "rank": {},
+	"read": {}, "references": {}, "right": {}, "rows": {}, "select": {}, "set": {}, "table": {}, "then": {},
+	"to": {}, "union": {}, "unique": {}, "update": {}, "user": {}, "using": {}, "values": {}, "when": {},
+	"where": {}, "window": {}, "with": {}, "write": {},
+}
+
+// quoteIdent quotes name for driver if it's a reserved word or contains characters other than letters, digits
+// and underscores. Dotted name like schema.table is quoted part by part. It panics if name is empty or contains
+// quote or control characters, which can't be quoted safely
+func quoteIdent(driverName, name string) string {
+	lq, rq := "\"", "\""
+	switch driverName {
+	case "mysql":
+		lq, rq = "`", "`"
+	case "sqlserver", "mssql":
+		lq, rq = "[", "]"
+	}
+
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		if isPlainIdent(p) {
+			continue
+		}
+
+		if len(p) > 2 && strings.HasPrefix(p, lq) && strings.HasSuffix(p, rq) {
+			// already quoted
+			p = p[1 : len(p)-1]
+			if !isValidIdent(p, lq, rq) {
+				panic("invalid identifier: " + name)
+			}
+			continue
+		}
+
+		if !isValidIdent(p, lq, rq) {
+			panic("invalid identifier: " + name)
+		}
+		parts[i] = lq + p + rq
+	}
+	return strings.Join(parts, ".")
+}
+
+func isPlainIdent(s string) bool {
+	if len(s) == 0 || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return false
+		}
+	}
+
+	_, reserved := _reservedWords[strings.ToLower(s)]
+	return !reserved
+}
+
+func isValidIdent(s, lq, rq string) bool {
+	if len(s) == 0 || strings.Contains(s, lq) || strings.Contains(s, rq) {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Table) quote(name string) string {
+	return quoteIdent(t.driverName, name)
+}
+
+// quoteColumns quotes names and joins them with comma
+func (t *Table) quoteColumns(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = t.quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// WithSchema returns a copy of t whose table is in schema, e.g. db.Table("events e").WithSchema("analytics")
+func (t *Table) WithSchema(schema string) *Table {
+	c := *t
+	_, name := splitQualified(t.name)
+	c.name = schema + "." + name
+	return &c
+}
+
+// qualify prepends default schema to table name if it isn't qualified
+func (o *options) qualify(name string) string {
+	if o == nil || len(o.schema) == 0 {
+		return name
+	}
+
+	if schema, _ := splitQualified(name); len(schema) > 0 {
+		return name
+	}
+	return o.schema + "." + name
+}
+
+// splitQualified splits table name, which may be followed by alias, into schema and the rest
+func splitQualified(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	end := strings.IndexAny(name, " \t")
+	if end < 0 {
+		end = len(name)
+	}
+
+	i := strings.LastIndex(name[:end], ".")
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
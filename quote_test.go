@@ -0,0 +1,58 @@
+package sql
+
+import (
+	"testing"
+)
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		driverName string
+		name       string
+		expected   string
+	}{
+		{"mysql", "products", "products"},
+		{"mysql", "order", "`order`"},
+		{"mysql", "my table", "`my table`"},
+		{"mysql", "`order`", "`order`"},
+		{"postgres", "public.user", `public."user"`},
+		{"sqlite3", "2fa", `"2fa"`},
+		{"sqlserver", "key", "[key]"},
+	}
+
+	for _, test := range tests {
+		if got := quoteIdent(test.driverName, test.name); got != test.expected {
+			t.Error(test.driverName, test.name, got)
+		}
+	}
+
+	for _, name := range []string{"", "a`; DROP TABLE users; --", "a\nb", "public."} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic:", name)
+				}
+			}()
+			quoteIdent("mysql", name)
+		}()
+	}
+}
+
+func TestQualify(t *testing.T) {
+	opts := &options{schema: "analytics"}
+	tests := map[string]string{
+		"events":          "analytics.events",
+		"events e":        "analytics.events e",
+		"app.events":      "app.events",
+		"app.events AS e": "app.events AS e",
+	}
+	for name, expected := range tests {
+		if got := opts.qualify(name); got != expected {
+			t.Error(got)
+		}
+	}
+
+	tbl := &Table{driverName: "postgres", name: "app.events e", opts: &options{}}
+	if got := tbl.WithSchema("user").from(); got != `"user".events e` {
+		t.Error(got)
+	}
+}
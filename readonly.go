@@ -0,0 +1,130 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+)
+
+// ErrReadOnly is returned by statements changing data through ReadOnlyDB
+var ErrReadOnly = errors.New("read only")
+
+// ReadOnlyDB is a handle of DB which only has methods to read, e.g. to be passed into report and export code.
+// Tables of it are guarded at runtime, whose statements other than SELECT, SHOW and EXPLAIN return ErrReadOnly.
+// Reads go to replicas if there are any
+type ReadOnlyDB struct {
+	db *DB
+}
+
+// ReadOnly returns a read only handle of d
+func (d *DB) ReadOnly() *ReadOnlyDB {
+	opts := *d.opts
+	opts.readOnly = true
+	opts.middlewares = append([]Middleware{guardReadOnly}, d.opts.middlewares...)
+	return &ReadOnlyDB{
+		db: &DB{
+			db:         d.db,
+			driverName: d.driverName,
+			replicas:   d.replicas,
+			opts:       &opts,
+		},
+	}
+}
+
+func (r *ReadOnlyDB) Table(name string) *Table {
+	return r.db.Table(name)
+}
+
+func (r *ReadOnlyDB) Select(records interface{}, where string, args ...interface{}) error {
+	return r.db.Select(records, where, args...)
+}
+
+func (r *ReadOnlyDB) SelectOne(record interface{}, where string, args ...interface{}) error {
+	return r.db.SelectOne(record, where, args...)
+}
+
+func (r *ReadOnlyDB) Get(record interface{}, pk ...interface{}) error {
+	return r.db.Get(record, pk...)
+}
+
+func (r *ReadOnlyDB) Iterate(record interface{}, fn func() error, where string, args ...interface{}) error {
+	return r.db.Iterate(record, fn, where, args...)
+}
+
+func (r *ReadOnlyDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.db.Query(query, args...)
+}
+
+func (r *ReadOnlyDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return r.db.QueryRow(query, args...)
+}
+
+func guardReadOnly(next Executor) Executor {
+	return &readOnlyGuard{next: next}
+}
+
+// readOnlyGuard rejects statements which may change data
+type readOnlyGuard struct {
+	next Executor
+}
+
+// isReadStatement reports whether query only reads. Statements containing others, e.g. common tables of SELECT,
+// or EXPLAIN ANALYZE which executes the statement, mustn't change data either
+func isReadStatement(query string) bool {
+	switch statementOp(query) {
+	case "SELECT", "EXPLAIN", "DECLARE":
+		for _, f := range strings.Fields(strings.ToUpper(query)) {
+			switch strings.TrimLeft(f, "(") {
+			case "INSERT", "UPDATE", "DELETE", "MERGE":
+				return false
+			}
+		}
+		return true
+	case "SHOW", "DESCRIBE", "VALUES", "FETCH", "CLOSE":
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *readOnlyGuard) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if !isReadStatement(query) {
+		return nil, ErrReadOnly
+	}
+	return g.next.ExecContext(ctx, query, args...)
+}
+
+func (g *readOnlyGuard) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !isReadStatement(query) {
+		return nil, ErrReadOnly
+	}
+	return g.next.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext returns row of ErrReadOnly by a database failing to connect, as sql.Row can't be made otherwise
+func (g *readOnlyGuard) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if !isReadStatement(query) {
+		return _readOnlyDB.QueryRowContext(ctx, query)
+	}
+	return g.next.QueryRowContext(ctx, query, args...)
+}
+
+var _readOnlyDB = sql.OpenDB(readOnlyConnector{})
+
+type readOnlyConnector struct{}
+
+func (c readOnlyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return nil, ErrReadOnly
+}
+
+func (c readOnlyConnector) Driver() driver.Driver {
+	return readOnlyDriver{}
+}
+
+type readOnlyDriver struct{}
+
+func (d readOnlyDriver) Open(name string) (driver.Conn, error) {
+	return nil, ErrReadOnly
+}
@@ -0,0 +1,37 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestDB_ReadOnly(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a"})
+	ro := db.ReadOnly()
+	var items []*fakeItem
+	if err := ro.Table("items").Select(&items, "id > ?", 0); err != nil || len(items) != 1 {
+		t.Fatal(err, items)
+	}
+
+	if err := ro.Table("items").Insert(&fakeItem{Name: "b"}); !errors.Is(err, sql.ErrReadOnly) {
+		t.Error(err)
+	}
+
+	var item fakeItem
+	err := ro.Table("items").WithContext(context.Background()).UpdateReturning(&fakeItem{ID: 1, Name: "b"}, &item)
+	if !errors.Is(err, sql.ErrReadOnly) {
+		t.Error(err)
+	}
+
+	if _, err = ro.Query("WITH d AS (DELETE FROM items RETURNING id) SELECT id FROM d"); err != sql.ErrReadOnly {
+		t.Error(err)
+	}
+
+	if len(f.Statements()) != 1 {
+		t.Error(f.Statements())
+	}
+}
@@ -0,0 +1,201 @@
+package sql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	relHasMany   = "has_many"
+	relHasOne    = "has_one"
+	relBelongsTo = "belongs_to"
+)
+
+// relation is declared by tag rel:has_many, rel:has_one or rel:belongs_to, with optional fk:column
+type relation struct {
+	kind  string
+	index fieldIndex
+
+	//struct type of related records
+	elemType reflect.Type
+
+	//foreign key column, which is in related table for has_many and has_one, or in parent table for belongs_to
+	fk string
+}
+
+func parseRelation(parent reflect.Type, f reflect.StructField, tag string) *relation {
+	r := &relation{index: f.Index}
+	for _, s := range strings.Split(tag, ",") {
+		s = strings.TrimSpace(s)
+		switch {
+		case strings.HasPrefix(s, "rel:"):
+			r.kind = s[len("rel:"):]
+		case strings.HasPrefix(s, "fk:"):
+			r.fk = s[len("fk:"):]
+		}
+	}
+
+	typ := f.Type
+	if r.kind == relHasMany {
+		if typ.Kind() != reflect.Slice {
+			panic("has_many field must be a slice: " + f.Name)
+		}
+		typ = typ.Elem()
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		panic("invalid relation type: " + f.Type.String())
+	}
+	r.elemType = typ
+
+	switch r.kind {
+	case relHasMany, relHasOne:
+		if len(r.fk) == 0 {
+			r.fk = naming().ColumnName(parent.Name()) + "_id"
+		}
+	case relBelongsTo:
+		if len(r.fk) == 0 {
+			r.fk = naming().ColumnName(f.Name) + "_id"
+		}
+	default:
+		panic("invalid relation: " + r.kind)
+	}
+	return r
+}
+
+// Preload loads relations of fields into selected records by Select and SelectOne, with one query per relation, e.g.
+//
+//	type User struct {
+//		ID     int64
+//		Orders []*Order `sql:"rel:has_many,fk:user_id"`
+//	}
+//
+//	db.Table("users").Preload("Orders").Select(&users, "")
+func (t *Table) Preload(fields ...string) *Table {
+	c := *t
+	c.preloads = fields
+	return &c
+}
+
+// loadRelations loads preloaded relations of parents, which are addressable struct values
+func (t *Table) loadRelations(parents []reflect.Value, info *columnInfo) error {
+	for _, name := range t.preloads {
+		r, ok := info.relations[name]
+		if !ok {
+			panic("no relation: " + name)
+		}
+
+		if err := t.loadRelation(parents, info, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Table) loadRelation(parents []reflect.Value, info *columnInfo, r *relation) error {
+	related := getColumnInfo(r.elemType)
+
+	// parents are matched with related records by parentKey = relatedKey
+	var parentKey, relatedKey string
+	if r.kind == relBelongsTo {
+		parentKey, relatedKey = r.fk, singlePKName(related)
+	} else {
+		parentKey, relatedKey = singlePKName(info), r.fk
+	}
+
+	if _, ok := info.nameToIndex[parentKey]; !ok {
+		panic("unknown column: " + parentKey)
+	}
+
+	if _, ok := related.nameToIndex[relatedKey]; !ok {
+		panic("unknown column: " + relatedKey)
+	}
+
+	var keys []interface{}
+	seen := make(map[string]bool, len(parents))
+	for _, p := range parents {
+		k := p.FieldByIndex(info.nameToIndex[parentKey]).Interface()
+		if s := fmt.Sprint(k); !seen[s] {
+			seen[s] = true
+			keys = append(keys, k)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	c := &Table{
+		exe:        t.exe,
+		reader:     t.reader,
+		db:         t.db,
+		tx:         t.tx,
+		driverName: t.driverName,
+		name:       t.opts.qualify(getTableNameByType(r.elemType)),
+		opts:       t.opts,
+		ctx:        t.ctx,
+		timeout:    t.timeout,
+		comment:    t.comment,
+	}
+	records := reflect.New(reflect.SliceOf(reflect.PtrTo(r.elemType)))
+	size := c.maxParams()
+	for i := 0; i < len(keys); i += size {
+		end := i + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		where := c.quote(relatedKey) + " IN (" + strings.TrimSuffix(strings.Repeat("?, ", end-i), ", ") + ")"
+		if err := c.Select(records.Interface(), where, keys[i:end]...); err != nil {
+			return err
+		}
+	}
+
+	groups := make(map[string][]reflect.Value)
+	l := records.Elem()
+	for i := 0; i < l.Len(); i++ {
+		rec := l.Index(i)
+		k := fmt.Sprint(rec.Elem().FieldByIndex(related.nameToIndex[relatedKey]).Interface())
+		groups[k] = append(groups[k], rec)
+	}
+
+	for _, p := range parents {
+		g := groups[fmt.Sprint(p.FieldByIndex(info.nameToIndex[parentKey]).Interface())]
+		f := p.FieldByIndex(r.index)
+		if r.kind == relHasMany {
+			s := reflect.MakeSlice(f.Type(), 0, len(g))
+			for _, rec := range g {
+				if f.Type().Elem().Kind() == reflect.Ptr {
+					s = reflect.Append(s, rec)
+				} else {
+					s = reflect.Append(s, rec.Elem())
+				}
+			}
+			f.Set(s)
+			continue
+		}
+
+		if len(g) == 0 {
+			continue
+		}
+
+		if f.Kind() == reflect.Ptr {
+			f.Set(g[0])
+		} else {
+			f.Set(g[0].Elem())
+		}
+	}
+	return nil
+}
+
+func singlePKName(info *columnInfo) string {
+	if len(info.pkNames) != 1 {
+		panic("relation requires single primary key")
+	}
+	return info.pkNames[0]
+}
@@ -0,0 +1,35 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+type relUser struct {
+	ID     int64
+	Name   string
+	Orders []*relOrder `sql:"rel:has_many"`
+}
+
+type relOrder struct {
+	ID        int64
+	RelUserID int64
+	Buyer     *relUser `sql:"rel:belongs_to,fk:rel_user_id"`
+}
+
+func TestParseRelation(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(relUser{}))
+	if !reflect.DeepEqual(info.names, []string{"id", "name"}) {
+		t.Error(info.names)
+	}
+
+	r := info.relations["Orders"]
+	if r == nil || r.kind != relHasMany || r.fk != "rel_user_id" || r.elemType != reflect.TypeOf(relOrder{}) {
+		t.Error(r)
+	}
+
+	r = getColumnInfo(reflect.TypeOf(relOrder{})).relations["Buyer"]
+	if r == nil || r.kind != relBelongsTo || r.fk != "rel_user_id" || r.elemType != reflect.TypeOf(relUser{}) {
+		t.Error(r)
+	}
+}
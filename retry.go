@@ -0,0 +1,43 @@
+package sql
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// MaxBlockRetries is the max number of attempts of RetryBlock
+var MaxBlockRetries = 3
+
+// RetryBlock runs fn in a savepoint of the transaction, and retries it from the savepoint on serialization failure or
+// deadlock, so a long transaction recovers from a localized conflict without restarting.
+// Other errors are returned after rolling back to the savepoint, and the transaction can go on
+func (t *Tx) RetryBlock(fn func(tx *Tx) error) error {
+	name := fmt.Sprintf("sp_%d", atomic.AddInt32(&t.savepoints, 1))
+	set, release, rollback := savepointQueries(t.driverName, name)
+	for attempt := 1; ; attempt++ {
+		if _, err := t.Exec(set); err != nil {
+			return err
+		}
+
+		err := fn(t)
+		if err == nil {
+			if len(release) > 0 {
+				_, err = t.Exec(release)
+			}
+			return err
+		}
+
+		if _, rerr := t.Exec(rollback); rerr != nil {
+			return rerr
+		}
+
+		if attempt >= MaxBlockRetries || !isRetryable(err) {
+			return err
+		}
+	}
+}
+
+// isRetryable reports whether err is a serialization failure or deadlock, whose statements can be retried
+func isRetryable(err error) bool {
+	return IsSerializationFailure(err) || IsDeadlock(err)
+}
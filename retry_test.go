@@ -0,0 +1,65 @@
+package sql_test
+
+import (
+	"context"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string {
+	return "pq: " + string(e)
+}
+
+func (e sqlStateError) SQLState() string {
+	return string(e)
+}
+
+func TestTx_RetryBlock(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	f.On("UPDATE").Fails(sqlStateError("40001")).Once()
+	attempts := 0
+	err := db.WithTx(context.Background(), func(tx *sql.Tx) error {
+		return tx.RetryBlock(func(tx *sql.Tx) error {
+			attempts++
+			_, err := tx.Exec("UPDATE accounts SET balance = balance - 1 WHERE id = $1", 1)
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 2 {
+		t.Error(attempts)
+	}
+
+	var queries []string
+	for _, s := range f.Statements() {
+		if !strings.HasPrefix(s.Query, "UPDATE") {
+			queries = append(queries, s.Query)
+		}
+	}
+	expected := []string{"BEGIN", "SAVEPOINT sp_1", "ROLLBACK TO SAVEPOINT sp_1", "SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1", "COMMIT"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Error(queries)
+	}
+
+	failure := sqlStateError("23505")
+	f.On("UPDATE").Fails(failure).Once()
+	attempts = 0
+	err = db.WithTx(context.Background(), func(tx *sql.Tx) error {
+		return tx.RetryBlock(func(tx *sql.Tx) error {
+			attempts++
+			_, err := tx.Exec("UPDATE accounts SET balance = balance - 1 WHERE id = $1", 1)
+			return err
+		})
+	})
+	if err != failure || attempts != 1 {
+		t.Error(err, attempts)
+	}
+}
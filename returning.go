@@ -0,0 +1,142 @@
+package sql
+
+import (
+	"github.com/gopub/log"
+	"reflect"
+)
+
+// InsertReturning inserts record, and scans the inserted row into dest in the same round trip,
+// e.g. to get values generated by database. dest is a pointer to struct, and can be record itself.
+// It's supported by postgres and sqlite3 3.35+
+func (t *Table) InsertReturning(record, dest interface{}) error {
+	query, values, err := t.prepareInsertQuery(record)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	return t.execReturning(query, values, dest)
+}
+
+// UpdateReturning updates record, and scans the updated row into dest
+func (t *Table) UpdateReturning(record, dest interface{}) error {
+	query, args, err := t.prepareUpdateQuery(record)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	return t.execReturning(query, args, dest)
+}
+
+// DeleteReturning deletes rows matching where, and appends them to records
+func (t *Table) DeleteReturning(records interface{}, where string, args ...interface{}) error {
+	if t.needsTimeoutTx() {
+		return t.withTx(func(c *Table) error {
+			return c.DeleteReturning(records, where, args...)
+		})
+	}
+
+	if err := t.checkAllRows(where); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	info := getColumnInfo(getSliceElemType(records))
+	if err := t.checkWhere(info, where, args); err != nil {
+		log.Error(err)
+		return err
+	}
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(info, where, args)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	query := t.returning(t.prepareDeleteQuery(where), info)
+	t.logQuery(query, args)
+
+	ctx, cancel := t.context()
+	defer cancel()
+	if err = t.setStatementTimeout(ctx); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	rows, err := t.exe.QueryContext(ctx, t.annotate(query), args...)
+	if err != nil {
+		err = t.queryError(query, args, err)
+		log.Error(err)
+		return err
+	}
+	defer rows.Close()
+
+	if err = scanRows(rows, records, info, t.opts); err != nil {
+		err = t.queryError(query, args, err)
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (t *Table) execReturning(query string, args []interface{}, dest interface{}) error {
+	if t.needsTimeoutTx() {
+		return t.withTx(func(c *Table) error {
+			return c.execReturning(query, args, dest)
+		})
+	}
+
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		panic("not pointer to a struct")
+	}
+
+	elem := getStructValue(dest)
+	info := getColumnInfo(elem.Type())
+	query = t.returning(query, info)
+	t.logQuery(query, args)
+
+	ctx, cancel := t.context()
+	defer cancel()
+	if err := t.setStatementTimeout(ctx); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if err := scanStruct(t.exe.QueryRowContext(ctx, t.annotate(query), args...), elem, info, t.opts); err != nil {
+		err = t.queryError(query, args, err)
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (t *Table) returning(query string, info *columnInfo) string {
+	if !isPostgres(t.driverName) && t.driverName != "sqlite3" {
+		panic("RETURNING is not supported for driver: " + t.driverName)
+	}
+	return query + " RETURNING " + t.quoteColumns(info.names)
+}
+
+func (d *DB) InsertReturning(record, dest interface{}) error {
+	return d.Table(getTableName(record)).InsertReturning(record, dest)
+}
+
+func (d *DB) UpdateReturning(record, dest interface{}) error {
+	return d.Table(getTableName(record)).UpdateReturning(record, dest)
+}
+
+func (d *DB) DeleteReturning(records interface{}, where string, args ...interface{}) error {
+	return d.Table(getTableNameBySlice(records)).DeleteReturning(records, where, args...)
+}
+
+func (t *Tx) InsertReturning(record, dest interface{}) error {
+	return t.Table(getTableName(record)).InsertReturning(record, dest)
+}
+
+func (t *Tx) UpdateReturning(record, dest interface{}) error {
+	return t.Table(getTableName(record)).UpdateReturning(record, dest)
+}
+
+func (t *Tx) DeleteReturning(records interface{}, where string, args ...interface{}) error {
+	return t.Table(getTableNameBySlice(records)).DeleteReturning(records, where, args...)
+}
@@ -0,0 +1,38 @@
+package sql
+
+import (
+	"errors"
+)
+
+// ErrUnsafeWhere is returned in safe mode by statements changing rows whose where clause matches all rows, e.g. 1=1
+var ErrUnsafeWhere = errors.New("where clause matches all rows, use AllRows to confirm")
+
+// SetSafeMode makes UpdateColumnsMap, Delete and DeleteReturning return ErrUnsafeWhere if where clause matches all rows,
+// unless AllRows is chained
+func (d *DB) SetSafeMode(safe bool) {
+	d.opts.safeMode = safe
+}
+
+// AllRows confirms UpdateColumnsMap, Delete and DeleteReturning to change all rows, where clause can be empty then,
+// e.g. db.Table("sessions").AllRows().Delete("")
+func (t *Table) AllRows() *Table {
+	c := *t
+	c.allRows = true
+	return &c
+}
+
+// checkAllRows panics if where is empty without AllRows, and returns ErrUnsafeWhere if where matches all rows in safe mode
+func (t *Table) checkAllRows(where string) error {
+	if t.allRows {
+		return nil
+	}
+
+	if len(where) == 0 {
+		panic("where is empty")
+	}
+
+	if t.opts.safeMode && isTautology(where) {
+		return ErrUnsafeWhere
+	}
+	return nil
+}
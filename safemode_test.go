@@ -0,0 +1,31 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestDB_SetSafeMode(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetSafeMode(true)
+	if err := db.Table("sessions").Delete("1=1"); err != sql.ErrUnsafeWhere {
+		t.Error(err)
+	}
+
+	if err := db.Table("sessions").UpdateColumnsMap(map[string]interface{}{"active": false}, "true"); err != sql.ErrUnsafeWhere {
+		t.Error(err)
+	}
+
+	if len(f.Statements()) != 0 {
+		t.Error(f.Statements())
+	}
+
+	if err := db.Table("sessions").AllRows().Delete(""); err != nil {
+		t.Error(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "DELETE FROM sessions" {
+		t.Error(s.Query)
+	}
+}
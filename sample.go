@@ -0,0 +1,80 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// OrderRandom returns a copy of t whose rows are selected in random order, which is ORDER BY RAND() on mysql,
+// NEWID() on sqlserver and RANDOM() on postgres and sqlite. It sorts all matched rows, use Sample for large tables
+func (t *Table) OrderRandom() *Table {
+	return t.OrderBy(t.randomFunc())
+}
+
+func (t *Table) randomFunc() string {
+	switch t.driverName {
+	case "mysql":
+		return "RAND()"
+	case "sqlserver", "mssql":
+		return "NEWID()"
+	default:
+		return "RANDOM()"
+	}
+}
+
+// Sample returns a copy of t which selects about percent of rows at random, percent is in (0, 100].
+// It's TABLESAMPLE BERNOULLI on postgres and TABLESAMPLE on sqlserver, whose sampled pages make the size less accurate.
+// Rows are filtered by random numbers on mysql and sqlite, which still scan the whole table
+func (t *Table) Sample(percent float64) *Table {
+	if percent <= 0 || percent > 100 {
+		panic(fmt.Sprintf("invalid percent: %v", percent))
+	}
+
+	if t.source != nil {
+		panic("can't sample subquery")
+	}
+
+	c := *t
+	c.samplePercent = percent
+	return &c
+}
+
+// sampleClause returns TABLESAMPLE clause following table name in FROM clause
+func (t *Table) sampleClause() string {
+	if t.samplePercent <= 0 {
+		return ""
+	}
+
+	p := strconv.FormatFloat(t.samplePercent, 'f', -1, 64)
+	switch {
+	case isPostgres(t.driverName):
+		return " TABLESAMPLE BERNOULLI (" + p + ")"
+	case t.driverName == "sqlserver" || t.driverName == "mssql":
+		return " TABLESAMPLE (" + p + " PERCENT)"
+	default:
+		return ""
+	}
+}
+
+// sampleWhere returns where with the condition sampling rows of drivers without TABLESAMPLE
+func (t *Table) sampleWhere(where string) string {
+	if t.samplePercent <= 0 {
+		return where
+	}
+
+	var cond string
+	switch t.driverName {
+	case "mysql":
+		cond = "RAND() < " + strconv.FormatFloat(t.samplePercent/100, 'f', -1, 64)
+	case "sqlite3":
+		// random() returns a 64-bit signed integer
+		cond = "abs(random() % 1000000) < " + strconv.FormatFloat(t.samplePercent*10000, 'f', 0, 64)
+	default:
+		return where
+	}
+
+	if len(where) == 0 {
+		return cond
+	}
+	return "(" + where + ") AND " + cond
+}
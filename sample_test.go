@@ -0,0 +1,26 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTable_Sample(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(joinUser{}))
+	tests := []struct {
+		driver   string
+		expected string
+	}{
+		{"postgres", "SELECT id, name FROM join_users TABLESAMPLE BERNOULLI (2.5) WHERE name = ? ORDER BY RANDOM()"},
+		{"sqlserver", "SELECT id, name FROM join_users TABLESAMPLE (2.5 PERCENT) WHERE name = ? ORDER BY NEWID()"},
+		{"mysql", "SELECT id, name FROM join_users WHERE (name = ?) AND RAND() < 0.025 ORDER BY RAND()"},
+		{"sqlite3", "SELECT id, name FROM join_users WHERE (name = ?) AND abs(random() % 1000000) < 25000 ORDER BY RANDOM()"},
+	}
+	for _, test := range tests {
+		tbl := (&Table{driverName: test.driver, name: "join_users", opts: &options{}}).Sample(2.5).OrderRandom()
+		query, _ := tbl.buildSelectQuery(info, "name = ?", []interface{}{"a"})
+		if query != test.expected {
+			t.Error(query)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package sql
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+)
+
+var (
+	_scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	_timeType    = reflect.TypeOf(time.Time{})
+)
+
+// isScalarType reports whether values of typ are scanned from a single column, e.g. int64, string, time.Time
+// and types implementing sql.Scanner, rather than mapped from columns by fields
+func isScalarType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() != reflect.Struct || typ == _timeType || reflect.PtrTo(typ).Implements(_scannerType)
+}
+
+// scalarInfo returns column info of the single column or expression selected into scalar destinations, e.g.
+//
+//	var ids []int64
+//	db.Table("users").Columns("id").Select(&ids, "age > ?", 18)
+//
+// Where clause isn't checked by columns of a struct, and isn't scoped by tenant
+func (t *Table) scalarInfo() *columnInfo {
+	if len(t.columns)+len(t.selectExprs) != 1 {
+		panic("scalar destination requires exactly one column or expression. please call Columns or SelectExpr")
+	}
+	return &columnInfo{
+		names:       append(append([]string(nil), t.columns...), t.selectExprs...),
+		exprs:       t.selectExprs,
+		nameToIndex: make(map[string]fieldIndex),
+	}
+}
+
+// scanScalar scans the single column of row into v, which must be addressable
+func scanScalar(row rowScanner, v reflect.Value, o *options) error {
+	if isArrayType(v.Type()) {
+		return row.Scan(Array(v.Addr().Interface()))
+	}
+
+	if !isTimeType(v.Type()) {
+		return row.Scan(v.Addr().Interface())
+	}
+
+	tv := &timeValue{}
+	if o != nil {
+		tv.loc = o.timeLocation
+	}
+	if err := row.Scan(tv); err != nil {
+		return err
+	}
+	tv.set(v)
+	return nil
+}
+
+// scanScalars appends values of the single column of rows to records, which is a pointer to slice of scalars
+func scanScalars(rows *sql.Rows, records interface{}, o *options) error {
+	v := reflect.ValueOf(records)
+	sliceType := v.Type().Elem()
+	if v.IsNil() {
+		v.Set(reflect.New(sliceType))
+	}
+
+	sliceValue := v.Elem()
+	for rows.Next() {
+		elem := reflect.New(sliceType.Elem())
+		if err := scanScalar(rows, elem.Elem(), o); err != nil {
+			return err
+		}
+		sliceValue = reflect.Append(sliceValue, elem.Elem())
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	v.Elem().Set(sliceValue)
+	return nil
+}
@@ -0,0 +1,37 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+func TestTable_Select_scalar(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("SELECT id FROM items").Returns([]string{"id"}, []interface{}{1}, []interface{}{2})
+	f.On("SELECT name FROM items").Returns([]string{"name"}, []interface{}{nil})
+
+	var ids []int64
+	if err := db.Table("items").Columns("id").Select(&ids, "id > ?", 0); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Error(ids)
+	}
+
+	var id int64
+	if err := db.Table("items").Columns("id").SelectOne(&id, "id = ?", 2); err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 {
+		t.Error(id)
+	}
+
+	name := new(string)
+	if err := db.Table("items").Columns("name").SelectOne(&name, ""); err != nil {
+		t.Fatal(err)
+	}
+	if name != nil {
+		t.Error(*name)
+	}
+}
@@ -0,0 +1,331 @@
+package sql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gopub/utils"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+type scanKind int
+
+const (
+	scanDirect scanKind = iota
+	scanJSON
+	scanNullInt
+	scanNullUint
+	scanNullBool
+	scanNullFloat
+	scanNullString
+	scanEncrypted
+	scanTime
+	scanArray
+	scanDiscard
+)
+
+type scanField struct {
+	index fieldIndex
+	typ   reflect.Type
+	kind  scanKind
+
+	// offset of the field in struct, which is valid if the field isn't behind a pointer
+	offset uintptr
+	inline bool
+
+	// column name and allowed values of enum column
+	column string
+	enum   []string
+}
+
+// scanPlan is precompiled scanning of columns into fields of a struct type
+type scanPlan struct {
+	fields []scanField
+}
+
+type scanPlanKey struct {
+	typ     reflect.Type
+	columns string
+}
+
+var _scanPlans = &sync.Map{} //scanPlanKey:*scanPlan
+
+func getScanPlan(typ reflect.Type, info *columnInfo) *scanPlan {
+	key := scanPlanKey{typ: typ, columns: strings.Join(info.names, ",")}
+	if p, ok := _scanPlans.Load(key); ok {
+		return p.(*scanPlan)
+	}
+
+	p := &scanPlan{fields: make([]scanField, len(info.indexes))}
+	for i, idx := range info.indexes {
+		if idx == nil {
+			// column without field
+			p.fields[i] = scanField{kind: scanDiscard}
+			continue
+		}
+
+		f := scanField{index: idx, inline: true}
+		t := typ
+		for j, x := range idx {
+			if j > 0 && t.Kind() == reflect.Ptr {
+				t = t.Elem()
+				f.inline = false
+			}
+			sf := t.Field(x)
+			f.offset += sf.Offset
+			t = sf.Type
+		}
+		f.typ = t
+		if enum, ok := info.enums[info.names[i]]; ok {
+			f.column, f.enum = info.names[i], enum
+		}
+
+		if utils.IndexOfString(info.jsonNames, info.names[i]) >= 0 {
+			f.kind = scanJSON
+		} else if utils.IndexOfString(info.encryptedNames, info.names[i]) >= 0 {
+			f.kind = scanEncrypted
+		} else if utils.IndexOfString(info.arrayNames, info.names[i]) >= 0 {
+			f.kind = scanArray
+		} else if isTimeType(t) {
+			f.kind = scanTime
+		} else if utils.IndexOfString(info.nullableNames, info.names[i]) >= 0 {
+			switch t.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				f.kind = scanNullInt
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				f.kind = scanNullUint
+			case reflect.Bool:
+				f.kind = scanNullBool
+			case reflect.Float32, reflect.Float64:
+				f.kind = scanNullFloat
+			case reflect.String:
+				f.kind = scanNullString
+			default:
+				// UUID and geometries scan NULL as zero
+				if !isUUIDType(t) && !isGeoType(t) {
+					panic("invalid nullable type" + fmt.Sprint(t))
+				}
+			}
+		}
+		p.fields[i] = f
+	}
+	_scanPlans.Store(key, p)
+	return p
+}
+
+// structScanner scans rows by plan, holders of json, encrypted, time and nullable columns are reused across rows
+type structScanner struct {
+	plan   *scanPlan
+	dests  []interface{}
+	cipher Cipher
+}
+
+// newStructScanner returns scanner of plan, o provides cipher of encrypted columns and location of time columns
+func newStructScanner(plan *scanPlan, o *options) *structScanner {
+	s := &structScanner{
+		plan:  plan,
+		dests: make([]interface{}, len(plan.fields)),
+	}
+	var loc *time.Location
+	if o != nil {
+		s.cipher = o.cipher
+		loc = o.timeLocation
+	}
+	for i, f := range plan.fields {
+		switch f.kind {
+		case scanTime:
+			s.dests[i] = &timeValue{loc: loc}
+		case scanJSON, scanEncrypted, scanArray:
+			s.dests[i] = new([]byte)
+		case scanNullInt, scanNullUint:
+			s.dests[i] = new(sql.NullInt64)
+		case scanNullBool:
+			s.dests[i] = new(sql.NullBool)
+		case scanNullFloat:
+			s.dests[i] = new(sql.NullFloat64)
+		case scanNullString:
+			s.dests[i] = new(sql.NullString)
+		case scanDiscard:
+			s.dests[i] = new(sql.RawBytes)
+		}
+	}
+	return s
+}
+
+// value returns the field of elem, whose address is base
+func (f *scanField) value(elem reflect.Value, base unsafe.Pointer) reflect.Value {
+	if f.inline {
+		return reflect.NewAt(f.typ, unsafe.Add(base, f.offset)).Elem()
+	}
+	return fieldByIndex(elem, f.index)
+}
+
+func (s *structScanner) scan(row rowScanner, elem reflect.Value) error {
+	base := elem.Addr().UnsafePointer()
+	for i := range s.plan.fields {
+		f := &s.plan.fields[i]
+		if f.kind != scanDirect {
+			continue
+		}
+
+		if f.inline {
+			s.dests[i] = reflect.NewAt(f.typ, unsafe.Add(base, f.offset)).Interface()
+		} else {
+			s.dests[i] = fieldByIndex(elem, f.index).Addr().Interface()
+		}
+	}
+
+	if err := row.Scan(s.dests...); err != nil {
+		return err
+	}
+
+	for i := range s.plan.fields {
+		f := &s.plan.fields[i]
+		switch v := s.dests[i].(type) {
+		case *[]byte:
+			switch {
+			case f.kind == scanJSON:
+				if err := json.Unmarshal(*v, f.value(elem, base).Addr().Interface()); err != nil {
+					return err
+				}
+			case f.kind == scanEncrypted && len(*v) > 0:
+				if err := decryptValue(s.cipher, *v, f.value(elem, base)); err != nil {
+					return err
+				}
+			case f.kind == scanArray:
+				fv := f.value(elem, base)
+				if *v == nil {
+					fv.Set(reflect.Zero(fv.Type()))
+				} else if err := parseArray(string(*v), fv); err != nil {
+					return err
+				}
+			}
+		case *sql.NullInt64:
+			if v.Valid {
+				if f.kind == scanNullUint {
+					f.value(elem, base).SetUint(uint64(v.Int64))
+				} else {
+					f.value(elem, base).SetInt(v.Int64)
+				}
+			}
+		case *sql.NullBool:
+			if v.Valid {
+				f.value(elem, base).SetBool(v.Bool)
+			}
+		case *sql.NullFloat64:
+			if v.Valid {
+				f.value(elem, base).SetFloat(v.Float64)
+			}
+		case *sql.NullString:
+			if v.Valid {
+				f.value(elem, base).SetString(v.String)
+			}
+		case *timeValue:
+			v.set(f.value(elem, base))
+		}
+
+		if len(f.enum) > 0 {
+			if err := checkEnum(f.column, f.enum, f.value(elem, base).String(), f.kind == scanNullString); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanStruct scans columns of info into fields of elem, which must be addressable, see newStructScanner
+func scanStruct(row rowScanner, elem reflect.Value, info *columnInfo, o *options) error {
+	return newStructScanner(getScanPlan(elem.Type(), info), o).scan(row, elem)
+}
+
+// getSliceElemType returns struct type of records' elements. records must be a pointer to slice of structs
+// or pointers to structs
+func getSliceElemType(records interface{}) reflect.Type {
+	elemType := sliceElemType(records)
+	if elemType.Kind() != reflect.Struct {
+		panic("slice element must be a struct or pointer to struct")
+	}
+	return elemType
+}
+
+// sliceElemType returns type of records' elements, or the pointed type if elements are pointers.
+// records must be a pointer to slice
+func sliceElemType(records interface{}) reflect.Type {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Ptr {
+		panic("must be a pointer to slice")
+	}
+
+	if v.IsNil() && !v.CanSet() {
+		panic("cannot be set value")
+	}
+
+	sliceType := v.Type().Elem()
+	if sliceType.Kind() != reflect.Slice {
+		panic("must be a pointer to slice")
+	}
+
+	elemType := sliceType.Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	return elemType
+}
+
+// scanRows appends rows to records which is checked by getSliceElemType
+func scanRows(rows *sql.Rows, records interface{}, info *columnInfo, o *options) error {
+	v := reflect.ValueOf(records)
+	sliceType := v.Type().Elem()
+	elemType := sliceType.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.New(sliceType))
+	}
+	sliceValue := v.Elem()
+	s := newStructScanner(getScanPlan(elemType, info), o)
+	for rows.Next() {
+		ptrToElem := utils.DeepNew(elemType)
+		elem := ptrToElem.Elem()
+		if err := s.scan(rows, elem); err != nil {
+			return err
+		}
+
+		if isPtr {
+			sliceValue = reflect.Append(sliceValue, ptrToElem)
+		} else {
+			sliceValue = reflect.Append(sliceValue, elem)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	v.Elem().Set(sliceValue)
+	return nil
+}
+
+// fieldByIndex returns the nested field of v like reflect.Value.FieldByIndex, but allocates nil pointers on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
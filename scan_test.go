@@ -0,0 +1,70 @@
+package sql
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+type ScanBase struct {
+	ID int64 `sql:"primary key"`
+}
+
+type scanRecord struct {
+	*ScanBase
+	Name  string   `sql:"nullable"`
+	Count uint     `sql:"nullable"`
+	Tags  []string `sql:"json"`
+	Score float64
+}
+
+func TestScanStruct(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(scanRecord{}))
+	row := fakeRow{int64(1), sql.NullString{String: "a", Valid: true}, sql.NullInt64{Int64: 2, Valid: true},
+		[]byte(`["x","y"]`), 1.5}
+	var v scanRecord
+	if err := scanStruct(row, reflect.ValueOf(&v).Elem(), info, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := scanRecord{ScanBase: &ScanBase{ID: 1}, Name: "a", Count: 2, Tags: []string{"x", "y"}, Score: 1.5}
+	if !reflect.DeepEqual(v, expected) {
+		t.Error(v)
+	}
+
+	// holders are reused, NULL leaves fields unchanged
+	s := newStructScanner(getScanPlan(reflect.TypeOf(v), info), nil)
+	row = fakeRow{int64(2), sql.NullString{}, sql.NullInt64{}, []byte(`[]`), 2.5}
+	var v2 scanRecord
+	if err := s.scan(row, reflect.ValueOf(&v2).Elem()); err != nil {
+		t.Fatal(err)
+	}
+
+	if v2.ID != 2 || v2.Name != "" || v2.Count != 0 || len(v2.Tags) != 0 || v2.Score != 2.5 {
+		t.Error(v2)
+	}
+
+	if getScanPlan(reflect.TypeOf(v), info) != s.plan {
+		t.Error("plan is not cached")
+	}
+}
+
+func BenchmarkScanStruct(b *testing.B) {
+	type record struct {
+		ID    int64 `sql:"primary key"`
+		Name  string
+		Price float64
+		Note  string `sql:"nullable"`
+	}
+
+	info := getColumnInfo(reflect.TypeOf(record{}))
+	row := fakeRow{int64(1), "apple", 1.5, sql.NullString{String: "fresh", Valid: true}}
+	s := newStructScanner(getScanPlan(reflect.TypeOf(record{}), info), nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var v record
+		if err := s.scan(row, reflect.ValueOf(&v).Elem()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package sql
+
+import (
+	"database/sql"
+	"github.com/gopub/log"
+	"github.com/gopub/utils"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ScanOptions maps result columns of raw queries onto fields of structs, see ScanRows
+type ScanOptions struct {
+	// Prefixes maps column prefixes to struct fields, e.g. {"u_": "User"} scans u_id and u_name into User.ID and User.Name
+	Prefixes map[string]string
+
+	// Suffixes maps column suffixes to struct fields like Prefixes, e.g. {"_u": "User"} scans id_u into User.ID
+	Suffixes map[string]string
+}
+
+// ScanRows appends rows of a raw query to records, whose columns are mapped onto fields by names.
+// Columns of joined tables can be aliased with prefixes or suffixes, which are stripped to scan them into struct fields
+// declared by opts, e.g.
+//
+//	type OrderUser struct {
+//		Order
+//		User *User
+//	}
+//
+//	rows, err := db.Query("SELECT o.*, u.id AS u_id, u.name AS u_name FROM orders o JOIN users u ON u.id = o.user_id")
+//	err = sql.ScanRows(rows, &list, &sql.ScanOptions{Prefixes: map[string]string{"u_": "User"}})
+//
+// Columns aliased with dots, e.g. "address.city", are scanned into nested struct fields, e.g. Address.City.
+// Nil pointers to structs are allocated as needed. Columns without fields are discarded, e.g. columns added to
+// tables of SELECT *
+func ScanRows(rows *sql.Rows, records interface{}, opts *ScanOptions) error {
+	return scanRowsByName(rows, records, opts, nil)
+}
+
+// QueryRecords executes query and scans its rows into records by column names, see ScanRows
+func (d *DB) QueryRecords(records interface{}, opts *ScanOptions, query string, args ...interface{}) error {
+	rows, err := d.Query(query, args...)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	defer rows.Close()
+
+	if err = scanRowsByName(rows, records, opts, d.opts); err != nil {
+		log.Error(err)
+		return err
+	}
+	return nil
+}
+
+func scanRowsByName(rows *sql.Rows, records interface{}, opts *ScanOptions, o *options) error {
+	elemType := getSliceElemType(records)
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	return scanRows(rows, records, getNamedColumnInfo(elemType, columns, opts), o)
+}
+
+// getNamedColumnInfo returns column info of typ whose names are columns in order, which are mapped by opts
+func getNamedColumnInfo(typ reflect.Type, columns []string, opts *ScanOptions) *columnInfo {
+	if opts == nil {
+		opts = &ScanOptions{}
+	}
+
+	info := &columnInfo{
+		nameToIndex: make(map[string]fieldIndex, len(columns)),
+	}
+	for _, c := range columns {
+		index, sub, name := resolveColumn(typ, c, opts)
+		if index == nil {
+			info.names = append(info.names, "-"+c)
+			info.indexes = append(info.indexes, nil)
+			continue
+		}
+
+		// path of the field distinguishes scan plans of the same columns mapped by different options
+		path := fieldPath(typ, index[:len(index)-len(sub.nameToIndex[name])]) + name
+		info.names = append(info.names, path)
+		info.indexes = append(info.indexes, index)
+		info.nameToIndex[path] = index
+
+		if utils.IndexOfString(sub.jsonNames, name) >= 0 {
+			info.jsonNames = append(info.jsonNames, path)
+		}
+
+		if utils.IndexOfString(sub.nullableNames, name) >= 0 {
+			info.nullableNames = append(info.nullableNames, path)
+		}
+
+		if utils.IndexOfString(sub.encryptedNames, name) >= 0 {
+			info.encryptedNames = append(info.encryptedNames, path)
+		}
+
+		if utils.IndexOfString(sub.arrayNames, name) >= 0 {
+			info.arrayNames = append(info.arrayNames, path)
+		}
+
+		if allowed, ok := sub.enums[name]; ok {
+			if info.enums == nil {
+				info.enums = make(map[string][]string)
+			}
+			info.enums[path] = allowed
+		}
+	}
+	return info
+}
+
+// resolveColumn returns index of the field of column in typ, with column info and name of the column in the struct holding it.
+// Index is nil if there's no field for column
+func resolveColumn(typ reflect.Type, column string, opts *ScanOptions) (fieldIndex, *columnInfo, string) {
+	for _, p := range sortedKeys(opts.Prefixes) {
+		if strings.HasPrefix(column, p) {
+			return resolveFieldColumn(typ, []string{opts.Prefixes[p]}, column[len(p):])
+		}
+	}
+
+	for _, s := range sortedKeys(opts.Suffixes) {
+		if strings.HasSuffix(column, s) {
+			return resolveFieldColumn(typ, []string{opts.Suffixes[s]}, column[:len(column)-len(s)])
+		}
+	}
+
+	if i := strings.LastIndex(column, "."); i > 0 {
+		return resolveFieldColumn(typ, strings.Split(column[:i], "."), column[i+1:])
+	}
+
+	info := getColumnInfo(typ)
+	name := strings.ToLower(column)
+	index, ok := info.nameToIndex[name]
+	if !ok {
+		return nil, nil, ""
+	}
+	return index, info, name
+}
+
+// resolveFieldColumn returns index of column in the struct field of typ at path, e.g. [Address] of address.city.
+// Fields of path are matched by their names or column names
+func resolveFieldColumn(typ reflect.Type, path []string, column string) (fieldIndex, *columnInfo, string) {
+	var index fieldIndex
+	for _, field := range path {
+		lower := strings.ToLower(field)
+		sf, ok := typ.FieldByNameFunc(func(name string) bool {
+			return name == field || naming().ColumnName(name) == lower
+		})
+		if !ok {
+			panic("no field " + field + " in " + typ.String())
+		}
+
+		index = append(index, sf.Index...)
+		typ = sf.Type
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			panic("not struct: " + strings.Join(path, "."))
+		}
+	}
+
+	sub := getColumnInfo(typ)
+	name := strings.ToLower(column)
+	i, ok := sub.nameToIndex[name]
+	if !ok {
+		return nil, nil, ""
+	}
+	return append(index, i...), sub, name
+}
+
+// fieldPath returns names of struct fields of index in typ followed by dots, e.g. User.
+func fieldPath(typ reflect.Type, index fieldIndex) string {
+	var b strings.Builder
+	for _, x := range index {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		f := typ.Field(x)
+		b.WriteString(f.Name)
+		b.WriteString(".")
+		typ = f.Type
+	}
+	return b.String()
+}
+
+// sortedKeys returns keys of m, longer keys go first so that the most specific prefix or suffix matches
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
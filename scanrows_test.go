@@ -0,0 +1,80 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestDB_QueryRecords_prefix(t *testing.T) {
+	type user struct {
+		ID   int64 `sql:"primary key"`
+		Name string
+	}
+	type itemUser struct {
+		fakeItem
+		User  *user
+		Owner user
+	}
+
+	db, f := gosqltest.New("mysql")
+	f.On("SELECT").Returns([]string{"id", "name", "u_id", "u_name", "id_owner"}, []interface{}{1, "a", 2, "Tom", 3})
+	var list []*itemUser
+	err := db.QueryRecords(&list, &sql.ScanOptions{
+		Prefixes: map[string]string{"u_": "User"},
+		Suffixes: map[string]string{"_owner": "Owner"},
+	}, "SELECT i.*, u.id AS u_id, u.name AS u_name, o.id AS id_owner FROM ...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != 1 || list[0].Name != "a" || list[0].User.ID != 2 || list[0].User.Name != "Tom" ||
+		list[0].Owner.ID != 3 {
+		t.Errorf("%+v", list[0])
+	}
+}
+
+func TestDB_QueryRecords_nested(t *testing.T) {
+	type geo struct {
+		Lat float64
+		Lng float64
+	}
+	type address struct {
+		City     string
+		Location *geo
+	}
+	type customer struct {
+		ID      int64 `sql:"primary key"`
+		Address *address
+	}
+
+	db, f := gosqltest.New("postgres")
+	f.On("SELECT").Returns([]string{"id", "address.city", "address.location.lat", "Address.Location.lng"},
+		[]interface{}{1, "Paris", 48.8, 2.3})
+	var list []customer
+	if err := db.QueryRecords(&list, nil, `SELECT id, city AS "address.city", ... FROM customers`); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Address == nil || list[0].Address.City != "Paris" || list[0].Address.Location == nil ||
+		list[0].Address.Location.Lat != 48.8 || list[0].Address.Location.Lng != 2.3 {
+		t.Errorf("%+v", list)
+	}
+}
+
+func TestDB_QueryRecords_unknownColumns(t *testing.T) {
+	type itemUser struct {
+		fakeItem
+		User *fakeItem
+	}
+
+	db, f := gosqltest.New("mysql")
+	f.On("SELECT").Returns([]string{"id", "name", "created_at", "u_id", "u_email"}, []interface{}{1, "a", 100, 2, "b@c"})
+	var list []*itemUser
+	err := db.QueryRecords(&list, &sql.ScanOptions{Prefixes: map[string]string{"u_": "User"}},
+		"SELECT i.*, u.id AS u_id, u.email AS u_email FROM ...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != 1 || list[0].Name != "a" || list[0].User == nil || list[0].User.ID != 2 {
+		t.Errorf("%+v", list)
+	}
+}
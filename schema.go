@@ -0,0 +1,264 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/gopub/log"
+	"github.com/gopub/utils"
+	"reflect"
+	"strings"
+)
+
+// Column describes a column of table in database
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool
+
+	// Default is nil if column has no default value
+	Default *string
+
+	// Key is PRI for primary key column, UNI for unique column, MUL for indexed column in mysql
+	Key string
+}
+
+// Tables returns names of tables in schema set by SetSchema, or in current database or schema
+func (d *DB) Tables() ([]string, error) {
+	schema := d.opts.schema
+	var query string
+	var args []interface{}
+	switch {
+	case d.driverName == "mysql":
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = " + d.schemaExpr(schema, "DATABASE()", &args) +
+			" AND table_type = 'BASE TABLE' ORDER BY table_name"
+	case isPostgres(d.driverName):
+		query = "SELECT table_name FROM information_schema.tables WHERE table_schema = " + d.schemaExpr(schema, "current_schema()", &args) +
+			" AND table_type = 'BASE TABLE' ORDER BY table_name"
+		query = rebind(query)
+	case d.driverName == "sqlite3":
+		master := "sqlite_master"
+		if len(schema) > 0 {
+			master = quoteIdent(d.driverName, schema) + ".sqlite_master"
+		}
+		query = "SELECT name FROM " + master + " WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	default:
+		panic("Tables is not supported for driver: " + d.driverName)
+	}
+
+	log.Debug(query, args)
+	rows, err := d.Query(query, args...)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// Columns returns columns of table in the order of definition. table can be qualified by schema, e.g. analytics.events
+func (d *DB) Columns(table string) ([]*Column, error) {
+	schema, table := splitQualified(d.opts.qualify(table))
+	var query string
+	var args []interface{}
+	switch {
+	case d.driverName == "mysql":
+		query = `SELECT column_name, column_type, is_nullable = 'YES', column_default, column_key
+FROM information_schema.columns WHERE table_schema = ` + d.schemaExpr(schema, "DATABASE()", &args) +
+			` AND table_name = ? ORDER BY ordinal_position`
+	case isPostgres(d.driverName):
+		query = `SELECT c.column_name, c.data_type, c.is_nullable = 'YES', c.column_default,
+COALESCE((SELECT CASE tc.constraint_type WHEN 'PRIMARY KEY' THEN 'PRI' ELSE 'UNI' END
+	FROM information_schema.key_column_usage k JOIN information_schema.table_constraints tc
+	ON tc.constraint_name = k.constraint_name AND tc.table_schema = k.table_schema
+	WHERE k.table_schema = c.table_schema AND k.table_name = c.table_name AND k.column_name = c.column_name
+	AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE') ORDER BY tc.constraint_type LIMIT 1), '')
+FROM information_schema.columns c WHERE c.table_schema = ` + d.schemaExpr(schema, "current_schema()", &args) +
+			` AND c.table_name = ? ORDER BY c.ordinal_position`
+		query = rebind(query)
+	case d.driverName == "sqlite3":
+		query = `SELECT name, type, "notnull" = 0, dflt_value, CASE WHEN pk > 0 THEN 'PRI' ELSE '' END FROM pragma_table_info(?`
+		if len(schema) > 0 {
+			query += ", ?"
+		}
+		query += ") ORDER BY cid"
+		args = append(args, table)
+		if len(schema) > 0 {
+			args = append(args, schema)
+		}
+	default:
+		panic("Columns is not supported for driver: " + d.driverName)
+	}
+
+	if d.driverName != "sqlite3" {
+		args = append(args, table)
+	}
+
+	log.Debug(query, args)
+	rows, err := d.Query(query, args...)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []*Column
+	for rows.Next() {
+		c := &Column{}
+		var def sql.NullString
+		if err = rows.Scan(&c.Name, &c.Type, &c.Nullable, &def, &c.Key); err != nil {
+			log.Error(err)
+			return nil, err
+		}
+
+		if def.Valid {
+			c.Default = &def.String
+		}
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+// schemaExpr returns placeholder of schema which is appended to args, or current if schema is empty
+func (d *DB) schemaExpr(schema, current string, args *[]interface{}) string {
+	if len(schema) == 0 {
+		return current
+	}
+	*args = append(*args, schema)
+	return "?"
+}
+
+// ModelError lists differences between struct and its table
+type ModelError struct {
+	Table    string
+	Problems []string
+}
+
+func (e *ModelError) Error() string {
+	return "model of " + e.Table + " mismatches: " + strings.Join(e.Problems, "; ")
+}
+
+// ValidateModel compares columns mapped by record with the live table,
+// and returns *ModelError if there are missing columns, type mismatches or nullability conflicts
+func (d *DB) ValidateModel(record interface{}) error {
+	table := getTableName(record)
+	columns, err := d.Columns(table)
+	if err != nil {
+		return err
+	}
+
+	if len(columns) == 0 {
+		return &ModelError{Table: table, Problems: []string{"table doesn't exist"}}
+	}
+
+	if problems := compareModel(getStructValue(record).Type(), columns); len(problems) > 0 {
+		return &ModelError{Table: table, Problems: problems}
+	}
+	return nil
+}
+
+func compareModel(typ reflect.Type, columns []*Column) []string {
+	info := getColumnInfo(typ)
+	nameToColumn := make(map[string]*Column, len(columns))
+	for _, c := range columns {
+		nameToColumn[strings.ToLower(c.Name)] = c
+	}
+
+	var problems []string
+	for _, name := range info.names {
+		c, ok := nameToColumn[name]
+		if !ok {
+			problems = append(problems, "missing column "+name)
+			continue
+		}
+
+		isJSON := utils.IndexOfString(info.jsonNames, name) >= 0
+		ft := typ.FieldByIndex(info.nameToIndex[name]).Type
+		if !isCompatibleType(ft, isJSON, c.Type) {
+			problems = append(problems, fmt.Sprintf("column %s is %s, but field is %s", name, c.Type, ft))
+		}
+
+		nullable := utils.IndexOfString(info.nullableNames, name) >= 0
+		if c.Nullable && !nullable {
+			problems = append(problems, "column "+name+" is nullable, but field isn't tagged nullable")
+		} else if !c.Nullable && nullable && c.Default == nil {
+			problems = append(problems, "column "+name+" is not null, but field is tagged nullable")
+		}
+	}
+
+	for _, c := range columns {
+		name := strings.ToLower(c.Name)
+		if _, ok := info.nameToIndex[name]; !ok && !c.Nullable && c.Default == nil && !strings.Contains(c.Key, "PRI") {
+			problems = append(problems, "column "+name+" is not null without default, but isn't mapped")
+		}
+	}
+	return problems
+}
+
+func isCompatibleType(typ reflect.Type, isJSON bool, dbType string) bool {
+	family := typeFamily(dbType)
+	if len(family) == 0 {
+		return true
+	}
+
+	if isJSON {
+		return family == "json" || family == "string" || family == "bytes"
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return family == "bool" || family == "int"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return family == "int"
+	case reflect.Float32, reflect.Float64:
+		return family == "float" || family == "int"
+	case reflect.String:
+		return family == "string" || family == "json" || family == "time"
+	default:
+		if typ.ConvertibleTo(_bytesType) {
+			return family == "bytes" || family == "string" || family == "json"
+		}
+	}
+	return true
+}
+
+// typeFamily returns the family of database column type, or empty string if unknown
+func typeFamily(dbType string) string {
+	t := strings.ToLower(dbType)
+	switch {
+	case strings.Contains(t, "bool"):
+		return "bool"
+	case strings.Contains(t, "point"):
+		return ""
+	case strings.Contains(t, "int") && !strings.Contains(t, "interval") || strings.Contains(t, "serial"):
+		return "int"
+	case strings.Contains(t, "float"), strings.Contains(t, "double"), strings.Contains(t, "real"),
+		strings.Contains(t, "decimal"), strings.Contains(t, "numeric"):
+		return "float"
+	case strings.Contains(t, "json"):
+		return "json"
+	case strings.Contains(t, "char"), strings.Contains(t, "text"), strings.Contains(t, "enum"),
+		strings.Contains(t, "set"), strings.Contains(t, "uuid"), strings.Contains(t, "clob"):
+		return "string"
+	case strings.Contains(t, "blob"), strings.Contains(t, "binary"), strings.Contains(t, "bytea"):
+		return "bytes"
+	case strings.Contains(t, "date"), strings.Contains(t, "time"):
+		return "time"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,29 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareModel(t *testing.T) {
+	def := "0"
+	columns := []*Column{
+		{Name: "id", Type: "bigint(20)", Key: "PRI"},
+		{Name: "name", Type: "int(11)"},
+		{Name: "price", Type: "double", Nullable: true},
+		{Name: "note", Type: "varchar(255)", Default: &def},
+		{Name: "tags", Type: "json"},
+		{Name: "created_at", Type: "bigint(20)"},
+	}
+
+	problems := compareModel(reflect.TypeOf(ddlProduct{}), columns)
+	expected := []string{
+		"column name is int(11), but field is string",
+		"missing column code",
+		"column price is nullable, but field isn't tagged nullable",
+		"column created_at is not null without default, but isn't mapped",
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Error(problems)
+	}
+}
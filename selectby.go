@@ -0,0 +1,47 @@
+package sql
+
+import (
+	"github.com/gopub/log"
+	"github.com/gopub/utils"
+	"strings"
+)
+
+// SelectBy selects rows matching non-zero fields of example by equality, e.g. query by example of admin list filters.
+// All rows are selected if all fields are zero. Encrypted fields can't be matched and must be zero
+func (t *Table) SelectBy(records interface{}, example interface{}) error {
+	where, args, err := t.exampleWhere(example)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	return t.Select(records, where, args...)
+}
+
+func (d *DB) SelectBy(records interface{}, example interface{}) error {
+	return d.Table(getTableNameBySlice(records)).SelectBy(records, example)
+}
+
+// exampleWhere returns where clause of equality conditions of non-zero fields of example
+func (t *Table) exampleWhere(example interface{}) (string, []interface{}, error) {
+	v := getStructValue(example)
+	info := getColumnInfo(v.Type())
+	var conds []string
+	var args []interface{}
+	for _, name := range info.names {
+		if name == t.tenantColumn(info) || v.FieldByIndex(info.nameToIndex[name]).IsZero() {
+			continue
+		}
+
+		if utils.IndexOfString(info.encryptedNames, name) >= 0 {
+			panic("encrypted column can't be matched: " + name)
+		}
+
+		value, err := t.getFieldValueByName(v, info, name, false)
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, t.quote(name)+" = ?")
+		args = append(args, value)
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
@@ -0,0 +1,28 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+func TestTable_SelectBy(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a"})
+	var items []*fakeItem
+	if err := db.SelectBy(&items, &fakeItem{Name: "a"}); err != nil || len(items) != 1 {
+		t.Fatal(err, items)
+	}
+
+	s := f.LastStatement()
+	if s.Query != "SELECT id, name FROM fake_items WHERE name = $1" || !reflect.DeepEqual(s.Args, []interface{}{"a"}) {
+		t.Error(s)
+	}
+
+	if err := db.Table("fake_items").SelectBy(&items, fakeItem{}); err != nil {
+		t.Fatal(err)
+	}
+	if s = f.LastStatement(); s.Query != "SELECT id, name FROM fake_items" {
+		t.Error(s)
+	}
+}
@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"strings"
+)
+
+// SelectExpr appends sql expressions to columns selected by Select, SelectOne and Iterate, e.g.
+//
+//	type UserStat struct {
+//		ID        int64
+//		NameLower string `sql:"name_lower,computed"`
+//		N         int    `sql:"n,computed"`
+//	}
+//
+//	db.Table("users").Columns("id").SelectExpr("lower(name) AS name_lower", "count(*) AS n").GroupBy("id").Select(&stats, "")
+//
+// Expressions are scanned into fields of columns or computed fields named by their aliases, and computed fields are
+// neither written nor created. An expression without alias can be selected into a scalar destination alone
+func (t *Table) SelectExpr(exprs ...string) *Table {
+	for _, e := range exprs {
+		if len(strings.TrimSpace(e)) == 0 || strings.Contains(e, ";") || strings.Contains(e, "--") || strings.Contains(e, "/*") {
+			panic("invalid expression: " + e)
+		}
+	}
+	c := *t
+	c.selectExprs = exprs
+	return &c
+}
+
+// exprAlias returns lower case alias of expression e, e.g. n of count(*) AS n, or empty string if there's no alias
+func exprAlias(e string) string {
+	i := strings.LastIndex(strings.ToLower(e), " as ")
+	if i < 0 {
+		return ""
+	}
+
+	alias := strings.TrimSpace(e[i+len(" as "):])
+	if len(alias) > 2 && strings.ContainsAny(alias[:1], "\"`[") {
+		alias = alias[1 : len(alias)-1]
+	}
+
+	for j := 0; j < len(alias); j++ {
+		if !isIdentChar(alias[j]) {
+			return ""
+		}
+	}
+	return strings.ToLower(alias)
+}
+
+// withExprs returns info of columns of info followed by expressions, which are scanned into fields named by aliases
+func (info *columnInfo) withExprs(exprs []string) *columnInfo {
+	sub := info.subset(info.names)
+	sub.exprs = exprs
+	for _, e := range exprs {
+		alias := exprAlias(e)
+		idx, ok := info.nameToIndex[alias]
+		if !ok {
+			idx, ok = info.computed[alias]
+		}
+
+		if !ok {
+			panic("no field for expression: " + e)
+		}
+		sub.indexes = append(sub.indexes, idx)
+		sub.names = append(sub.names, alias)
+	}
+	return sub
+}
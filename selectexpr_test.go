@@ -0,0 +1,31 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestTable_SelectExpr(t *testing.T) {
+	type itemStat struct {
+		Name  string
+		Total int64 `sql:"total,computed"`
+	}
+
+	db, f := gosqltest.New("mysql")
+	f.On("SELECT name, sum(price) AS total FROM items").Returns([]string{"name", "total"}, []interface{}{"a", 3})
+	f.On("SELECT count(*) FROM items").Returns([]string{"count(*)"}, []interface{}{2})
+
+	var stats []*itemStat
+	err := db.Table("items").Columns("name").SelectExpr("sum(price) AS total").GroupBy("name").Select(&stats, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || *stats[0] != (itemStat{Name: "a", Total: 3}) {
+		t.Error(stats)
+	}
+
+	var n int
+	if err := db.Table("items").SelectExpr("count(*)").SelectOne(&n, ""); err != nil || n != 2 {
+		t.Error(err, n)
+	}
+}
@@ -0,0 +1,123 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gopub/log"
+)
+
+// Session runs statements on a single connection, e.g. for SET statements, temporary tables or session level locks.
+// It must be closed to release the connection
+type Session struct {
+	conn       *sql.Conn
+	exe        Executor
+	db         *sql.DB
+	ctx        context.Context
+	driverName string
+	opts       *options
+}
+
+// Session checks out a connection of the primary database, statements of the session are executed with ctx
+func (d *DB) Session(ctx context.Context) (*Session, error) {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return &Session{
+		conn:       conn,
+		exe:        d.opts.wrap(conn),
+		db:         d.db,
+		ctx:        ctx,
+		driverName: d.driverName,
+		opts:       d.opts,
+	}, nil
+}
+
+// WithSession runs fn in a session, whose connection is released after fn returns
+func (d *DB) WithSession(ctx context.Context, fn func(s *Session) error) error {
+	s, err := d.Session(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return fn(s)
+}
+
+// Close returns the connection to pool. Session variables and temporary tables are kept by the connection,
+// which should be reset by the caller if they matter to other users of the pool
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+// Context returns the context which the session is checked out with
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Session) Table(name string) *Table {
+	return &Table{
+		exe:        s.exe,
+		reader:     s.exe,
+		db:         s.db,
+		conn:       s.conn,
+		driverName: s.driverName,
+		name:       s.opts.qualify(name),
+		opts:       s.opts,
+		ctx:        s.ctx,
+	}
+}
+
+// BeginTx begins a transaction on the connection of the session
+func (s *Session) BeginTx(opts *TxOptions) (*Tx, error) {
+	tx, err := s.conn.BeginTx(s.ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTx(tx, s.ctx, s.driverName, s.opts), nil
+}
+
+func (s *Session) Exec(query string, args ...interface{}) (sql.Result, error) {
+	s.opts.logQuery(s.driverName, query, args)
+	ctx, cancel := s.opts.context(s.ctx)
+	defer cancel()
+	return s.exe.ExecContext(ctx, query, args...)
+}
+
+// Query executes a query in context of s, the default query timeout isn't applied like DB.Query
+func (s *Session) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	s.opts.logQuery(s.driverName, query, args)
+	return s.exe.QueryContext(s.ctx, query, args...)
+}
+
+// QueryRow executes a query in context of s, the default query timeout isn't applied like DB.Query
+func (s *Session) QueryRow(query string, args ...interface{}) *sql.Row {
+	s.opts.logQuery(s.driverName, query, args)
+	return s.exe.QueryRowContext(s.ctx, query, args...)
+}
+
+func (s *Session) Insert(record interface{}) error {
+	return s.Table(getTableName(record)).Insert(record)
+}
+
+func (s *Session) Update(record interface{}) error {
+	return s.Table(getTableName(record)).Update(record)
+}
+
+func (s *Session) Delete(record interface{}) error {
+	return s.Table(getTableName(record)).DeleteRecord(record)
+}
+
+func (s *Session) Select(records interface{}, where string, args ...interface{}) error {
+	return s.Table(getTableNameBySlice(records)).Select(records, where, args...)
+}
+
+func (s *Session) SelectOne(record interface{}, where string, args ...interface{}) error {
+	return s.Table(getTableName(record)).SelectOne(record, where, args...)
+}
+
+func (s *Session) Get(record interface{}, pk ...interface{}) error {
+	return s.Table(getTableName(record)).Get(record, pk...)
+}
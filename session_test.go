@@ -0,0 +1,47 @@
+package sql_test
+
+import (
+	"context"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDB_WithSession(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	err := db.WithSession(context.Background(), func(s *sql.Session) error {
+		if _, err := s.Exec("SET search_path TO tenant_1"); err != nil {
+			return err
+		}
+
+		var items []*fakeItem
+		if err := s.Table("items").MaxExecutionTime(time.Second).Select(&items, "id > ?", 0); err != nil {
+			return err
+		}
+
+		tx, err := s.BeginTx(nil)
+		if err != nil {
+			return err
+		}
+		if err = tx.Table("items").Insert(&fakeItem{ID: 1, Name: "a"}); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var queries []string
+	for _, s := range f.Statements() {
+		queries = append(queries, s.Query)
+	}
+	expected := []string{"SET search_path TO tenant_1", "BEGIN", "SET LOCAL statement_timeout = 1000",
+		"SELECT id, name FROM items WHERE id > $1", "COMMIT", "BEGIN", "INSERT INTO items(id, name) VALUES ($1, $2)", "COMMIT"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Error(queries)
+	}
+}
@@ -0,0 +1,233 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ShardStrategy returns index of the shard in [0, n) holding rows of shard key
+type ShardStrategy func(key interface{}, n int) int
+
+// HashSharding distributes keys by FNV-1a hash of their string form
+func HashSharding(key interface{}, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(fmt.Sprint(key)))
+	return int(h.Sum32() % uint32(n))
+}
+
+// RangeSharding returns a strategy of integer keys, shard i holds keys less than bounds[i]
+// and not less than bounds[i-1], the last shard holds keys not less than the last bound,
+// so n shards need n-1 ascending bounds
+func RangeSharding(bounds ...int64) ShardStrategy {
+	if !sort.SliceIsSorted(bounds, func(i, j int) bool { return bounds[i] < bounds[j] }) {
+		panic("bounds must be ascending")
+	}
+
+	return func(key interface{}, n int) int {
+		if len(bounds) != n-1 {
+			panic(fmt.Sprintf("%d bounds for %d shards", len(bounds), n))
+		}
+
+		v := reflect.ValueOf(key)
+		if !v.Type().ConvertibleTo(_int64Type) {
+			panic("not integer shard key: " + v.Type().String())
+		}
+		k := v.Convert(_int64Type).Int()
+		return sort.Search(len(bounds), func(i int) bool { return k < bounds[i] })
+	}
+}
+
+// ShardedDB routes statements of records to one of shards by shard key column.
+// Insert, Update, Save and Delete use shard key of record. Select and SelectOne are routed
+// if where has condition key=? without OR, otherwise they are scattered to all shards
+type ShardedDB struct {
+	shards   []*DB
+	key      string
+	strategy ShardStrategy
+}
+
+// NewShardedDB returns a ShardedDB of shards, whose rows are located by column key and strategy
+func NewShardedDB(key string, strategy ShardStrategy, shards ...*DB) *ShardedDB {
+	if len(shards) == 0 {
+		panic("no shards")
+	}
+
+	if strategy == nil {
+		panic("strategy is nil")
+	}
+
+	return &ShardedDB{
+		shards:   shards,
+		key:      key,
+		strategy: strategy,
+	}
+}
+
+// Shards returns all shards
+func (s *ShardedDB) Shards() []*DB {
+	return s.shards
+}
+
+// Shard returns the shard holding rows of shard key
+func (s *ShardedDB) Shard(key interface{}) *DB {
+	i := s.strategy(key, len(s.shards))
+	if i < 0 || i >= len(s.shards) {
+		panic(fmt.Sprintf("shard %d is out of range", i))
+	}
+	return s.shards[i]
+}
+
+// shardOf returns the shard of record by its shard key field
+func (s *ShardedDB) shardOf(record interface{}) *DB {
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	info := getColumnInfo(v.Type())
+	index, ok := info.nameToIndex[s.key]
+	if !ok {
+		panic("no shard key " + s.key + " in " + v.Type().String())
+	}
+	return s.Shard(v.FieldByIndex(index).Interface())
+}
+
+func (s *ShardedDB) Insert(record interface{}) error {
+	return s.shardOf(record).Insert(record)
+}
+
+func (s *ShardedDB) Update(record interface{}) error {
+	return s.shardOf(record).Update(record)
+}
+
+func (s *ShardedDB) Save(record interface{}) error {
+	return s.shardOf(record).Save(record)
+}
+
+func (s *ShardedDB) Delete(record interface{}) error {
+	return s.shardOf(record).Delete(record)
+}
+
+// Select selects from the shard of shard key in where, or from all shards concurrently if where has no shard key.
+// Scattered results are appended in order of shards, so ORDER BY and LIMIT apply to each shard
+func (s *ShardedDB) Select(records interface{}, where string, args ...interface{}) error {
+	if key, ok := s.whereKey(where, args); ok {
+		return s.Shard(key).Select(records, where, args...)
+	}
+	return s.SelectAll(records, where, args...)
+}
+
+// SelectAll selects from all shards concurrently, and appends results in order of shards
+func (s *ShardedDB) SelectAll(records interface{}, where string, args ...interface{}) error {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		panic("must be a pointer to slice")
+	}
+
+	results := make([]reflect.Value, len(s.shards))
+	errs := make([]error, len(s.shards))
+	var wg sync.WaitGroup
+	for i, shard := range s.shards {
+		wg.Add(1)
+		go func(i int, shard *DB) {
+			defer wg.Done()
+			results[i] = reflect.New(v.Elem().Type())
+			errs[i] = shard.Select(results[i].Interface(), where, args...)
+		}(i, shard)
+	}
+	wg.Wait()
+
+	l := v.Elem()
+	for i, err := range errs {
+		if err != nil {
+			return err
+		}
+		l = reflect.AppendSlice(l, results[i].Elem())
+	}
+	v.Elem().Set(l)
+	return nil
+}
+
+// SelectOne selects from the shard of shard key in where, or from shards in order until a row is found
+func (s *ShardedDB) SelectOne(record interface{}, where string, args ...interface{}) error {
+	if key, ok := s.whereKey(where, args); ok {
+		return s.Shard(key).SelectOne(record, where, args...)
+	}
+
+	for _, shard := range s.shards {
+		err := shard.SelectOne(record, where, args...)
+		if !errors.Is(err, ErrNoRows) {
+			return err
+		}
+	}
+	return ErrNoRows
+}
+
+// Close closes all shards and returns the first error
+func (s *ShardedDB) Close() error {
+	var first error
+	for _, shard := range s.shards {
+		if err := shard.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// whereKey returns arg of condition key=? or key=$n in where, where with OR isn't routed
+func (s *ShardedDB) whereKey(where string, args []interface{}) (interface{}, bool) {
+	if keywordIndex(where, "or") >= 0 {
+		return nil, false
+	}
+
+	var key interface{}
+	found := false
+	n := 0
+	walkSQL(where, func(i int, c byte) int {
+		if found {
+			return i + 1
+		}
+
+		switch {
+		case c == '?':
+			n++
+			return i + 1
+		case !isIdentChar(c) || i > 0 && isIdentChar(where[i-1]):
+			return i + 1
+		}
+
+		j := i
+		for j < len(where) && isIdentChar(where[j]) {
+			j++
+		}
+		if !strings.EqualFold(where[i:j], s.key) {
+			return j
+		}
+
+		rest := strings.TrimLeft(where[j:], " \t\n")
+		if !strings.HasPrefix(rest, "=") {
+			return j
+		}
+		rest = strings.TrimLeft(rest[1:], " \t\n")
+		switch {
+		case strings.HasPrefix(rest, "?") && n < len(args):
+			key, found = args[n], true
+		case strings.HasPrefix(rest, "$"):
+			k := 1
+			for k < len(rest) && rest[k] >= '0' && rest[k] <= '9' {
+				k++
+			}
+			if p, err := strconv.Atoi(rest[1:k]); err == nil && p >= 1 && p <= len(args) {
+				key, found = args[p-1], true
+			}
+		}
+		return j
+	}, func(c byte) {})
+	return key, found
+}
@@ -0,0 +1,38 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestShardedDB(t *testing.T) {
+	db0, f0 := gosqltest.New("mysql")
+	db1, f1 := gosqltest.New("mysql")
+	s := sql.NewShardedDB("id", sql.RangeSharding(100), db0, db1)
+
+	if err := s.Insert(&fakeItem{ID: 150, Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(f0.Statements()) != 0 || len(f1.Statements()) != 1 {
+		t.Fatal(f0.Statements(), f1.Statements())
+	}
+
+	f0.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a"})
+	var item fakeItem
+	if err := s.SelectOne(&item, "name=? AND id = ?", "a", 1); err != nil || item.ID != 1 {
+		t.Fatal(err, item)
+	}
+	if len(f0.Statements()) != 1 || len(f1.Statements()) != 1 {
+		t.Fatal(f0.Statements(), f1.Statements())
+	}
+
+	f1.On("SELECT").Returns([]string{"id", "name"}, []interface{}{100, "b"}, []interface{}{101, "b"})
+	var items []*fakeItem
+	if err := s.Select(&items, "name=? OR id=?", "b", 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[0].ID != 1 || items[2].ID != 101 {
+		t.Error(items)
+	}
+}
@@ -0,0 +1,37 @@
+package sql
+
+import "testing"
+
+func TestHashSharding(t *testing.T) {
+	if HashSharding(int64(42), 4) != HashSharding(int64(42), 4) || HashSharding("a", 1) != 0 {
+		t.Error("unstable hash")
+	}
+
+	s := RangeSharding(10, 20)
+	if s(5, 3) != 0 || s(10, 3) != 1 || s(int32(25), 3) != 2 {
+		t.Error(s(5, 3), s(10, 3), s(25, 3))
+	}
+}
+
+func TestShardedDB_whereKey(t *testing.T) {
+	s := &ShardedDB{key: "user_id"}
+	tests := []struct {
+		where string
+		args  []interface{}
+		key   interface{}
+		ok    bool
+	}{
+		{"name=? AND user_id = ?", []interface{}{"a", 7}, 7, true},
+		{"user_id=$2 AND name=$1", []interface{}{"a", 8}, 8, true},
+		{"name='user_id=?' AND user_id=?", []interface{}{9}, 9, true},
+		{"owner_user_id=?", []interface{}{1}, nil, false},
+		{"user_id IN (?)", []interface{}{1}, nil, false},
+		{"user_id=? OR name=?", []interface{}{1, "a"}, nil, false},
+	}
+	for _, test := range tests {
+		key, ok := s.whereKey(test.where, test.args)
+		if key != test.key || ok != test.ok {
+			t.Error(test.where, key, ok)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gopub/log"
+)
+
+// ErrNoRowsAffected is returned by UpdateStrict and DeleteStrict if no row is changed
+var ErrNoRowsAffected = errors.New("no rows affected")
+
+// ErrTooManyRowsAffected is returned by UpdateOne and DeleteOne if more than one row matches, whose changes are rolled back
+var ErrTooManyRowsAffected = errors.New("too many rows affected")
+
+// UpdateStrict updates record like Update, and returns ErrNoRowsAffected if no row is updated.
+// Mysql counts changed rows rather than matched rows unless clientFoundRows=true is set in data source name
+func (t *Table) UpdateStrict(record interface{}) error {
+	return checkAffected(t.update(record))
+}
+
+// DeleteStrict deletes like Delete, and returns ErrNoRowsAffected if no row is deleted
+func (t *Table) DeleteStrict(where string, args ...interface{}) error {
+	return checkAffected(t.delete(where, args))
+}
+
+// UpdateOne updates like UpdateColumnsMap in a transaction, which is rolled back with ErrTooManyRowsAffected
+// if more than one row matches where
+func (t *Table) UpdateOne(values map[string]interface{}, where string, args ...interface{}) error {
+	return t.withTx(func(c *Table) error {
+		return checkAtMostOne(c.updateColumnsMap(values, where, args))
+	})
+}
+
+// DeleteOne deletes like Delete in a transaction, which is rolled back with ErrTooManyRowsAffected
+// if more than one row matches where
+func (t *Table) DeleteOne(where string, args ...interface{}) error {
+	return t.withTx(func(c *Table) error {
+		return checkAtMostOne(c.delete(where, args))
+	})
+}
+
+func checkAffected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if n == 0 {
+		return ErrNoRowsAffected
+	}
+	return nil
+}
+
+func checkAtMostOne(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if n > 1 {
+		return ErrTooManyRowsAffected
+	}
+	return nil
+}
+
+func (d *DB) UpdateStrict(record interface{}) error {
+	return d.Table(getTableName(record)).UpdateStrict(record)
+}
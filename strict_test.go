@@ -0,0 +1,38 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestTable_UpdateStrict(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	f.On("UPDATE").Affects(0, 0).Once()
+	if err := db.UpdateStrict(&fakeItem{ID: 1, Name: "a"}); err != sql.ErrNoRowsAffected {
+		t.Error(err)
+	}
+
+	f.On("DELETE").Affects(0, 1).Once()
+	if err := db.Table("items").DeleteStrict("id = ?", 1); err != nil {
+		t.Error(err)
+	}
+
+	f.On("DELETE").Affects(0, 2).Once()
+	if err := db.Table("items").DeleteOne("name = ?", "a"); err != sql.ErrTooManyRowsAffected {
+		t.Error(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "ROLLBACK" {
+		t.Error(s.Query)
+	}
+
+	f.On("UPDATE").Affects(0, 1).Once()
+	if err := db.Table("items").UpdateOne(map[string]interface{}{"name": "b"}, "name = ?", "a"); err != nil {
+		t.Error(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "COMMIT" {
+		t.Error(s.Query)
+	}
+}
@@ -1,21 +1,35 @@
 package sql
 
 import (
-	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"github.com/gopub/log"
 	"github.com/gopub/utils"
-	"github.com/jinzhu/inflection"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type tableNaming interface {
 	TableName() string
 }
 
+// TableNameOf returns table name of record, or of elements of records which is a pointer to slice,
+// e.g. for wrappers of DB which select tables of records
+func TableNameOf(v interface{}) string {
+	typ := reflect.TypeOf(v)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() == reflect.Slice {
+		return getTableNameBySlice(v)
+	}
+	return getTableName(v)
+}
+
 func getTableName(record interface{}) string {
 	if n, ok := record.(tableNaming); ok {
 		return n.TableName()
@@ -57,7 +71,7 @@ func getTableNameByType(typ reflect.Type) string {
 		//return reflect.Zero(reflect.PtrTo(typ)).Interface().(tableNaming).TableName()
 	}
 
-	return inflection.Plural(utils.CamelToSnake(typ.Name()))
+	return naming().TableName(typ.Name())
 }
 
 func isEmpty(jsonData []byte) bool {
@@ -66,9 +80,186 @@ func isEmpty(jsonData []byte) bool {
 }
 
 type Table struct {
-	exe        executor
+	exe        Executor
+	reader     Executor
 	driverName string
 	name       string
+	opts       *options
+
+	// db is the primary database, and tx is the transaction of t if there is one.
+	// They're used by statements which can't be executed by Executor, e.g. COPY
+	db *sql.DB
+	tx *sql.Tx
+
+	// conn is the connection of Session, transactions of t are begun on it
+	conn *sql.Conn
+
+	// ctx is parent context of statements, e.g. context of Tx
+	ctx context.Context
+
+	timeout time.Duration
+	comment string
+
+	orderBy []string
+	limit   int
+	offset  int
+
+	// columns selected by Select and SelectOne, default is all columns
+	columns []string
+
+	distinct   bool
+	groupBy    []string
+	having     string
+	havingArgs []interface{}
+
+	joins []string
+
+	// qualified prefixes selected columns with alias of t, see As
+	qualified bool
+
+	// samplePercent is the percent of rows sampled by Select and Count, see Sample
+	samplePercent float64
+
+	// source is selected instead of table name if it's not nil, see DB.From
+	source *Expression
+
+	// asOf is the time of system-versioned table to select, see AsOf
+	asOf *time.Time
+
+	// fields of relations loaded by Select and SelectOne
+	preloads []string
+
+	// lock is UPDATE or SHARE
+	lock       string
+	skipLocked bool
+
+	// unscoped disables tenant scope
+	unscoped bool
+
+	// results of Select and SelectOne are cached for cacheTTL if it's positive, see Cache
+	cacheTTL    time.Duration
+	cacheTables []string
+
+	// fetchSize is number of rows fetched per round trip by Iterate, see FetchSize
+	fetchSize int
+
+	// selectExprs are selected after columns, see SelectExpr
+	selectExprs []string
+
+	// ctes are common tables declared by WITH clause, see With
+	ctes []commonTable
+
+	// unions are combined with the select query, see Union
+	unions []compound
+
+	// maxExecutionTime is enforced by database server, see MaxExecutionTime
+	maxExecutionTime time.Duration
+
+	// allRows allows changing all rows, see AllRows
+	allRows bool
+
+	// hints and indexHints of Select, see Hint and UseIndex
+	hints      []string
+	indexHints []indexHint
+}
+
+// OrderBy sets ORDER BY clause for Select and SelectOne, e.g. OrderBy("price DESC", "id")
+// It overrides the default order declared by struct
+func (t *Table) OrderBy(columns ...string) *Table {
+	c := *t
+	c.orderBy = columns
+	return &c
+}
+
+// Limit sets the max number of rows returned by Select
+func (t *Table) Limit(n int) *Table {
+	c := *t
+	c.limit = n
+	return &c
+}
+
+// Offset sets the number of rows skipped by Select, mysql and sqlite get a LIMIT of all rows if Limit isn't set
+func (t *Table) Offset(n int) *Table {
+	c := *t
+	c.offset = n
+	return &c
+}
+
+// Paginate sets LIMIT and OFFSET for Select, page starts from 1
+func (t *Table) Paginate(page, size int) *Table {
+	if page < 1 || size < 1 {
+		panic("invalid page or size")
+	}
+	c := *t
+	c.limit = size
+	c.offset = (page - 1) * size
+	return &c
+}
+
+// Columns sets columns selected by Select and SelectOne, e.g. to skip wide columns.
+// Fields of other columns are left zero values
+func (t *Table) Columns(names ...string) *Table {
+	c := *t
+	c.columns = names
+	return &c
+}
+
+// Distinct selects distinct rows
+func (t *Table) Distinct() *Table {
+	c := *t
+	c.distinct = true
+	return &c
+}
+
+// GroupBy sets GROUP BY clause for Select and SelectOne
+func (t *Table) GroupBy(columns ...string) *Table {
+	c := *t
+	c.groupBy = columns
+	return &c
+}
+
+// Having sets HAVING clause, args are bound after args of where clause
+func (t *Table) Having(cond string, args ...interface{}) *Table {
+	c := *t
+	c.having = cond
+	c.havingArgs = args
+	return &c
+}
+
+// ForUpdate locks selected rows for update until the transaction ends. Rows are read from primary.
+// It's ignored by sqlite3 which locks the whole database instead
+func (t *Table) ForUpdate() *Table {
+	c := *t
+	c.lock = "UPDATE"
+	c.reader = c.exe
+	return &c
+}
+
+// ForShare locks selected rows against updates by other transactions
+func (t *Table) ForShare() *Table {
+	c := *t
+	c.lock = "SHARE"
+	c.reader = c.exe
+	return &c
+}
+
+// SkipLocked skips rows locked by other transactions, it must be used with ForUpdate or ForShare
+func (t *Table) SkipLocked() *Table {
+	c := *t
+	c.skipLocked = true
+	return &c
+}
+
+// selectInfo returns info of columns and expressions selected by t
+func (t *Table) selectInfo(info *columnInfo) *columnInfo {
+	if len(t.columns) > 0 {
+		info = info.subset(t.columns)
+	}
+
+	if len(t.selectExprs) > 0 {
+		info = info.withExprs(t.selectExprs)
+	}
+	return info
 }
 
 func (t *Table) Insert(record interface{}) error {
@@ -78,10 +269,9 @@ func (t *Table) Insert(record interface{}) error {
 		return err
 	}
 
-	if log.GetLevel() <= log.DebugLevel {
-		log.Debug(query, toReadableArgs(values))
-	}
-	result, err := t.exe.Exec(query, values...)
+	t.logQuery(query, values)
+	result, err := t.exec(query, values...)
+	t.releaseArgs(values)
 	if err != nil {
 		log.Error(err)
 		return err
@@ -103,9 +293,12 @@ func (t *Table) Insert(record interface{}) error {
 func (t *Table) prepareInsertQuery(record interface{}) (string, []interface{}, error) {
 	v := getStructValue(record)
 	info := getColumnInfo(v.Type())
+	if err := t.checkScopedRecord(info); err != nil {
+		return "", nil, err
+	}
 
 	var columns []string
-	values := make([]interface{}, 0, len(info.indexes))
+	values := getArgs(len(info.indexes))
 	if len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
 		columns = info.notAINames
 	} else {
@@ -113,18 +306,19 @@ func (t *Table) prepareInsertQuery(record interface{}) (string, []interface{}, e
 	}
 
 	for _, name := range columns {
-		fv, err := t.getFieldValueByName(v, info, name)
+		fv, err := t.getFieldValueByName(v, info, name, true)
 		if err != nil {
 			return "", nil, err
 		}
 		values = append(values, fv)
 	}
 
-	var buf bytes.Buffer
+	buf := getBuffer()
+	defer putBuffer(buf)
 	buf.WriteString("INSERT INTO ")
-	buf.WriteString(t.name)
+	buf.WriteString(t.quote(t.name))
 	buf.WriteString("(")
-	buf.WriteString(strings.Join(columns, ", "))
+	buf.WriteString(t.quoteColumns(columns))
 	buf.WriteString(") VALUES (")
 	buf.WriteString(strings.Repeat("?, ", len(columns)))
 	buf.Truncate(buf.Len() - 2)
@@ -133,21 +327,44 @@ func (t *Table) prepareInsertQuery(record interface{}) (string, []interface{}, e
 }
 
 func (t *Table) Update(record interface{}) error {
+	_, err := t.update(record)
+	return err
+}
+
+func (t *Table) update(record interface{}) (sql.Result, error) {
+	query, args, err := t.prepareUpdateQuery(record)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	t.logQuery(query, args)
+	result, err := t.exec(query, args...)
+	t.releaseArgs(args)
+	return result, err
+}
+
+func (t *Table) prepareUpdateQuery(record interface{}) (string, []interface{}, error) {
 	v := getStructValue(record)
 	info := getColumnInfo(v.Type())
 	if len(info.pkNames) == 0 {
 		panic("no primary key. please use Insert operation")
 	}
 
-	var buf bytes.Buffer
+	if err := t.checkScopedRecord(info); err != nil {
+		return "", nil, err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
 	buf.WriteString("UPDATE ")
-	buf.WriteString(t.name)
+	buf.WriteString(t.quote(t.name))
 	buf.WriteString(" SET ")
 	for i, c := range info.notPKNames {
 		if i > 0 {
 			buf.WriteString(", ")
 		}
-		buf.WriteString(c)
+		buf.WriteString(t.quote(c))
 		buf.WriteString(" = ?")
 	}
 
@@ -156,15 +373,22 @@ func (t *Table) Update(record interface{}) error {
 		if i > 0 {
 			buf.WriteString(" and ")
 		}
-		buf.WriteString(c)
+		buf.WriteString(t.quote(c))
 		buf.WriteString(" = ?")
 	}
 
-	args := make([]interface{}, 0, len(info.indexes))
+	tenant := t.tenantColumn(info)
+	if len(tenant) > 0 {
+		buf.WriteString(" and ")
+		buf.WriteString(t.quote(tenant))
+		buf.WriteString(" = ?")
+	}
+
+	args := getArgs(len(info.indexes) + 1)
 	for _, name := range info.notPKNames {
-		fv, err := t.getFieldValueByName(v, info, name)
+		fv, err := t.getFieldValueByName(v, info, name, false)
 		if err != nil {
-			return err
+			return "", nil, err
 		}
 		args = append(args, fv)
 	}
@@ -173,12 +397,14 @@ func (t *Table) Update(record interface{}) error {
 		args = append(args, v.FieldByIndex(info.nameToIndex[name]).Interface())
 	}
 
-	query := buf.String()
-	if log.GetLevel() <= log.DebugLevel {
-		log.Debug(query, toReadableArgs(args))
+	if len(tenant) > 0 {
+		id, err := t.tenant()
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, id)
 	}
-	_, err := t.exe.Exec(query, args...)
-	return err
+	return buf.String(), args, nil
 }
 
 func (t *Table) Save(record interface{}) error {
@@ -193,7 +419,7 @@ func (t *Table) Save(record interface{}) error {
 }
 
 func (t *Table) mysqlSave(record interface{}) error {
-	query, values, err := t.prepareInsertQuery(record)
+	query, values, err := t.prepareMysqlSaveQuery(record)
 	if err != nil {
 		log.Error(err)
 		return err
@@ -202,29 +428,9 @@ func (t *Table) mysqlSave(record interface{}) error {
 	v := getStructValue(record)
 	info := getColumnInfo(v.Type())
 
-	var buf bytes.Buffer
-	buf.WriteString(query)
-	buf.WriteString(" ON DUPLICATE KEY UPDATE ")
-	for i, name := range info.names {
-		if i > 0 {
-			buf.WriteString(", ")
-		}
-		buf.WriteString(name)
-		buf.WriteString(" = ?")
-		fv, err := t.getFieldValueByName(v, info, name)
-		if err != nil {
-			return err
-		}
-		values = append(values, fv)
-	}
-
-	query = buf.String()
+	t.logQuery(query, values)
 
-	if log.GetLevel() <= log.DebugLevel {
-		log.Debug(query, toReadableArgs(values))
-	}
-
-	result, err := t.exe.Exec(query, values...)
+	result, err := t.exec(query, values...)
 	if len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
 		id, err := result.LastInsertId()
 		if err != nil {
@@ -236,6 +442,45 @@ func (t *Table) mysqlSave(record interface{}) error {
 	return err
 }
 
+func (t *Table) prepareMysqlSaveQuery(record interface{}) (string, []interface{}, error) {
+	query, values, err := t.prepareInsertQuery(record)
+	if err != nil {
+		return "", nil, err
+	}
+
+	v := getStructValue(record)
+	info := getColumnInfo(v.Type())
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString(query)
+	buf.WriteString(" ON DUPLICATE KEY UPDATE ")
+	for i, name := range info.names {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(t.quote(name))
+		if tenant := t.tenantColumn(info); len(tenant) > 0 {
+			// rows of other tenants with the same key are left unchanged
+			buf.WriteString(" = IF(")
+			buf.WriteString(t.quote(tenant))
+			buf.WriteString(" = VALUES(")
+			buf.WriteString(t.quote(tenant))
+			buf.WriteString("), ?, ")
+			buf.WriteString(t.quote(name))
+			buf.WriteString(")")
+		} else {
+			buf.WriteString(" = ?")
+		}
+		fv, err := t.getFieldValueByName(v, info, name, true)
+		if err != nil {
+			return "", nil, err
+		}
+		values = append(values, fv)
+	}
+	return buf.String(), values, nil
+}
+
 func (t *Table) sqliteSave(record interface{}) error {
 	query, values, err := t.prepareInsertQuery(record)
 	if err != nil {
@@ -247,11 +492,9 @@ func (t *Table) sqliteSave(record interface{}) error {
 	v := getStructValue(record)
 	info := getColumnInfo(v.Type())
 
-	if log.GetLevel() <= log.DebugLevel {
-		log.Debug(query, toReadableArgs(values))
-	}
+	t.logQuery(query, values)
 
-	result, err := t.exe.Exec(query, values...)
+	result, err := t.exec(query, values...)
 	if len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
 		id, err := result.LastInsertId()
 		if err != nil {
@@ -263,254 +506,572 @@ func (t *Table) sqliteSave(record interface{}) error {
 	return err
 }
 
+// Select appends records matching where to records, which is a pointer to slice of structs,
+// or of scalars like []int64 whose single column is set by Columns
 func (t *Table) Select(records interface{}, where string, args ...interface{}) error {
-	v := reflect.ValueOf(records)
-	if v.Kind() != reflect.Ptr {
-		panic("must be a pointer to slice")
+	if t.needsTimeoutTx() {
+		return t.withTx(func(c *Table) error {
+			return c.Select(records, where, args...)
+		})
 	}
 
-	if v.IsNil() && !v.CanSet() {
-		panic("cannot be set value")
+	var fi, checked *columnInfo
+	scalar := isScalarType(sliceElemType(records))
+	if scalar {
+		fi = t.scalarInfo()
+	} else {
+		fi = t.getSelectColumnInfo(getSliceElemType(records))
+		checked = fi
 	}
-
-	sliceType := v.Type().Elem()
-	if sliceType.Kind() != reflect.Slice {
-		panic("must be a pointer to slice")
+	if err := t.checkWhere(checked, where, args); err != nil {
+		log.Error(err)
+		return err
 	}
-
-	isPtr := false
-	elemType := sliceType.Elem()
-	if elemType.Kind() == reflect.Ptr {
-		elemType = elemType.Elem()
-		isPtr = true
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(fi, where, args)
+	if err != nil {
+		log.Error(err)
+		return err
 	}
-
-	if elemType.Kind() != reflect.Struct {
-		panic("slice element must be a struct or pointer to struct")
+	if !scalar {
+		fi = t.selectInfo(fi)
+	}
+	if err = t.validate("SELECT", fi, where); err != nil {
+		log.Error(err)
+		return err
+	}
+	query, args := t.buildSelectQuery(fi, where, args)
+
+	l := reflect.ValueOf(records).Elem()
+	n := l.Len()
+	key := t.cacheKey(query, args, records)
+	if len(key) > 0 {
+		cached := reflect.New(l.Type())
+		if t.loadCache(key, cached) {
+			l.Set(reflect.AppendSlice(l, cached.Elem()))
+			return nil
+		}
 	}
 
-	fi := getColumnInfo(elemType)
+	t.logQuery(query, args)
 
-	var buf bytes.Buffer
-	buf.WriteString("SELECT ")
-	buf.WriteString(strings.Join(fi.names, ", "))
-	buf.WriteString(" FROM ")
-	buf.WriteString(t.name)
-	if len(where) > 0 {
-		buf.WriteString(" WHERE ")
-		buf.WriteString(where)
+	ctx, cancel := t.context()
+	defer cancel()
+	if err = t.setStatementTimeout(ctx); err != nil {
+		log.Error(err)
+		return err
 	}
-	query := buf.String()
 
-	if log.GetLevel() <= log.DebugLevel {
-		log.Debug(query, toReadableArgs(args))
+	rows, err := t.reader.QueryContext(ctx, t.annotate(query), args...)
+	if err != nil {
+		err = t.queryError(query, args, err)
+		log.Error(err)
+		return err
 	}
+	defer rows.Close()
 
-	rows, err := t.exe.Query(query, args...)
+	if scalar {
+		err = scanScalars(rows, records, t.opts)
+	} else {
+		err = scanRows(rows, records, fi, t.opts)
+	}
 	if err != nil {
+		err = t.queryError(query, args, err)
 		log.Error(err)
 		return err
 	}
-	defer rows.Close()
 
-	if v.IsNil() {
-		v.Set(reflect.New(sliceType))
-	}
-	sliceValue := v.Elem()
-	fields := make([]interface{}, len(fi.indexes))
-	for rows.Next() {
-		ptrToElem := utils.DeepNew(elemType)
-		elem := ptrToElem.Elem()
-		for i, idx := range fi.indexes {
-			if utils.IndexOfString(fi.jsonNames, fi.names[i]) >= 0 {
-				var data []byte
-				fields[i] = &data
-			} else if utils.IndexOfString(fi.nullableNames, fi.names[i]) >= 0 {
-				switch elem.FieldByIndex(idx).Kind() {
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-					reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-					var v sql.NullInt64
-					fields[i] = &v
-				case reflect.Bool:
-					var b sql.NullBool
-					fields[i] = &b
-				case reflect.Float32, reflect.Float64:
-					var v sql.NullFloat64
-					fields[i] = &v
-				case reflect.String:
-					var v sql.NullString
-					fields[i] = &v
-				default:
-					panic("invalid nullable type" + fmt.Sprint(elem.FieldByIndex(idx).Type()))
-				}
-			} else {
-				fields[i] = elem.FieldByIndex(idx).Addr().Interface()
-			}
+	if len(t.preloads) > 0 && !scalar {
+		l := reflect.ValueOf(records).Elem()
+		parents := make([]reflect.Value, l.Len())
+		for i := range parents {
+			parents[i] = reflect.Indirect(l.Index(i))
 		}
 
-		err = rows.Scan(fields...)
-		if err != nil {
+		if err = t.loadRelations(parents, getColumnInfo(getSliceElemType(records))); err != nil {
 			log.Error(err)
 			return err
 		}
+	}
 
-		for _, name := range fi.jsonNames {
-			idx := fi.nameToIndex[name]
-			i := utils.IndexOfString(fi.names, name)
-			addr := fields[i]
-			data := reflect.ValueOf(addr).Elem().Interface()
-			err = json.Unmarshal(data.([]byte), elem.FieldByIndex(idx).Addr().Interface())
-			if err != nil {
-				log.Error(err)
-				return err
-			}
+	if len(key) > 0 {
+		selected := reflect.New(l.Type())
+		selected.Elem().Set(l.Slice(n, l.Len()))
+		t.storeCache(key, selected)
+	}
+	return nil
+}
+
+// SelectColumns selects like Select, but only queries and scans columns
+func (t *Table) SelectColumns(records interface{}, columns []string, where string, args ...interface{}) error {
+	return t.Columns(columns...).Select(records, where, args...)
+}
+
+// SelectAtMost selects like Select, but returns ErrTooManyRows without changing records if more than n rows match
+func (t *Table) SelectAtMost(records interface{}, n int, where string, args ...interface{}) error {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		panic("must be a pointer to slice")
+	}
+
+	result := reflect.New(v.Elem().Type())
+	err := t.Limit(n+1).Select(result.Interface(), where, args...)
+	if err != nil {
+		return err
+	}
+
+	if result.Elem().Len() > n {
+		log.Error(ErrTooManyRows)
+		return ErrTooManyRows
+	}
+	v.Elem().Set(result.Elem())
+	return nil
+}
+
+// FindInBatches selects rows matching where in batches ordered by primary key, e.g. for backfills.
+// records is reset to each batch before fn is called with the batch number starting from 0,
+// and it stops if fn returns error
+func (t *Table) FindInBatches(records interface{}, batchSize int, fn func(batch int) error, where string, args ...interface{}) error {
+	if batchSize <= 0 {
+		panic("batchSize must be positive")
+	}
+
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		panic("must be a pointer to slice")
+	}
+
+	info := getColumnInfo(getSliceElemType(records))
+	pk := singlePKName(info)
+	c := t.OrderBy(pk).Limit(batchSize)
+	cond := where
+	for batch := 0; ; batch++ {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+		if err := c.Select(records, cond, args...); err != nil {
+			return err
 		}
 
-		for _, name := range fi.nullableNames {
-			idx := fi.nameToIndex[name]
-			i := utils.IndexOfString(fi.names, name)
-			addr := fields[i]
-			switch v := reflect.ValueOf(addr).Elem().Interface().(type) {
-			case sql.NullString:
-				if v.Valid {
-					elem.FieldByIndex(idx).SetString(v.String)
-				}
-			case sql.NullFloat64:
-				if v.Valid {
-					elem.FieldByIndex(idx).SetFloat(v.Float64)
-				}
-			case sql.NullBool:
-				if v.Valid {
-					elem.FieldByIndex(idx).SetBool(v.Bool)
-				}
-			case sql.NullInt64:
-				if v.Valid {
-					elem.FieldByIndex(idx).SetInt(v.Int64)
-				}
-			default:
-				panic("invalid type:" + fmt.Sprint(v))
-			}
+		n := v.Elem().Len()
+		if n == 0 {
+			return nil
 		}
 
-		if isPtr {
-			sliceValue = reflect.Append(sliceValue, ptrToElem)
-		} else {
-			sliceValue = reflect.Append(sliceValue, elem)
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		if n < batchSize {
+			return nil
+		}
+
+		last := reflect.Indirect(v.Elem().Index(n - 1))
+		if batch == 0 {
+			if len(where) > 0 {
+				cond = "(" + where + ") AND "
+			}
+			cond += t.quote(pk) + " > ?"
+			args = append(append([]interface{}(nil), args...), nil)
 		}
+		args[len(args)-1] = last.FieldByIndex(info.nameToIndex[pk]).Interface()
 	}
-	v.Elem().Set(sliceValue)
-	return nil
 }
 
+// SelectOne selects the first record matching where into record, which is a pointer to struct,
+// or to scalar like *int64 whose single column is set by Columns. It returns ErrNoRows if no record matches
 func (t *Table) SelectOne(record interface{}, where string, args ...interface{}) error {
+	if t.needsTimeoutTx() {
+		return t.withTx(func(c *Table) error {
+			return c.SelectOne(record, where, args...)
+		})
+	}
+
 	rv := reflect.ValueOf(record)
 	if rv.Kind() != reflect.Ptr {
 		panic("not pointer to a struct")
 	}
 
 	//Store result in ev. If failed, don't change record's value
-	ev := utils.DeepNew(rv.Elem().Type()).Elem()
-	elem := ev
-	if elem.Kind() == reflect.Ptr {
-		elem = elem.Elem()
+	var ev, elem reflect.Value
+	var info, checked *columnInfo
+	scalar := isScalarType(rv.Elem().Type())
+	if scalar {
+		// scanned into a pointer to ev, which is set to nil by NULL if it's a pointer
+		ev = reflect.New(rv.Elem().Type()).Elem()
+		info = t.scalarInfo()
+	} else {
+		ev = utils.DeepNew(rv.Elem().Type()).Elem()
+		elem = ev
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		info = t.getSelectColumnInfo(elem.Type())
+		checked = info
+	}
+
+	if err := t.checkWhere(checked, where, args); err != nil {
+		log.Error(err)
+		return err
+	}
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(info, where, args)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+	if !scalar {
+		info = t.selectInfo(info)
+	}
+	if err = t.validate("SELECT", info, where); err != nil {
+		log.Error(err)
+		return err
+	}
+	query, args := t.buildSelectQuery(info, where, args)
+
+	key := t.cacheKey(query, args, record)
+	if len(key) > 0 && t.loadCache(key, rv) {
+		return nil
 	}
 
-	if elem.Kind() != reflect.Struct {
+	t.logQuery(query, args)
+
+	ctx, cancel := t.context()
+	defer cancel()
+	if err = t.setStatementTimeout(ctx); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	row := t.reader.QueryRowContext(ctx, t.annotate(query), args...)
+	if scalar {
+		err = scanScalar(row, ev, t.opts)
+	} else {
+		err = scanStruct(row, elem, info, t.opts)
+	}
+	if err != nil {
+		err = t.queryError(query, args, err)
+		log.Error(err)
+		return err
+	}
+
+	if len(t.preloads) > 0 && !scalar {
+		if err = t.loadRelations([]reflect.Value{elem}, getColumnInfo(elem.Type())); err != nil {
+			log.Error(err)
+			return err
+		}
+	}
+
+	rv.Elem().Set(ev)
+	if len(key) > 0 {
+		t.storeCache(key, rv)
+	}
+	return nil
+}
+
+// Get selects record by primary key values, and returns ErrNoRows if it doesn't exist
+func (t *Table) Get(record interface{}, pk ...interface{}) error {
+	typ := reflect.TypeOf(record)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
 		panic("not pointer to a struct")
 	}
 
-	info := getColumnInfo(elem.Type())
+	info := getColumnInfo(typ)
+	if len(info.pkNames) == 0 {
+		panic("no primary key")
+	}
+
+	if len(pk) != len(info.pkNames) {
+		panic(fmt.Sprintf("%d primary key values are required, got %d", len(info.pkNames), len(pk)))
+	}
+	return t.SelectOne(record, t.pkWhere(info), pk...)
+}
+
+// pkWhere returns where clause matching primary key columns
+func (t *Table) pkWhere(info *columnInfo) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	for i, name := range info.pkNames {
+		if i > 0 {
+			buf.WriteString(" AND ")
+		}
+		buf.WriteString(t.quote(name))
+		buf.WriteString(" = ?")
+	}
+	return buf.String()
+}
+
+// context returns context for a statement, cancel must be called after the statement is done
+func (t *Table) context() (context.Context, context.CancelFunc) {
+	parent := t.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	if t.timeout > 0 {
+		return context.WithTimeout(parent, t.timeout)
+	}
+
+	if t.timeout < 0 {
+		return parent, func() {}
+	}
+	return t.opts.context(parent)
+}
 
-	var buf bytes.Buffer
+// Name returns name of t, which is qualified by schema if it's set
+func (t *Table) Name() string {
+	return t.name
+}
+
+// WithContext sets parent context of statements executed by t, which is passed to middleware
+func (t *Table) WithContext(ctx context.Context) *Table {
+	c := *t
+	c.ctx = ctx
+	return &c
+}
+
+// txError returns TxAbortedError if err is caused by done context of t's Tx
+func (t *Table) txError(err error) error {
+	if t.tx == nil {
+		return err
+	}
+	return txError(t.ctx, err)
+}
+
+// Timeout overrides the default query timeout of statements executed by t. d < 0 disables the timeout
+func (t *Table) Timeout(d time.Duration) *Table {
+	c := *t
+	c.timeout = d
+	return &c
+}
+
+// annotate prepends comment to query, and rebinds placeholders for postgres
+func (t *Table) annotate(query string) string {
+	if isPostgres(t.driverName) {
+		query = rebind(query)
+	}
+
+	if len(t.comment) == 0 {
+		return query
+	}
+	return "/* " + strings.Replace(t.comment, "*/", "* /", -1) + " */ " + query
+}
+
+func (t *Table) exec(query string, args ...interface{}) (sql.Result, error) {
+	if t.needsTimeoutTx() {
+		var result sql.Result
+		err := t.withTx(func(c *Table) error {
+			var err error
+			result, err = c.exec(query, args...)
+			return err
+		})
+		return result, err
+	}
+
+	ctx, cancel := t.context()
+	defer cancel()
+	if err := t.setStatementTimeout(ctx); err != nil {
+		return nil, err
+	}
+	result, err := t.exe.ExecContext(ctx, t.annotate(query), args...)
+	return result, t.queryError(query, args, err)
+}
+
+// withTx calls fn with t in a transaction, which is begun and committed by withTx unless t is already in one.
+// The transaction is rolled back if fn panics
+func (t *Table) withTx(fn func(c *Table) error) error {
+	if t.tx != nil {
+		return fn(t)
+	}
+
+	if t.db == nil {
+		panic("no database")
+	}
+
+	var tx *sql.Tx
+	var err error
+	if t.conn != nil {
+		tx, err = t.conn.BeginTx(t.ctx, nil)
+	} else {
+		tx, err = t.db.Begin()
+	}
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// misuse panics of fn mustn't leak the transaction and its connection
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	c := *t
+	c.tx = tx
+	c.exe = t.opts.wrap(tx)
+	c.reader = c.exe
+	if err = fn(&c); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// buildSelectQuery returns select query and its args, which are args of where followed by args of other clauses
+func (t *Table) buildSelectQuery(info *columnInfo, where string, args []interface{}) (string, []interface{}) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if isPostgres(t.driverName) {
+		buf.WriteString(t.hintComment())
+	}
+	buf.WriteString(t.withClause())
 	buf.WriteString("SELECT ")
-	buf.WriteString(strings.Join(info.names, ", "))
+	if !isPostgres(t.driverName) {
+		buf.WriteString(t.hintComment())
+	}
+	if t.distinct {
+		buf.WriteString("DISTINCT ")
+	}
+
+	columns := info.names[:len(info.names)-len(info.exprs)]
+	if t.qualified && len(t.joins) == 0 {
+		columns = t.qualifyColumns(columns)
+	}
+	if len(columns) > 0 {
+		buf.WriteString(t.quoteColumns(columns))
+	}
+
+	if len(info.exprs) > 0 {
+		if len(columns) > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(strings.Join(info.exprs, ", "))
+	} else if len(columns) == 0 {
+		buf.WriteString("*")
+	}
 	buf.WriteString(" FROM ")
-	buf.WriteString(t.name)
+	buf.WriteString(t.from())
+	buf.WriteString(t.sampleClause())
+	buf.WriteString(t.indexHintClause())
+	for _, j := range t.joins {
+		buf.WriteString(" ")
+		buf.WriteString(j)
+	}
+	where = t.sampleWhere(where)
 	if len(where) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(where)
 	}
-	query := buf.String()
 
-	if log.GetLevel() <= log.DebugLevel {
-		log.Debug(query, toReadableArgs(args))
-	}
-
-	fieldAddrs := make([]interface{}, len(info.indexes))
-	for i, idx := range info.indexes {
-		if utils.IndexOfString(info.jsonNames, info.names[i]) >= 0 {
-			var data []byte
-			fieldAddrs[i] = &data
-		} else if utils.IndexOfString(info.nullableNames, info.names[i]) >= 0 {
-			switch elem.FieldByIndex(idx).Kind() {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-				var v sql.NullInt64
-				fieldAddrs[i] = &v
-			case reflect.Bool:
-				var b sql.NullBool
-				fieldAddrs[i] = &b
-			case reflect.Float32, reflect.Float64:
-				var v sql.NullFloat64
-				fieldAddrs[i] = &v
-			case reflect.String:
-				var v sql.NullString
-				fieldAddrs[i] = &v
-			default:
-				panic("invalid nullable type" + fmt.Sprint(elem.FieldByIndex(idx).Type()))
-			}
+	args = t.fromArgs(args)
+	if len(t.groupBy) > 0 {
+		buf.WriteString(" GROUP BY ")
+		buf.WriteString(strings.Join(t.groupBy, ", "))
+	}
+
+	if len(t.having) > 0 {
+		buf.WriteString(" HAVING ")
+		buf.WriteString(t.having)
+		args = append(append([]interface{}(nil), args...), t.havingArgs...)
+	}
+
+	for _, u := range t.unions {
+		if u.all {
+			buf.WriteString(" UNION ALL ")
 		} else {
-			fieldAddrs[i] = elem.FieldByIndex(idx).Addr().Interface()
+			buf.WriteString(" UNION ")
 		}
+		buf.WriteString(u.sub.SQL)
+		args = append(append([]interface{}(nil), args...), u.sub.Args...)
 	}
-	err := t.exe.QueryRow(query, args...).Scan(fieldAddrs...)
-	if err != nil {
-		log.Error(err)
-		return err
+
+	// Default order may be invalid for grouped rows, e.g. ordered by columns which aren't grouped
+	orderBy := t.orderBy
+	if len(orderBy) == 0 && len(t.groupBy) == 0 {
+		orderBy = info.defaultOrder
 	}
 
-	for _, name := range info.jsonNames {
-		idx := info.nameToIndex[name]
-		i := utils.IndexOfString(info.names, name)
-		addr := fieldAddrs[i]
-		data := reflect.ValueOf(addr).Elem().Interface()
-		err = json.Unmarshal(data.([]byte), elem.FieldByIndex(idx).Addr().Interface())
-		if err != nil {
-			log.Error(err)
-			return err
+	// Primary key can't be a tiebreaker of distinct or grouped rows
+	grouped := t.distinct || len(t.groupBy) > 0
+	if t.opts.strictOrder && !grouped && (len(orderBy) > 0 || t.limit > 0 || t.offset > 0) {
+		orderBy = appendTiebreaker(orderBy, info.pkNames, t.hintTarget())
+	}
+
+	if len(orderBy) > 0 {
+		buf.WriteString(" ORDER BY ")
+		buf.WriteString(strings.Join(orderBy, ", "))
+	}
+
+	if t.limit > 0 {
+		buf.WriteString(fmt.Sprintf(" LIMIT %d", t.limit))
+	} else if t.offset > 0 {
+		// OFFSET requires LIMIT in mysql and sqlite
+		switch t.driverName {
+		case "mysql":
+			buf.WriteString(" LIMIT 18446744073709551615")
+		case "sqlite3":
+			buf.WriteString(" LIMIT -1")
 		}
 	}
 
-	for _, name := range info.nullableNames {
-		idx := info.nameToIndex[name]
-		i := utils.IndexOfString(info.names, name)
-		addr := fieldAddrs[i]
-		switch v := reflect.ValueOf(addr).Elem().Interface().(type) {
-		case sql.NullString:
-			if v.Valid {
-				elem.FieldByIndex(idx).SetString(v.String)
-			}
-		case sql.NullFloat64:
-			if v.Valid {
-				elem.FieldByIndex(idx).SetFloat(v.Float64)
-			}
-		case sql.NullBool:
-			if v.Valid {
-				elem.FieldByIndex(idx).SetBool(v.Bool)
-			}
-		case sql.NullInt64:
-			if v.Valid {
-				elem.FieldByIndex(idx).SetInt(v.Int64)
+	if t.offset > 0 {
+		buf.WriteString(fmt.Sprintf(" OFFSET %d", t.offset))
+	}
+
+	if len(t.lock) > 0 && len(t.unions) > 0 {
+		panic("locking reads can't be used with Union")
+	}
+
+	if len(t.lock) > 0 && t.driverName != "sqlite3" {
+		buf.WriteString(" FOR ")
+		buf.WriteString(t.lock)
+		if t.skipLocked {
+			buf.WriteString(" SKIP LOCKED")
+		}
+	} else if t.skipLocked && len(t.lock) == 0 {
+		panic("SkipLocked must be used with ForUpdate or ForShare")
+	}
+	buf.WriteString(t.hintOption())
+	return buf.String(), args
+}
+
+// appendTiebreaker appends primary key columns which are not in orderBy. Columns in orderBy may be quoted,
+// or qualified by target which is the table name or its alias, e.g. `id` and o.id are the primary key id of orders o
+func appendTiebreaker(orderBy []string, pkNames []string, target string) []string {
+	target = strings.ToLower(unquoteIdent(target))
+	result := append([]string(nil), orderBy...)
+	for _, pk := range pkNames {
+		found := false
+		for _, o := range orderBy {
+			if qualifier, column := orderColumn(o); column == pk &&
+				(qualifier == "" || qualifier == target || strings.HasSuffix(target, "."+qualifier)) {
+				found = true
+				break
 			}
-		default:
-			panic("invalid type:" + fmt.Sprint(v))
+		}
+
+		if !found {
+			result = append(result, pk)
 		}
 	}
+	return result
+}
 
-	if err == nil {
-		rv.Elem().Set(ev)
+// orderColumn returns the unquoted column of order term o in lower case, and its qualifier, e.g. "o"."ID" DESC is o and id
+func orderColumn(o string) (qualifier, column string) {
+	fields := strings.Fields(o)
+	if len(fields) == 0 {
+		return "", ""
 	}
-	return err
+
+	name := strings.ToLower(unquoteIdent(fields[0]))
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
 }
 
 /*
@@ -525,45 +1086,147 @@ func (t *Table) Query(query string, args ...interface{}) (*Rows, error) {
 }*/
 
 func (t *Table) Delete(where string, args ...interface{}) error {
-	if len(where) == 0 {
-		panic("where is empty")
+	_, err := t.delete(where, args)
+	return err
+}
+
+func (t *Table) delete(where string, args []interface{}) (sql.Result, error) {
+	if err := t.checkAllRows(where); err != nil {
+		log.Error(err)
+		return nil, err
 	}
-	var buf bytes.Buffer
-	buf.WriteString("DELETE FROM ")
-	buf.WriteString(t.name)
-	buf.WriteString(" WHERE ")
-	buf.WriteString(where)
 
-	query := buf.String()
+	if err := t.checkWhere(nil, where, args); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	if err := t.validate("DELETE", nil, where); err != nil {
+		log.Error(err)
+		return nil, err
+	}
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(nil, where, args)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	query := t.prepareDeleteQuery(where)
+	t.logQuery(query, args)
+
+	result, err := t.exec(query, args...)
+	if err != nil {
+		log.Error(err)
+	}
+	return result, err
+}
 
-	if log.GetLevel() <= log.DebugLevel {
-		log.Debug(query, toReadableArgs(args))
+// DeleteRecord deletes the row of record by its primary key
+func (t *Table) DeleteRecord(record interface{}) error {
+	query, args, err := t.prepareDeleteRecordQuery(record)
+	if err != nil {
+		log.Error(err)
+		return err
 	}
 
-	_, err := t.exe.Exec(query, args...)
+	t.logQuery(query, args)
+
+	_, err = t.exec(query, args...)
 	if err != nil {
 		log.Error(err)
 	}
 	return err
 }
 
+func (t *Table) prepareDeleteRecordQuery(record interface{}) (string, []interface{}, error) {
+	v := getStructValue(record)
+	info := getColumnInfo(v.Type())
+	if len(info.pkNames) == 0 {
+		panic("no primary key. please use Delete with where")
+	}
+
+	args := make([]interface{}, 0, len(info.pkNames))
+	for _, name := range info.pkNames {
+		args = append(args, v.FieldByIndex(info.nameToIndex[name]).Interface())
+	}
+
+	where, args, err := t.scopeWhere(info, t.pkWhere(info), args)
+	if err != nil {
+		return "", nil, err
+	}
+	return t.prepareDeleteQuery(where), args, nil
+}
+
+func (t *Table) prepareDeleteQuery(where string) string {
+	if len(t.joins) > 0 {
+		return t.prepareJoinDeleteQuery(where)
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("DELETE FROM ")
+	buf.WriteString(t.quote(t.name))
+	if len(where) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(where)
+	}
+	return buf.String()
+}
+
 func (t *Table) Count(where string, args ...interface{}) (int, error) {
-	var buf bytes.Buffer
+	if t.needsTimeoutTx() {
+		var count int
+		err := t.withTx(func(c *Table) error {
+			var err error
+			count, err = c.Count(where, args...)
+			return err
+		})
+		return count, err
+	}
+
+	if err := t.checkWhere(nil, where, args); err != nil {
+		log.Error(err)
+		return 0, err
+	}
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(nil, where, args)
+	if err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString(t.withClause())
 	buf.WriteString("SELECT COUNT(*) FROM ")
-	buf.WriteString(t.name)
+	buf.WriteString(t.from())
+	buf.WriteString(t.sampleClause())
+	buf.WriteString(t.indexHintClause())
+	for _, j := range t.joins {
+		buf.WriteString(" ")
+		buf.WriteString(j)
+	}
+	where = t.sampleWhere(where)
 	if len(where) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(where)
 	}
 	query := buf.String()
+	args = t.fromArgs(args)
 
-	if log.GetLevel() <= log.DebugLevel {
-		log.Debug(query, toReadableArgs(args))
-	}
+	t.logQuery(query, args)
 
 	var count int
-	err := t.exe.QueryRow(query, args...).Scan(&count)
+	ctx, cancel := t.context()
+	defer cancel()
+	if err := t.setStatementTimeout(ctx); err != nil {
+		log.Error(err)
+		return 0, err
+	}
+
+	err = t.reader.QueryRowContext(ctx, t.annotate(query), args...).Scan(&count)
 	if err != nil {
+		err = t.queryError(query, args, err)
 		log.Error(err)
 		return 0, err
 	}
@@ -571,7 +1234,52 @@ func (t *Table) Count(where string, args ...interface{}) (int, error) {
 	return count, nil
 }
 
-func (t *Table) getFieldValueByName(item reflect.Value, info *columnInfo, name string) (interface{}, error) {
+// getFieldValueByName returns value of column name in item, which is bound as SensitiveValue if the column is sensitive.
+// Zero ids are generated and set if generate is true, which is only for inserts
+func (t *Table) getFieldValueByName(item reflect.Value, info *columnInfo, name string, generate bool) (interface{}, error) {
+	v, err := t.fieldValue(item, info, name, generate)
+	if err == nil && v != nil && utils.IndexOfString(info.sensitiveNames, name) >= 0 {
+		return Sensitive(v), nil
+	}
+	return v, err
+}
+
+func (t *Table) fieldValue(item reflect.Value, info *columnInfo, name string, generate bool) (interface{}, error) {
+	if name == t.tenantColumn(info) {
+		return t.tenantValue(item, info)
+	}
+
+	if version, ok := info.uuidVersions[name]; ok && generate {
+		return uuidValue(item.FieldByIndex(info.nameToIndex[name]), version), nil
+	}
+
+	if generator, ok := info.idGenerators[name]; ok && generate {
+		return t.generatedID(item.FieldByIndex(info.nameToIndex[name]), generator), nil
+	}
+
+	if utils.IndexOfString(info.arrayNames, name) >= 0 {
+		f := item.FieldByIndex(info.nameToIndex[name])
+		if utils.IndexOfString(info.nullableNames, name) >= 0 && f.Len() == 0 {
+			return nil, nil
+		}
+		return &ArrayValue{v: f.Interface()}, nil
+	}
+
+	if allowed, ok := info.enums[name]; ok {
+		v := item.FieldByIndex(info.nameToIndex[name]).String()
+		if err := checkEnum(name, allowed, v, utils.IndexOfString(info.nullableNames, name) >= 0); err != nil {
+			return nil, err
+		}
+	}
+
+	if utils.IndexOfString(info.encryptedNames, name) >= 0 {
+		f := item.FieldByIndex(info.nameToIndex[name])
+		if utils.IndexOfString(info.nullableNames, name) >= 0 && f.Len() == 0 {
+			return nil, nil
+		}
+		return encryptValue(t.opts.cipher, f)
+	}
+
 	k := item.FieldByIndex(info.nameToIndex[name]).Interface()
 	if utils.IndexOfString(info.jsonNames, name) >= 0 {
 		data, err := json.Marshal(k)
@@ -0,0 +1,271 @@
+package sql
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTable_buildSelectQuery(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(ddlProduct{}))
+	tbl := &Table{driverName: "mysql", name: "products", opts: &options{}}
+	c := tbl.Columns("id", "Name", "tags").Limit(10)
+	sub := c.selectInfo(info)
+	query, args := c.buildSelectQuery(sub, "price > ?", []interface{}{1})
+	if query != "SELECT id, name, tags FROM products WHERE price > ? LIMIT 10" || len(args) != 1 {
+		t.Error(query, args)
+	}
+
+	if len(sub.jsonNames) != 1 || len(sub.nullableNames) != 0 || !reflect.DeepEqual(sub.indexes[1], info.nameToIndex["name"]) {
+		t.Error(sub)
+	}
+
+	if tbl.selectInfo(info) != info {
+		t.Error("all columns should be selected by default")
+	}
+}
+
+func TestTable_buildSelectQuery_groupBy(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(ddlProduct{}))
+	tbl := &Table{driverName: "mysql", name: "products", opts: &options{strictOrder: true}}
+	c := tbl.Columns("name").Distinct().GroupBy("name").Having("COUNT(*) > ?", 2).OrderBy("name")
+	query, args := c.buildSelectQuery(c.selectInfo(info), "price > ?", []interface{}{1})
+	expected := "SELECT DISTINCT name FROM products WHERE price > ? GROUP BY name HAVING COUNT(*) > ? ORDER BY name"
+	if query != expected {
+		t.Error(query)
+	}
+
+	if len(args) != 2 || args[0] != 1 || args[1] != 2 {
+		t.Error(args)
+	}
+}
+
+type defaultOrderTopic struct {
+	ID        int64 `sql:"primary key,defaultorder=created_at desc; id desc"`
+	Category  string
+	CreatedAt int64
+}
+
+func TestTable_buildSelectQuery_defaultOrder(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(defaultOrderTopic{}))
+	if !reflect.DeepEqual(info.defaultOrder, []string{"created_at desc", "id desc"}) {
+		t.Error(info.defaultOrder)
+	}
+
+	tbl := &Table{driverName: "mysql", name: "topics", opts: &options{}}
+	query, _ := tbl.buildSelectQuery(info, "", nil)
+	if query != "SELECT id, category, created_at FROM topics ORDER BY created_at desc, id desc" {
+		t.Error(query)
+	}
+
+	c := tbl.Columns("category").GroupBy("category")
+	query, _ = c.buildSelectQuery(c.selectInfo(info), "", nil)
+	if query != "SELECT category FROM topics GROUP BY category" {
+		t.Error(query)
+	}
+}
+
+func TestAppendTiebreaker(t *testing.T) {
+	for _, o := range []string{"id DESC", "`id`", "o.id", `"O"."ID" desc`} {
+		if l := appendTiebreaker([]string{o}, []string{"id"}, "o"); len(l) != 1 {
+			t.Error(o, l)
+		}
+	}
+
+	if l := appendTiebreaker([]string{"u.id"}, []string{"id"}, "o"); !reflect.DeepEqual(l, []string{"u.id", "id"}) {
+		t.Error(l)
+	}
+
+	if l := appendTiebreaker([]string{"orders.id"}, []string{"id"}, "analytics.orders"); len(l) != 1 {
+		t.Error(l)
+	}
+}
+
+func TestTable_buildSelectQuery_offset(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(ddlProduct{}))
+	for driverName, expected := range map[string]string{
+		"mysql":    "SELECT id FROM products LIMIT 18446744073709551615 OFFSET 20",
+		"sqlite3":  "SELECT id FROM products LIMIT -1 OFFSET 20",
+		"postgres": "SELECT id FROM products OFFSET 20",
+	} {
+		tbl := &Table{driverName: driverName, name: "products", opts: &options{}}
+		c := tbl.Columns("id").Offset(20)
+		if query, _ := c.buildSelectQuery(c.selectInfo(info), "", nil); query != expected {
+			t.Error(query)
+		}
+	}
+}
+
+func TestTable_buildSelectQuery_lock(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(ddlProduct{}))
+	tbl := &Table{driverName: "postgres", name: "products", opts: &options{}}
+	c := tbl.Columns("id").ForUpdate().SkipLocked().Limit(1)
+	query, _ := c.buildSelectQuery(c.selectInfo(info), "price > ?", []interface{}{1})
+	if query != "SELECT id FROM products WHERE price > ? LIMIT 1 FOR UPDATE SKIP LOCKED" {
+		t.Error(query)
+	}
+
+	tbl.driverName = "sqlite3"
+	c = tbl.Columns("id").ForShare()
+	if query, _ = c.buildSelectQuery(c.selectInfo(info), "", nil); query != "SELECT id FROM products" {
+		t.Error(query)
+	}
+}
+
+func TestTable_context(t *testing.T) {
+	tbl := &Table{opts: &options{queryTimeout: time.Minute}}
+	ctx, cancel := tbl.context()
+	defer cancel()
+	if d, ok := ctx.Deadline(); !ok || time.Until(d) > time.Minute {
+		t.Error("default timeout is not applied")
+	}
+
+	ctx, cancel = tbl.Timeout(time.Second).context()
+	defer cancel()
+	if d, ok := ctx.Deadline(); !ok || time.Until(d) > time.Second {
+		t.Error("timeout is not overridden")
+	}
+
+	ctx, cancel = tbl.Timeout(-1).context()
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("timeout is not disabled")
+	}
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	tbl.ctx = parent
+	ctx, cancel = tbl.context()
+	defer cancel()
+	cancelParent()
+	if ctx.Err() == nil {
+		t.Error("parent context is not applied")
+	}
+}
+
+func BenchmarkTable_prepareInsertQuery(b *testing.B) {
+	type product struct {
+		ID    int64 `sql:"primary key,auto_increment"`
+		Name  string
+		Price float64
+	}
+
+	tbl := &Table{driverName: "mysql", name: "products", opts: &options{}}
+	p := &product{Name: "apple", Price: 1.5}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, args, err := tbl.prepareInsertQuery(p)
+		if err != nil {
+			b.Fatal(err)
+		}
+		putArgs(args)
+	}
+}
+
+func BenchmarkTable_buildSelectQuery(b *testing.B) {
+	tbl := &Table{driverName: "mysql", name: "products", opts: &options{}}
+	tbl = tbl.OrderBy("price DESC").Limit(20)
+	info := &columnInfo{names: []string{"id", "name", "price"}}
+	args := []interface{}{1}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tbl.buildSelectQuery(info, "price > ?", args)
+	}
+}
+
+type exprUserStat struct {
+	ID        int64  `sql:"primary key"`
+	NameLower string `sql:"name_lower,computed"`
+	N         int    `sql:"computed"`
+}
+
+func TestTable_SelectExpr(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(exprUserStat{}))
+	if !reflect.DeepEqual(info.names, []string{"id"}) || len(info.computed) != 2 {
+		t.Fatal(info.names, info.computed)
+	}
+
+	tbl := &Table{driverName: "mysql", name: "users", opts: &options{}}
+	c := tbl.SelectExpr("lower(name) AS name_lower", "count(*) as `n`").GroupBy("id")
+	sub := c.selectInfo(info)
+	query, _ := c.buildSelectQuery(sub, "", nil)
+	if query != "SELECT id, lower(name) AS name_lower, count(*) as `n` FROM users GROUP BY id" {
+		t.Error(query)
+	}
+
+	if !reflect.DeepEqual(sub.names, []string{"id", "name_lower", "n"}) || !reflect.DeepEqual(sub.indexes[2], info.computed["n"]) {
+		t.Error(sub.names, sub.indexes)
+	}
+
+	c = tbl.SelectExpr("max(id)")
+	if query, _ = c.buildSelectQuery(c.scalarInfo(), "", nil); query != "SELECT max(id) FROM users" {
+		t.Error(query)
+	}
+}
+
+func TestTable_With(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(ddlProduct{}))
+	tbl := &Table{driverName: "postgres", name: "shop.recent", opts: &options{}}
+	recent := (&Table{driverName: "postgres", name: "products", opts: &options{}}).Columns("id").Subquery("price > ?", 1)
+	c := tbl.With("recent", recent).Columns("id")
+	query, args := c.buildSelectQuery(c.selectInfo(info), "id < ?", []interface{}{9})
+	if query != "WITH recent AS (SELECT id FROM products WHERE price > ?) SELECT id FROM recent WHERE id < ?" {
+		t.Error(query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{1, 9}) {
+		t.Error(args)
+	}
+
+	tree := Expr("SELECT id FROM products WHERE id = ? UNION ALL SELECT p.id FROM products p JOIN tree ON p.parent_id = tree.id", 2)
+	c = (&Table{driverName: "mysql", name: "tree", opts: &options{}}).With("recent", recent).WithRecursive("tree", tree)
+	if !strings.HasPrefix(c.withClause(), "WITH RECURSIVE recent AS (SELECT id FROM products WHERE price > ?), tree AS (") {
+		t.Error(c.withClause())
+	}
+
+	c.driverName = "sqlserver"
+	if !strings.HasPrefix(c.withClause(), "WITH recent AS") {
+		t.Error(c.withClause())
+	}
+}
+
+func TestTable_Union(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(ddlProduct{}))
+	articles := (&Table{driverName: "postgres", name: "articles", opts: &options{}}).Columns("id", "name").Subquery("name LIKE ?", "%a%")
+	tbl := &Table{driverName: "postgres", name: "products", opts: &options{}}
+	c := tbl.Columns("id", "name").Union(articles).UnionAll(Expr("SELECT id, name FROM drafts WHERE id = ?", 3)).OrderBy("name").Limit(5)
+	query, args := c.buildSelectQuery(c.selectInfo(info), "name LIKE ?", []interface{}{"%p%"})
+	expected := "SELECT id, name FROM products WHERE name LIKE ? UNION SELECT id, name FROM articles WHERE name LIKE ? " +
+		"UNION ALL SELECT id, name FROM drafts WHERE id = ? ORDER BY name LIMIT 5"
+	if query != expected {
+		t.Error(query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"%p%", "%a%", 3}) {
+		t.Error(args)
+	}
+
+	if query = tbl.annotate(query); !strings.Contains(query, "name LIKE $2 UNION ALL") || !strings.Contains(query, "id = $3") {
+		t.Error(query)
+	}
+}
+
+func TestTable_Hint(t *testing.T) {
+	info := &columnInfo{names: []string{"id"}}
+	mysql := &Table{driverName: "mysql", name: "users u", opts: &options{}}
+	query, _ := mysql.Hint("NO_ICP(u)").MaxExecutionTime(time.Second).UseIndex("idx_email").buildSelectQuery(info, "email = ?", nil)
+	if query != "SELECT /*+ MAX_EXECUTION_TIME(1000) NO_ICP(u) */ id FROM users u USE INDEX (idx_email) WHERE email = ?" {
+		t.Error(query)
+	}
+
+	pg := &Table{driverName: "postgres", name: "users", opts: &options{}}
+	query, _ = pg.ForceIndex("idx_email").buildSelectQuery(info, "email = ?", nil)
+	if query != "/*+ IndexScan(users idx_email) */ SELECT id FROM users WHERE email = ?" {
+		t.Error(query)
+	}
+
+	mssql := &Table{driverName: "sqlserver", name: "users", opts: &options{}}
+	query, _ = mssql.Hint("RECOMPILE").ForceIndex("idx_email").buildSelectQuery(info, "email = ?", nil)
+	if query != "SELECT id FROM users WITH (INDEX(idx_email)) WHERE email = ? OPTION (RECOMPILE)" {
+		t.Error(query)
+	}
+}
@@ -0,0 +1,89 @@
+package sql
+
+import (
+	"strings"
+	"time"
+)
+
+// AsOf returns a copy of t which selects rows of system-versioned table as they were at ts, e.g.
+// db.Table("prices").AsOf(ts).Select(&prices, "sku = ?", sku). It renders FOR SYSTEM_TIME AS OF, which is supported
+// by sqlserver and MariaDB with mysql driver. See AsOfHistory for other databases
+func (t *Table) AsOf(ts time.Time) *Table {
+	switch t.driverName {
+	case "mysql", "sqlserver", "mssql":
+	default:
+		panic("AsOf is not supported for driver: " + t.driverName + ". please use AsOfHistory")
+	}
+
+	if t.source != nil {
+		panic("AsOf can't select from subquery")
+	}
+
+	c := *t
+	c.asOf = &ts
+	return &c
+}
+
+// systemTime returns FOR SYSTEM_TIME clause of AsOf
+func (t *Table) systemTime() string {
+	if t.asOf == nil {
+		return ""
+	}
+
+	if t.driverName == "mysql" {
+		return " FOR SYSTEM_TIME AS OF TIMESTAMP ?"
+	}
+	return " FOR SYSTEM_TIME AS OF ?"
+}
+
+// History declares history table of t, where changed and deleted rows are copied with their validity period,
+// e.g. by triggers or temporal_tables extension of postgres
+type History struct {
+	// Table is name of history table, default is table name with suffix _history
+	Table string
+
+	// ValidFrom and ValidTo are names of columns of validity period [ValidFrom, ValidTo),
+	// which exist in both tables. Default is valid_from and valid_to. NULL ValidTo means the row is current
+	ValidFrom string
+	ValidTo   string
+}
+
+// AsOfHistory returns a copy of t which selects rows as they were at ts from union of t and its history table,
+// whose columns are the same, e.g. db.Table("prices p").AsOfHistory(ts, nil)
+func (t *Table) AsOfHistory(ts time.Time, h *History) *Table {
+	if t.source != nil {
+		panic("AsOfHistory can't select from subquery")
+	}
+
+	fields := strings.Fields(t.name)
+	table := fields[0]
+	alias := fields[len(fields)-1]
+	if len(fields) == 1 {
+		_, alias = splitQualified(table)
+	}
+
+	history := table + "_history"
+	validFrom, validTo := "valid_from", "valid_to"
+	if h != nil {
+		if len(h.Table) > 0 {
+			history = h.Table
+		}
+
+		if len(h.ValidFrom) > 0 {
+			validFrom = h.ValidFrom
+		}
+
+		if len(h.ValidTo) > 0 {
+			validTo = h.ValidTo
+		}
+	}
+
+	cond := " WHERE " + t.quote(validFrom) + " <= ? AND (" + t.quote(validTo) + " IS NULL OR " + t.quote(validTo) + " > ?)"
+	c := *t
+	c.name = alias
+	c.source = &Expression{
+		SQL:  "SELECT * FROM " + t.quote(table) + cond + " UNION ALL SELECT * FROM " + t.quote(history) + cond,
+		Args: []interface{}{ts, ts, ts, ts},
+	}
+	return &c
+}
@@ -0,0 +1,28 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTable_AsOf(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(ddlProduct{}))
+	ts := time.Unix(1600000000, 0)
+	tbl := &Table{driverName: "sqlserver", name: "products p", opts: &options{}}
+	c := tbl.Columns("id").AsOf(ts)
+	query, args := c.buildSelectQuery(c.selectInfo(info), "price > ?", []interface{}{1})
+	if query != "SELECT id FROM products FOR SYSTEM_TIME AS OF ? p WHERE price > ?" || !reflect.DeepEqual(args, []interface{}{ts, 1}) {
+		t.Error(query, args)
+	}
+
+	tbl = &Table{driverName: "postgres", name: "app.products", opts: &options{}}
+	c = tbl.Columns("id").AsOfHistory(ts, &History{ValidTo: "valid_until"})
+	query, args = c.buildSelectQuery(c.selectInfo(info), "price > ?", []interface{}{1})
+	expected := "SELECT id FROM (SELECT * FROM app.products WHERE valid_from <= ? AND (valid_until IS NULL OR valid_until > ?)" +
+		" UNION ALL SELECT * FROM app.products_history WHERE valid_from <= ? AND (valid_until IS NULL OR valid_until > ?)) products" +
+		" WHERE price > ?"
+	if query != expected || len(args) != 5 || args[4] != 1 {
+		t.Error(query, args)
+	}
+}
@@ -0,0 +1,195 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrNoTenant is returned if tenant scope is enabled but there's no tenant in context of a scoped statement
+	ErrNoTenant = errors.New("no tenant in context")
+
+	// ErrTenantMismatch is returned if tenant of a record differs from tenant in context
+	ErrTenantMismatch = errors.New("record belongs to another tenant")
+
+	// ErrUnscopedWrite is returned if a statement writing rows of a tenant table can't be scoped,
+	// e.g. Truncate or InsertFromSelect, unless the table is Unscoped
+	ErrUnscopedWrite = errors.New("write can't be scoped by tenant")
+
+	// ErrUnknownTenantTable is returned if tenant scope is enabled but a statement's table isn't passed
+	// to SetTenantScope by a model, and the statement has no model tagged by tenant
+	ErrUnknownTenantTable = errors.New("table isn't known by tenant scope")
+)
+
+type tenantKey struct{}
+
+// WithTenant returns a child context of ctx carrying tenant id, which is read by TenantFromContext
+func WithTenant(ctx context.Context, id interface{}) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// TenantFromContext returns tenant id set by WithTenant
+func TenantFromContext(ctx context.Context) (interface{}, bool) {
+	id := ctx.Value(tenantKey{})
+	return id, id != nil
+}
+
+// SetTenantScope scopes statements of models with a column tagged by tenant, e.g.
+//
+//	type Order struct {
+//		ID       int64
+//		TenantID int64 `sql:"tenant"`
+//	}
+//
+// fn extracts tenant id from context of statement, see Table.WithContext. TenantFromContext is used if fn is nil.
+// Selects, updates and deletes match tenant column with the tenant, and inserts set it. Statements of tables
+// without tagged models, e.g. db.Table("orders").Delete(...) or Count, are scoped by models passed by models,
+// whose tables are named in the current schema and naming strategy. Models without tenant column declare
+// tables shared by tenants. Statements of other tables return ErrUnknownTenantTable.
+// Writes which can't be scoped, e.g. Truncate, InsertFromSelect and ImportCSV, return ErrUnscopedWrite.
+// Table.Unscoped disables the scope
+func (d *DB) SetTenantScope(fn func(ctx context.Context) (interface{}, bool), models ...interface{}) {
+	if fn == nil {
+		fn = TenantFromContext
+	}
+
+	tables := make(map[string]string, len(models))
+	for _, m := range models {
+		typ := reflect.TypeOf(m)
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		tables[d.opts.qualify(getTableNameByType(typ))] = getColumnInfo(typ).tenantName
+	}
+	d.opts.tenant = fn
+	d.opts.tenantTables = tables
+}
+
+// Unscoped returns a copy of t without tenant scope, e.g. for administration across tenants
+func (t *Table) Unscoped() *Table {
+	c := *t
+	c.unscoped = true
+	return &c
+}
+
+// tenantColumn returns tenant column of info if t is scoped, otherwise empty string
+func (t *Table) tenantColumn(info *columnInfo) string {
+	if t.unscoped || t.opts == nil || t.opts.tenant == nil || info == nil {
+		return ""
+	}
+	return info.tenantName
+}
+
+// tenant returns tenant id in context of t
+func (t *Table) tenant() (interface{}, error) {
+	ctx := t.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	id, ok := t.opts.tenant(ctx)
+	if !ok {
+		return nil, ErrNoTenant
+	}
+	return id, nil
+}
+
+// tableTenantColumn returns tenant column of the table of t if t is scoped, which is known by models passed
+// to SetTenantScope. It's empty if the table is shared by tenants
+func (t *Table) tableTenantColumn() (string, error) {
+	if t.unscoped || t.opts == nil || t.opts.tenant == nil || t.source != nil {
+		return "", nil
+	}
+
+	name := t.name
+	if fields := strings.Fields(name); len(fields) > 0 {
+		name = fields[0]
+	}
+
+	column, ok := t.opts.tenantTables[t.opts.qualify(name)]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTenantTable, name)
+	}
+	return column, nil
+}
+
+// checkScopedWrite returns ErrUnscopedWrite if t is a scoped tenant table, whose rows are written without tenant
+func (t *Table) checkScopedWrite() error {
+	column, err := t.tableTenantColumn()
+	if err != nil {
+		return err
+	}
+
+	if len(column) > 0 {
+		return ErrUnscopedWrite
+	}
+	return nil
+}
+
+// checkScopedRecord returns an error if t is scoped and records of info are written without tenant,
+// i.e. info isn't tagged by tenant and the table isn't shared by tenants
+func (t *Table) checkScopedRecord(info *columnInfo) error {
+	if len(t.tenantColumn(info)) > 0 {
+		return nil
+	}
+	return t.checkScopedWrite()
+}
+
+// scopeWhere appends tenant condition to where if t is scoped. Tenant column of the table is used
+// if info is nil or isn't tagged, e.g. columns selected into a projection
+func (t *Table) scopeWhere(info *columnInfo, where string, args []interface{}) (string, []interface{}, error) {
+	column := t.tenantColumn(info)
+	if len(column) == 0 {
+		var err error
+		if column, err = t.tableTenantColumn(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(column) == 0 {
+		return where, args, nil
+	}
+
+	id, err := t.tenant()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(t.joins) > 0 {
+		column = t.hintTarget() + "." + column
+	}
+
+	cond := t.quote(column) + " = ?"
+	if len(where) > 0 {
+		cond = "(" + where + ") AND " + cond
+	}
+	return cond, append(append([]interface{}(nil), args...), id), nil
+}
+
+// tenantValue returns tenant id as value of tenant field of v, and sets the field if it's zero
+func (t *Table) tenantValue(v reflect.Value, info *columnInfo) (interface{}, error) {
+	id, err := t.tenant()
+	if err != nil {
+		return nil, err
+	}
+
+	f := v.FieldByIndex(info.nameToIndex[info.tenantName])
+	tv := reflect.ValueOf(id)
+	if !tv.Type().ConvertibleTo(f.Type()) {
+		panic(fmt.Sprintf("cannot convert tenant %v to %v", tv.Type(), f.Type()))
+	}
+	tv = tv.Convert(f.Type())
+
+	if f.IsZero() {
+		if f.CanSet() {
+			f.Set(tv)
+		}
+	} else if f.Interface() != tv.Interface() {
+		return nil, ErrTenantMismatch
+	}
+	return tv.Interface(), nil
+}
@@ -0,0 +1,169 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+type tenantItem struct {
+	ID       int64 `sql:"primary key"`
+	TenantID int64 `sql:"tenant"`
+	Name     string
+}
+
+func TestDB_SetTenantScope(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetTenantScope(nil)
+	f.On("SELECT").Returns([]string{"id", "tenant_id", "name"}, []interface{}{1, 7, "a"})
+
+	var items []*tenantItem
+	if err := db.Select(&items, "name = ?", "a"); err != sql.ErrNoTenant {
+		t.Fatal(err)
+	}
+
+	tbl := db.Table("tenant_item").WithContext(sql.WithTenant(context.Background(), int64(7)))
+	if err := tbl.Select(&items, "name = ?", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "SELECT id, tenant_id, name FROM tenant_item WHERE (name = ?) AND tenant_id = ?" ||
+		!reflect.DeepEqual(s.Args, []interface{}{"a", int64(7)}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	item := &tenantItem{ID: 2, Name: "b"}
+	if err := tbl.Insert(item); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); !reflect.DeepEqual(s.Args, []interface{}{int64(2), int64(7), "b"}) || item.TenantID != 7 {
+		t.Error(s.Args, item.TenantID)
+	}
+
+	if err := tbl.Update(item); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "UPDATE tenant_item SET tenant_id = ?, name = ? WHERE id = ? and tenant_id = ?" {
+		t.Error(s.Query)
+	}
+
+	if err := tbl.Update(&tenantItem{ID: 2, TenantID: 8}); err != sql.ErrTenantMismatch {
+		t.Error(err)
+	}
+
+	if err := tbl.Unscoped().Select(&items, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "SELECT id, tenant_id, name FROM tenant_item" {
+		t.Error(s.Query)
+	}
+}
+
+type tenantOrder struct {
+	ID       int64
+	TenantID int64 `sql:"tenant"`
+	Status   string
+}
+
+func TestDB_SetTenantScope_tables(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetTenantScope(nil, tenantOrder{})
+	f.On("SELECT COUNT").Returns([]string{"count"}, []interface{}{3})
+
+	if err := db.Table("tenant_orders").Delete("status = ?", "closed"); err != sql.ErrNoTenant {
+		t.Fatal(err)
+	}
+
+	tbl := db.Table("tenant_orders").WithContext(sql.WithTenant(context.Background(), int64(7)))
+	if err := tbl.Delete("status = ?", "closed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "DELETE FROM tenant_orders WHERE (status = ?) AND tenant_id = ?" ||
+		!reflect.DeepEqual(s.Args, []interface{}{"closed", int64(7)}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	if err := tbl.UpdateColumnsMap(map[string]interface{}{"status": "open"}, "id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "UPDATE tenant_orders SET status = ? WHERE (id = ?) AND tenant_id = ?" {
+		t.Error(s.Query)
+	}
+
+	if n, err := tbl.Count(""); err != nil || n != 3 {
+		t.Fatal(n, err)
+	}
+
+	if s := f.LastStatement(); s.Query != "SELECT COUNT(*) FROM tenant_orders WHERE tenant_id = ?" {
+		t.Error(s.Query)
+	}
+
+	if err := tbl.Truncate(nil); err != sql.ErrUnscopedWrite {
+		t.Error(err)
+	}
+
+	if err := tbl.Unscoped().Delete("status = ?", "closed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "DELETE FROM tenant_orders WHERE status = ?" {
+		t.Error(s.Query)
+	}
+}
+
+type tenantRegion struct {
+	ID   int64
+	Name string
+}
+
+func TestDB_SetTenantScope_unknownTable(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetTenantScope(nil, tenantRegion{})
+	ctx := sql.WithTenant(context.Background(), int64(7))
+	if err := db.Table("tenant_orders").WithContext(ctx).Delete("status = ?", "closed"); !errors.Is(err, sql.ErrUnknownTenantTable) {
+		t.Error(err)
+	}
+
+	if _, err := db.Table("tenant_orders").WithContext(ctx).Count(""); !errors.Is(err, sql.ErrUnknownTenantTable) {
+		t.Error(err)
+	}
+
+	if err := db.Table("tenant_orders").WithContext(ctx).Insert(&fakeItem{ID: 1}); !errors.Is(err, sql.ErrUnknownTenantTable) {
+		t.Error(err)
+	}
+
+	if len(f.Statements()) != 0 {
+		t.Fatal(f.Statements())
+	}
+
+	if err := db.Table("tenant_regions").WithContext(ctx).Delete("id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "DELETE FROM tenant_regions WHERE id = ?" {
+		t.Error(s.Query)
+	}
+
+	// scope of another DB doesn't leak
+	other, f := gosqltest.New("mysql")
+	other.SetTenantScope(nil, tenantOrder{})
+	if err := db.Table("tenant_orders").WithContext(ctx).Delete("status = ?", "closed"); !errors.Is(err, sql.ErrUnknownTenantTable) {
+		t.Error(err)
+	}
+
+	if err := other.Table("tenant_orders").WithContext(ctx).Delete("status = ?", "closed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "DELETE FROM tenant_orders WHERE (status = ?) AND tenant_id = ?" {
+		t.Error(s.Query)
+	}
+}
@@ -0,0 +1,92 @@
+package sql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// _timeLayouts are layouts of DATETIME, DATE and TIMESTAMP text returned by drivers, e.g. mysql without parseTime
+var _timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02",
+}
+
+// SetTimeLocation sets location of DATETIME, DATE and TIMESTAMP values which are returned as text without time zone,
+// e.g. by mysql without parseTime in DSN. Default is UTC
+func (d *DB) SetTimeLocation(loc *time.Location) {
+	if loc == nil {
+		panic("location is nil")
+	}
+	d.opts.timeLocation = loc
+}
+
+// isTimeType reports whether typ is time.Time or *time.Time
+func isTimeType(typ reflect.Type) bool {
+	return typ == _timeType || (typ.Kind() == reflect.Ptr && typ.Elem() == _timeType)
+}
+
+// timeValue is a holder scanning time.Time, or text of it in location loc
+type timeValue struct {
+	loc   *time.Location
+	time  time.Time
+	valid bool
+}
+
+func (v *timeValue) Scan(src interface{}) error {
+	v.time, v.valid = time.Time{}, src != nil
+	switch s := src.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		v.time = s
+		return nil
+	case []byte:
+		return v.parse(string(s))
+	case string:
+		return v.parse(s)
+	default:
+		return fmt.Errorf("cannot scan %T into time.Time", src)
+	}
+}
+
+func (v *timeValue) parse(s string) error {
+	// zero dates of mysql
+	if strings.HasPrefix(s, "0000-00-00") {
+		return nil
+	}
+
+	loc := v.loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layout := range _timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			v.time = t
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q into time.Time", s)
+}
+
+// set sets field f, which is time.Time or *time.Time. NULL leaves time.Time unchanged, and sets *time.Time to nil
+func (v *timeValue) set(f reflect.Value) {
+	if f.Kind() == reflect.Ptr {
+		if !v.valid {
+			f.Set(reflect.Zero(f.Type()))
+			return
+		}
+		t := v.time
+		f.Set(reflect.ValueOf(&t))
+		return
+	}
+
+	if v.valid {
+		f.Set(reflect.ValueOf(v.time))
+	}
+}
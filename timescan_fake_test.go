@@ -0,0 +1,38 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+	"time"
+)
+
+func TestDB_SetTimeLocation(t *testing.T) {
+	type event struct {
+		ID        int64      `sql:"primary key"`
+		CreatedAt time.Time  `sql:"created_at"`
+		DeletedAt *time.Time `sql:"deleted_at"`
+	}
+
+	db, f := gosqltest.New("mysql")
+	loc := time.FixedZone("UTC+8", 8*3600)
+	db.SetTimeLocation(loc)
+	f.On("SELECT id, created_at, deleted_at").Returns([]string{"id", "created_at", "deleted_at"},
+		[]interface{}{1, []byte("2024-01-02 03:04:05"), nil})
+
+	var e event
+	if err := db.Table("events").SelectOne(&e, "id = ?", 1); err != nil {
+		t.Fatal(err, f.Statements())
+	}
+	if !e.CreatedAt.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, loc)) || e.DeletedAt != nil {
+		t.Error(e)
+	}
+
+	f.On("SELECT created_at").Returns([]string{"created_at"}, []interface{}{"2024-01-02"})
+	var days []time.Time
+	if err := db.Table("events").Columns("created_at").Select(&days, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 1 || !days[0].Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, loc)) {
+		t.Error(days)
+	}
+}
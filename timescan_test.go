@@ -0,0 +1,67 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeValue(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	v := &timeValue{loc: loc}
+	for _, s := range []string{"2024-01-02 03:04:05", "2024-01-02T03:04:05", "2024-01-02 03:04:05+08:00"} {
+		if err := v.Scan([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		if !v.valid || !v.time.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, loc)) {
+			t.Error(s, v.time)
+		}
+	}
+
+	if err := v.Scan("2024-01-02 03:04:05.123456"); err != nil || v.time.Nanosecond() != 123456000 {
+		t.Error(err, v.time)
+	}
+
+	if err := v.Scan("2024-01-02"); err != nil || v.time.Location() != loc || v.time.Day() != 2 {
+		t.Error(err, v.time)
+	}
+
+	if err := v.Scan("0000-00-00 00:00:00"); err != nil || !v.valid || !v.time.IsZero() {
+		t.Error(err, v.time)
+	}
+
+	if err := v.Scan(nil); err != nil || v.valid {
+		t.Error(err, v.valid)
+	}
+
+	if err := v.Scan("yesterday"); err == nil {
+		t.Error("no error")
+	}
+
+	var p *time.Time
+	v.Scan("2024-01-02")
+	v.set(reflect.ValueOf(&p).Elem())
+	if p == nil || p.Year() != 2024 {
+		t.Error(p)
+	}
+
+	v.Scan(nil)
+	v.set(reflect.ValueOf(&p).Elem())
+	if p != nil {
+		t.Error(p)
+	}
+}
+
+func TestParseColumnInfo_untaggedTime(t *testing.T) {
+	type event struct {
+		ID        int64 `sql:"primary key"`
+		CreatedAt time.Time
+		UpdatedAt *time.Time
+		DeletedAt *time.Time `sql:"deleted_at"`
+	}
+
+	info := parseColumnInfo(reflect.TypeOf(event{}))
+	if !reflect.DeepEqual(info.names, []string{"id", "deleted_at"}) {
+		t.Error(info.names)
+	}
+}
@@ -0,0 +1,80 @@
+package sql
+
+import (
+	"reflect"
+)
+
+// ToSQL returns sql and args of e
+func (e *Expression) ToSQL() (string, []interface{}) {
+	return e.SQL, e.Args
+}
+
+// ToSQL returns query and args of s, which can be executed as they are by DB.Exec or DB.Query
+func (s *Statement) ToSQL() (string, []interface{}) {
+	return s.Query, s.Args
+}
+
+// statement returns query and args as executed by t
+func (t *Table) statement(query string, args []interface{}) *Statement {
+	return &Statement{Query: t.annotate(query), Args: args, driverName: t.driverName}
+}
+
+// SelectStatement returns the statement of Select without executing it. records is a pointer to slice or struct
+func (t *Table) SelectStatement(records interface{}, where string, args ...interface{}) *Statement {
+	typ := reflect.TypeOf(records)
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+
+	var info, checked *columnInfo
+	scalar := isScalarType(typ)
+	if scalar {
+		info = t.scalarInfo()
+	} else {
+		info = t.getSelectColumnInfo(typ)
+		checked = info
+	}
+	if err := t.checkWhere(checked, where, args); err != nil {
+		panic(err)
+	}
+	where, args = expandExpressions(where, args)
+	where, args, err := t.scopeWhere(info, where, args)
+	if err != nil {
+		panic(err)
+	}
+	if !scalar {
+		info = t.selectInfo(info)
+	}
+	return t.statement(t.buildSelectQuery(info, where, args))
+}
+
+// InsertStatement returns the statement of Insert without executing it
+func (t *Table) InsertStatement(record interface{}) *Statement {
+	query, args, err := t.prepareInsertQuery(record)
+	if err != nil {
+		panic(err)
+	}
+	return t.statement(query, args)
+}
+
+// UpdateStatement returns the statement of Update without executing it
+func (t *Table) UpdateStatement(record interface{}) *Statement {
+	query, args, err := t.prepareUpdateQuery(record)
+	if err != nil {
+		panic(err)
+	}
+	return t.statement(query, args)
+}
+
+// DeleteStatement returns the statement of Delete without executing it
+func (t *Table) DeleteStatement(where string, args ...interface{}) *Statement {
+	if len(where) == 0 && !t.allRows {
+		panic("where is empty")
+	}
+
+	if err := t.checkWhere(nil, where, args); err != nil {
+		panic(err)
+	}
+	where, args = expandExpressions(where, args)
+	return t.statement(t.prepareDeleteQuery(where), args)
+}
@@ -0,0 +1,30 @@
+package sql_test
+
+import (
+	"github.com/gopub/sql/gosqltest"
+	"reflect"
+	"testing"
+)
+
+func TestTable_SelectStatement(t *testing.T) {
+	db, f := gosqltest.New("postgres")
+	var items []*fakeItem
+	query, args := db.Table("items").Limit(10).SelectStatement(&items, "name = ?", "a").ToSQL()
+	if query != "SELECT id, name FROM items WHERE name = $1 LIMIT 10" || !reflect.DeepEqual(args, []interface{}{"a"}) {
+		t.Error(query, args)
+	}
+
+	query, args = db.Table("items").UpdateStatement(&fakeItem{ID: 1, Name: "b"}).ToSQL()
+	if query != "UPDATE items SET name = $1 WHERE id = $2" || !reflect.DeepEqual(args, []interface{}{"b", int64(1)}) {
+		t.Error(query, args)
+	}
+
+	query, args = db.Table("items").DeleteStatement("id = ?", 1).ToSQL()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "DELETE FROM items WHERE id = $1" {
+		t.Error(s.Query)
+	}
+}
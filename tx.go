@@ -1,17 +1,95 @@
 package sql
 
 import (
+	"context"
 	"database/sql"
 	"github.com/gopub/log"
 )
 
+// TxOptions are options of DB.BeginTx
+type TxOptions = sql.TxOptions
+
+// IsolationLevel is isolation level of transaction
+type IsolationLevel = sql.IsolationLevel
+
+const (
+	LevelDefault         = sql.LevelDefault
+	LevelReadUncommitted = sql.LevelReadUncommitted
+	LevelReadCommitted   = sql.LevelReadCommitted
+	LevelWriteCommitted  = sql.LevelWriteCommitted
+	LevelRepeatableRead  = sql.LevelRepeatableRead
+	LevelSnapshot        = sql.LevelSnapshot
+	LevelSerializable    = sql.LevelSerializable
+	LevelLinearizable    = sql.LevelLinearizable
+)
+
+// TxAbortedError is returned by statements of Tx whose context is done, and the Tx has been rolled back
+type TxAbortedError struct {
+	// Err is error of the context, i.e. context.Canceled or context.DeadlineExceeded
+	Err error
+}
+
+func (e *TxAbortedError) Error() string {
+	return "transaction rolled back: " + e.Err.Error()
+}
+
+func (e *TxAbortedError) Unwrap() error {
+	return e.Err
+}
+
 type Tx struct {
 	tx         *sql.Tx
+	exe        Executor
+	ctx        context.Context
 	driverName string
+	opts       *options
+
+	//savepoints counts savepoints to name them uniquely
+	savepoints int32
+
+	//writes records written tables if results are cached, see DB.SetCache
+	writes *txWrites
+}
+
+func newTx(tx *sql.Tx, ctx context.Context, driverName string, opts *options) *Tx {
+	t := &Tx{
+		tx:         tx,
+		ctx:        ctx,
+		driverName: driverName,
+		opts:       opts,
+	}
+
+	if opts.cache != nil {
+		t.writes = &txWrites{next: tx}
+		t.exe = opts.wrap(t.writes)
+	} else {
+		t.exe = opts.wrap(tx)
+	}
+	return t
+}
+
+// Context returns the context which the transaction began with
+func (t *Tx) Context() context.Context {
+	return t.ctx
 }
 
 func (t *Tx) Commit() error {
-	return t.tx.Commit()
+	if err := t.tx.Commit(); err != nil {
+		return txError(t.ctx, err)
+	}
+
+	if t.writes != nil {
+		t.writes.invalidate(t.ctx, t.opts.cache)
+	}
+	return nil
+}
+
+// txError returns TxAbortedError if err is caused by done context of Tx
+func txError(ctx context.Context, err error) error {
+	if err != nil && ctx != nil && ctx.Err() != nil {
+		return &TxAbortedError{Err: ctx.Err()}
+	}
+	return err
 }
 
 func (t *Tx) Rollback() error {
@@ -20,9 +98,13 @@ func (t *Tx) Rollback() error {
 
 func (t *Tx) Table(name string) *Table {
 	return &Table{
-		exe:        t.tx,
+		exe:        t.exe,
+		reader:     t.exe,
+		tx:         t.tx,
 		driverName: t.driverName,
-		name:       name,
+		name:       t.opts.qualify(name),
+		opts:       t.opts,
+		ctx:        t.ctx,
 	}
 }
 
@@ -34,6 +116,10 @@ func (t *Tx) Update(record interface{}) error {
 	return t.Table(getTableName(record)).Update(record)
 }
 
+func (t *Tx) Delete(record interface{}) error {
+	return t.Table(getTableName(record)).DeleteRecord(record)
+}
+
 func (t *Tx) Save(record interface{}) error {
 	return t.Table(getTableName(record)).Save(record)
 }
@@ -42,11 +128,74 @@ func (t *Tx) Select(records interface{}, where string, args ...interface{}) erro
 	return t.Table(getTableNameBySlice(records)).Select(records, where, args...)
 }
 
+func (t *Tx) SelectColumns(records interface{}, columns []string, where string, args ...interface{}) error {
+	return t.Table(getTableNameBySlice(records)).SelectColumns(records, columns, where, args...)
+}
+
+func (t *Tx) FindInBatches(records interface{}, batchSize int, fn func(batch int) error, where string, args ...interface{}) error {
+	return t.Table(getTableNameBySlice(records)).FindInBatches(records, batchSize, fn, where, args...)
+}
+
+func (t *Tx) SelectAtMost(records interface{}, n int, where string, args ...interface{}) error {
+	return t.Table(getTableNameBySlice(records)).SelectAtMost(records, n, where, args...)
+}
+
+func (t *Tx) From(sub *Expression, alias string) *Table {
+	tbl := t.Table(alias)
+	tbl.name = alias
+	tbl.source = sub
+	return tbl
+}
+
+func (t *Tx) Get(record interface{}, pk ...interface{}) error {
+	return t.Table(getTableName(record)).Get(record, pk...)
+}
+
 func (t *Tx) SelectOne(record interface{}, where string, args ...interface{}) error {
 	return t.Table(getTableName(record)).SelectOne(record, where, args...)
 }
 
 func (t *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
-	log.Debug(query, toReadableArgs(args))
-	return t.tx.Exec(query, args...)
+	return t.ExecContext(t.ctx, query, args...)
+}
+
+// ExecContext executes query in ctx, which is usually a child of the context of t
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	t.opts.logQuery(t.driverName, query, args)
+	ctx, cancel := t.opts.context(ctx)
+	defer cancel()
+	result, err := t.exe.ExecContext(ctx, query, args...)
+	return result, txError(t.ctx, err)
+}
+
+// WithTx runs fn in a transaction of default isolation level, which is committed if fn returns nil, otherwise rolled back
+func (d *DB) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	return d.WithTxIsolation(ctx, LevelDefault, fn)
+}
+
+// WithTxIsolation runs fn in a transaction of level, which is committed if fn returns nil, otherwise rolled back
+func (d *DB) WithTxIsolation(ctx context.Context, level IsolationLevel, fn func(tx *Tx) error) error {
+	tx, err := d.BeginTx(ctx, &TxOptions{Isolation: level})
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error(err)
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,198 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// DB returns a DB whose statements run in the transaction, e.g. to pass the transaction to code depending on DB in tests.
+// Transactions begun by the returned DB are savepoints of this transaction. It's invalid once the transaction ends.
+// Options are copied, so settings like Use or SetSchema of the returned DB don't change the DB of t.
+// The returned DB should be closed when it's no longer used, which doesn't end the transaction
+func (t *Tx) DB() *DB {
+	c := &txConnector{tx: t.tx, driverName: t.driverName, savepoints: &t.savepoints}
+	opts := *t.opts
+	d := &DB{
+		db:         sql.OpenDB(c),
+		driverName: t.driverName,
+		opts:       &opts,
+	}
+	opts.keys = &keyResolver{db: d}
+	return d
+}
+
+// savepointQueries returns statements to set, release and roll back to savepoint name
+func savepointQueries(driverName, name string) (set, release, rollback string) {
+	switch driverName {
+	case "sqlserver", "mssql":
+		return "SAVE TRANSACTION " + name, "", "ROLLBACK TRANSACTION " + name
+	default:
+		return "SAVEPOINT " + name, "RELEASE SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name
+	}
+}
+
+// txConnector connects to a transaction, all of its connections share the transaction
+type txConnector struct {
+	tx         *sql.Tx
+	driverName string
+	savepoints *int32
+}
+
+func (c *txConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &txConn{c: c}, nil
+}
+
+func (c *txConnector) Driver() driver.Driver {
+	return txDriver{c: c}
+}
+
+type txDriver struct {
+	c *txConnector
+}
+
+func (d txDriver) Open(name string) (driver.Conn, error) {
+	return &txConn{c: d.c}, nil
+}
+
+type txConn struct {
+	c *txConnector
+}
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) {
+	return &txStmt{c: c, query: query}, nil
+}
+
+func (c *txConn) Close() error {
+	return nil
+}
+
+func (c *txConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx sets a savepoint, options are ignored as they're decided by the transaction
+func (c *txConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	name := fmt.Sprintf("sp_%d", atomic.AddInt32(c.c.savepoints, 1))
+	set, release, rollback := savepointQueries(c.c.driverName, name)
+	if _, err := c.c.tx.ExecContext(ctx, set); err != nil {
+		return nil, err
+	}
+	return &txSavepoint{tx: c.c.tx, release: release, rollback: rollback}, nil
+}
+
+// CheckNamedValue passes args as they are, they're converted by driver of the transaction
+func (c *txConn) CheckNamedValue(v *driver.NamedValue) error {
+	return nil
+}
+
+func (c *txConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.c.tx.ExecContext(ctx, query, txArgs(args)...)
+}
+
+func (c *txConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.c.tx.QueryContext(ctx, query, txArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return &txRows{rows: rows}, nil
+}
+
+func txArgs(args []driver.NamedValue) []interface{} {
+	l := make([]interface{}, len(args))
+	for i, a := range args {
+		if len(a.Name) > 0 {
+			l[i] = sql.Named(a.Name, a.Value)
+		} else {
+			l[i] = a.Value
+		}
+	}
+	return l
+}
+
+type txSavepoint struct {
+	tx       *sql.Tx
+	release  string
+	rollback string
+}
+
+func (s *txSavepoint) Commit() error {
+	if len(s.release) == 0 {
+		return nil
+	}
+	_, err := s.tx.Exec(s.release)
+	return err
+}
+
+func (s *txSavepoint) Rollback() error {
+	_, err := s.tx.Exec(s.rollback)
+	return err
+}
+
+type txStmt struct {
+	c     *txConn
+	query string
+}
+
+func (s *txStmt) Close() error {
+	return nil
+}
+
+func (s *txStmt) NumInput() int {
+	return -1
+}
+
+func (s *txStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *txStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	l := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		l[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return l
+}
+
+type txRows struct {
+	rows *sql.Rows
+}
+
+func (r *txRows) Columns() []string {
+	columns, _ := r.rows.Columns()
+	return columns
+}
+
+func (r *txRows) Close() error {
+	return r.rows.Close()
+}
+
+func (r *txRows) Next(dest []driver.Value) error {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	values := make([]interface{}, len(dest))
+	ptrs := make([]interface{}, len(dest))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := r.rows.Scan(ptrs...); err != nil {
+		return err
+	}
+
+	for i, v := range values {
+		dest[i] = v
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package sql
+
+// compound is a query combined with the query of t by UNION or UNION ALL
+type compound struct {
+	all bool
+	sub *Expression
+}
+
+// Union returns a copy of t whose Select, SelectOne and Iterate combine their rows with rows of sub,
+// and remove duplicate rows. ORDER BY, LIMIT and OFFSET of t apply to the combined rows, e.g. searching several tables
+//
+//	articles := db.Table("articles").Columns("id", "title").Subquery("title LIKE ?", "%go%")
+//	db.Table("posts").Columns("id", "title").Union(articles).OrderBy("title").Select(&results, "title LIKE ?", "%go%")
+//
+// Columns of sub must match columns selected by t
+func (t *Table) Union(sub *Expression) *Table {
+	return t.appendCompound(sub, false)
+}
+
+// UnionAll combines rows like Union, but keeps duplicate rows
+func (t *Table) UnionAll(sub *Expression) *Table {
+	return t.appendCompound(sub, true)
+}
+
+func (t *Table) appendCompound(sub *Expression, all bool) *Table {
+	if sub == nil {
+		panic("sub is nil")
+	}
+	c := *t
+	c.unions = append(append([]compound(nil), t.unions...), compound{all: all, sub: sub})
+	return &c
+}
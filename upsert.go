@@ -0,0 +1,375 @@
+package sql
+
+import (
+	"github.com/gopub/log"
+	"github.com/gopub/utils"
+	"reflect"
+	"strings"
+)
+
+// UpsertOptions controls what Upsert does on conflict
+type UpsertOptions struct {
+	// ConflictColumns form the conflict target, default is primary key.
+	// It's ignored by mysql, which checks all primary and unique keys
+	ConflictColumns []string
+
+	// UpdateColumns are updated on conflict, default is all columns except conflict columns
+	UpdateColumns []string
+
+	// DoNothing keeps the existing row on conflict
+	DoNothing bool
+}
+
+// ConflictStrategy is the way MultiSaveWithStrategy handles records conflicting with existing rows
+type ConflictStrategy int
+
+const (
+	// UpdateOnConflict updates conflicting rows with records, the same as Save
+	UpdateOnConflict ConflictStrategy = iota
+
+	// SkipConflicts keeps conflicting rows and skips the records, the same as InsertIgnore
+	SkipConflicts
+
+	// FailFast fails at the first conflicting record, the same as MultiInsert
+	FailFast
+)
+
+// Upsert inserts record, or updates the conflicting row as specified by opts. nil opts is the same as Save
+func (t *Table) Upsert(record interface{}, opts *UpsertOptions) error {
+	if opts == nil {
+		return t.Save(record)
+	}
+
+	query, values, err := t.prepareUpsertQuery(record, opts)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	t.logQuery(query, values)
+
+	result, err := t.exec(query, values...)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	v := getStructValue(record)
+	info := getColumnInfo(v.Type())
+	if len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
+		id, err := result.LastInsertId()
+		if err != nil {
+			log.Error(err)
+			return err
+		}
+
+		// id is 0 if nothing is inserted
+		if id > 0 {
+			v.FieldByIndex(info.nameToIndex[info.aiName]).SetInt(id)
+		}
+	}
+	return nil
+}
+
+func (t *Table) prepareUpsertQuery(record interface{}, opts *UpsertOptions) (string, []interface{}, error) {
+	query, values, err := t.prepareInsertQuery(record)
+	if err != nil {
+		return "", nil, err
+	}
+
+	info := getColumnInfo(getStructValue(record).Type())
+	return query + t.onConflict(info, opts), values, nil
+}
+
+// onConflict returns the conflict clause of opts, which is appended to insert query
+func (t *Table) onConflict(info *columnInfo, opts *UpsertOptions) string {
+	conflict := opts.ConflictColumns
+	if len(conflict) == 0 {
+		conflict = info.pkNames
+	}
+
+	update := opts.UpdateColumns
+	if len(update) == 0 && !opts.DoNothing {
+		for _, name := range info.names {
+			if utils.IndexOfString(conflict, name) < 0 {
+				update = append(update, name)
+			}
+		}
+	}
+
+	for _, names := range [][]string{conflict, update} {
+		for _, name := range names {
+			if _, ok := info.nameToIndex[name]; !ok {
+				panic("unknown column: " + name)
+			}
+		}
+	}
+
+	doNothing := opts.DoNothing || len(update) == 0
+	buf := getBuffer()
+	defer putBuffer(buf)
+	switch {
+	case t.driverName == "mysql":
+		buf.WriteString(" ON DUPLICATE KEY UPDATE ")
+		if doNothing {
+			// Assigning a column to itself changes nothing, unlike INSERT IGNORE which ignores other errors as well
+			buf.WriteString(t.quote(info.names[0]))
+			buf.WriteString(" = ")
+			buf.WriteString(t.quote(info.names[0]))
+			break
+		}
+
+		for i, name := range update {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			value := "VALUES(" + t.quote(name) + ")"
+			if tenant := t.tenantColumn(info); len(tenant) > 0 {
+				// rows of other tenants with the same key are left unchanged
+				value = "IF(" + t.quote(tenant) + " = VALUES(" + t.quote(tenant) + "), " + value + ", " + t.quote(name) + ")"
+			}
+			buf.WriteString(t.quote(name))
+			buf.WriteString(" = ")
+			buf.WriteString(value)
+		}
+	case t.driverName == "sqlite3" || isPostgres(t.driverName):
+		buf.WriteString(" ON CONFLICT")
+		if len(conflict) > 0 {
+			buf.WriteString(" (")
+			buf.WriteString(t.quoteColumns(conflict))
+			buf.WriteString(")")
+		} else if !doNothing {
+			panic("no conflict columns. please specify ConflictColumns")
+		}
+
+		if doNothing {
+			buf.WriteString(" DO NOTHING")
+			break
+		}
+
+		buf.WriteString(" DO UPDATE SET ")
+		for i, name := range update {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(t.quote(name))
+			buf.WriteString(" = EXCLUDED.")
+			buf.WriteString(t.quote(name))
+		}
+
+		if tenant := t.tenantColumn(info); len(tenant) > 0 {
+			buf.WriteString(" WHERE ")
+			buf.WriteString(t.quote(t.name))
+			buf.WriteString(".")
+			buf.WriteString(t.quote(tenant))
+			buf.WriteString(" = EXCLUDED.")
+			buf.WriteString(t.quote(tenant))
+		}
+	default:
+		panic("Upsert operation is not supported for driver: " + t.driverName)
+	}
+	return buf.String()
+}
+
+// BulkUpsert upserts records with multi-row statements, which are split to stay under the parameter limit
+// of driver. records is a slice of structs or pointers to structs. nil opts updates all columns except primary key
+// on conflict. Auto increment ids generated by database are not set back to records
+func (t *Table) BulkUpsert(records interface{}, opts *UpsertOptions) error {
+	v := reflect.ValueOf(records)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Slice {
+		panic("must be a slice")
+	}
+
+	if v.Len() == 0 {
+		return nil
+	}
+
+	if opts == nil {
+		opts = &UpsertOptions{}
+	}
+
+	typ := getStructValue(v.Index(0).Interface()).Type()
+	info := getColumnInfo(typ)
+
+	// Records without auto increment id are inserted without the column
+	var withID, withoutID []reflect.Value
+	for i := 0; i < v.Len(); i++ {
+		elem := getStructValue(v.Index(i).Interface())
+		if elem.Type() != typ {
+			panic("records must be of the same type")
+		}
+
+		if len(info.aiName) > 0 && elem.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
+			withoutID = append(withoutID, elem)
+		} else {
+			withID = append(withID, elem)
+		}
+	}
+
+	clause := t.onConflict(info, opts)
+	groups := []struct {
+		rows    []reflect.Value
+		columns []string
+	}{{withID, info.names}, {withoutID, info.notAINames}}
+	for _, g := range groups {
+		rows, columns := g.rows, g.columns
+		size := t.maxParams() / len(columns)
+		for i := 0; i < len(rows); i += size {
+			end := i + size
+			if end > len(rows) {
+				end = len(rows)
+			}
+
+			query, values, err := t.prepareBulkInsertQuery(info, columns, rows[i:end])
+			if err != nil {
+				log.Error(err)
+				return err
+			}
+			query += clause
+
+			t.logQuery(query, values)
+
+			if _, err = t.exec(query, values...); err != nil {
+				log.Error(err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *Table) prepareBulkInsertQuery(info *columnInfo, columns []string, rows []reflect.Value) (string, []interface{}, error) {
+	if err := t.checkScopedRecord(info); err != nil {
+		return "", nil, err
+	}
+
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	values := make([]interface{}, 0, len(columns)*len(rows))
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("INSERT INTO ")
+	buf.WriteString(t.quote(t.name))
+	buf.WriteString("(")
+	buf.WriteString(t.quoteColumns(columns))
+	buf.WriteString(") VALUES ")
+	for i, v := range rows {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(row)
+
+		for _, name := range columns {
+			fv, err := t.getFieldValueByName(v, info, name, true)
+			if err != nil {
+				return "", nil, err
+			}
+			values = append(values, fv)
+		}
+	}
+	return buf.String(), values, nil
+}
+
+// maxParams returns the max number of placeholders in a statement
+func (t *Table) maxParams() int {
+	if t.driverName == "sqlite3" {
+		// Limit of sqlite before 3.32.0
+		return 999
+	}
+	return 65535
+}
+
+// InsertIgnore inserts record unless it conflicts with an existing row, and returns whether it's inserted.
+// It renders INSERT IGNORE on mysql, and ON CONFLICT DO NOTHING on postgres and sqlite3
+func (t *Table) InsertIgnore(record interface{}) (bool, error) {
+	query, values, err := t.prepareInsertIgnoreQuery(record)
+	if err != nil {
+		log.Error(err)
+		return false, err
+	}
+
+	t.logQuery(query, values)
+
+	result, err := t.exec(query, values...)
+	if err != nil {
+		log.Error(err)
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		log.Error(err)
+		return false, err
+	}
+
+	if n == 0 {
+		return false, nil
+	}
+
+	v := getStructValue(record)
+	info := getColumnInfo(v.Type())
+	if len(info.aiName) > 0 && v.FieldByIndex(info.nameToIndex[info.aiName]).Int() == 0 {
+		id, err := result.LastInsertId()
+		if err != nil {
+			log.Error(err)
+			return true, err
+		}
+		v.FieldByIndex(info.nameToIndex[info.aiName]).SetInt(id)
+	}
+	return true, nil
+}
+
+func (t *Table) prepareInsertIgnoreQuery(record interface{}) (string, []interface{}, error) {
+	query, values, err := t.prepareInsertQuery(record)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch {
+	case t.driverName == "mysql":
+		query = strings.Replace(query, "INSERT INTO", "INSERT IGNORE INTO", 1)
+	case t.driverName == "sqlite3" || isPostgres(t.driverName):
+		query += " ON CONFLICT DO NOTHING"
+	default:
+		panic("InsertIgnore operation is not supported for driver: " + t.driverName)
+	}
+	return query, values, nil
+}
+
+func (d *DB) InsertIgnore(record interface{}) (bool, error) {
+	return d.Table(getTableName(record)).InsertIgnore(record)
+}
+
+func (t *Tx) InsertIgnore(record interface{}) (bool, error) {
+	return t.Table(getTableName(record)).InsertIgnore(record)
+}
+
+func (d *DB) Upsert(record interface{}, opts *UpsertOptions) error {
+	return d.Table(getTableName(record)).Upsert(record, opts)
+}
+
+func (t *Tx) Upsert(record interface{}, opts *UpsertOptions) error {
+	return t.Table(getTableName(record)).Upsert(record, opts)
+}
+
+// BulkUpsert upserts records within a transaction
+func (d *DB) BulkUpsert(records interface{}, opts *UpsertOptions) error {
+	tx, err := d.Begin()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if err = tx.BulkUpsert(records, opts); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (t *Tx) BulkUpsert(records interface{}, opts *UpsertOptions) error {
+	return t.Table(getTableNameBySlice(records)).BulkUpsert(records, opts)
+}
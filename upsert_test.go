@@ -0,0 +1,88 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTable_prepareUpsertQuery(t *testing.T) {
+	p := &ddlProduct{ID: 1, Name: "apple", Code: "a1", Price: 2}
+	tests := []struct {
+		driverName string
+		opts       *UpsertOptions
+		expected   string
+	}{
+		{"mysql", &UpsertOptions{UpdateColumns: []string{"price"}},
+			"INSERT INTO products(id, name, code, price, note, tags) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE price = VALUES(price)"},
+		{"mysql", &UpsertOptions{DoNothing: true},
+			"INSERT INTO products(id, name, code, price, note, tags) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE id = id"},
+		{"sqlite3", &UpsertOptions{ConflictColumns: []string{"code"}, UpdateColumns: []string{"name", "price"}},
+			"INSERT INTO products(id, name, code, price, note, tags) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name, price = EXCLUDED.price"},
+		{"postgres", &UpsertOptions{ConflictColumns: []string{"code"}, DoNothing: true},
+			"INSERT INTO products(id, name, code, price, note, tags) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (code) DO NOTHING"},
+		{"postgres", &UpsertOptions{},
+			"INSERT INTO products(id, name, code, price, note, tags) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, code = EXCLUDED.code, price = EXCLUDED.price, note = EXCLUDED.note, tags = EXCLUDED.tags"},
+	}
+
+	for _, test := range tests {
+		tbl := &Table{driverName: test.driverName, name: "products"}
+		query, values, err := tbl.prepareUpsertQuery(p, test.opts)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if query != test.expected {
+			t.Error(test.driverName, query)
+		}
+
+		if len(values) != 6 {
+			t.Error(values)
+		}
+	}
+}
+
+func TestTable_prepareInsertIgnoreQuery(t *testing.T) {
+	p := &ddlProduct{Name: "apple", Code: "a1", Price: 2}
+	tests := map[string]string{
+		"mysql":    "INSERT IGNORE INTO products(name, code, price, note, tags) VALUES (?, ?, ?, ?, ?)",
+		"sqlite3":  "INSERT INTO products(name, code, price, note, tags) VALUES (?, ?, ?, ?, ?) ON CONFLICT DO NOTHING",
+		"postgres": "INSERT INTO products(name, code, price, note, tags) VALUES (?, ?, ?, ?, ?) ON CONFLICT DO NOTHING",
+	}
+
+	for driverName, expected := range tests {
+		tbl := &Table{driverName: driverName, name: "products"}
+		query, _, err := tbl.prepareInsertIgnoreQuery(p)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if query != expected {
+			t.Error(driverName, query)
+		}
+	}
+}
+
+func TestTable_prepareBulkInsertQuery(t *testing.T) {
+	rows := []reflect.Value{
+		reflect.ValueOf(ddlProduct{ID: 1, Name: "apple", Code: "a1"}),
+		reflect.ValueOf(ddlProduct{ID: 2, Name: "pear", Code: "p1"}),
+	}
+	info := getColumnInfo(rows[0].Type())
+	tbl := &Table{driverName: "sqlite3", name: "products"}
+	query, values, err := tbl.prepareBulkInsertQuery(info, []string{"id", "name", "code"}, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query += tbl.onConflict(info, &UpsertOptions{UpdateColumns: []string{"name"}})
+	expected := "INSERT INTO products(id, name, code) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name"
+	if query != expected {
+		t.Error(query)
+	}
+
+	if len(values) != 6 || values[4] != "pear" {
+		t.Error(values)
+	}
+}
@@ -0,0 +1,195 @@
+package sql
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// UUID is stored as BINARY(16) in mysql, BLOB in sqlite, uuid in postgres and UNIQUEIDENTIFIER in sqlserver.
+// It's bound and scanned as 16 bytes or text by dialect, and scanned from either of them.
+// Zero UUID primary key is generated on insert, which is version 4, or version 7 if tagged by uuid=v7
+type UUID [16]byte
+
+var (
+	_uuidType = reflect.TypeOf(UUID{})
+
+	_ driver.Valuer = UUID{}
+	_ sql.Scanner   = (*UUID)(nil)
+)
+
+// NewUUID returns a random UUID of version 4
+func NewUUID() UUID {
+	var u UUID
+	if _, err := rand.Read(u[:]); err != nil {
+		panic(err)
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// NewUUIDv7 returns a UUID of version 7, which is ordered by its millisecond timestamp, e.g. for primary keys
+// inserted in order
+func NewUUIDv7() UUID {
+	u := NewUUID()
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(u[:6], ms[2:])
+	u[6] = u[6]&0x0f | 0x70
+	return u
+}
+
+// ParseUUID parses text like 6ba7b810-9dad-11d1-80b4-00c04fd430c8, with or without hyphens
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	switch len(s) {
+	case 32:
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, fmt.Errorf("invalid uuid: %s", s)
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	default:
+		return u, fmt.Errorf("invalid uuid: %s", s)
+	}
+
+	if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+		return u, fmt.Errorf("invalid uuid: %s", s)
+	}
+	return u, nil
+}
+
+func MustParseUUID(s string) UUID {
+	u, err := ParseUUID(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[:8], u[:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// Value returns text of u, which is bound as 16 bytes in mysql and sqlite
+func (u UUID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+func (u *UUID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*u = UUID{}
+		return nil
+	case []byte:
+		if len(v) == 16 {
+			copy(u[:], v)
+			return nil
+		}
+		return u.parse(string(v))
+	case string:
+		return u.parse(v)
+	default:
+		return fmt.Errorf("cannot scan %T into UUID", src)
+	}
+}
+
+func (u *UUID) parse(s string) error {
+	v, err := ParseUUID(s)
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u *UUID) UnmarshalText(text []byte) error {
+	return u.parse(string(text))
+}
+
+// isUUIDType reports whether typ is UUID or *UUID
+func isUUIDType(typ reflect.Type) bool {
+	return typ == _uuidType || (typ.Kind() == reflect.Ptr && typ.Elem() == _uuidType)
+}
+
+// isBinaryUUID reports whether UUID is stored as 16 bytes by driver
+func isBinaryUUID(driverName string) bool {
+	return driverName == "mysql" || driverName == "sqlite3"
+}
+
+// uuidValue returns value of UUID primary key field f, which is generated and set if it's zero
+func uuidValue(f reflect.Value, version int) interface{} {
+	u := f.Interface().(UUID)
+	if !u.IsZero() {
+		return u
+	}
+
+	if version == 7 {
+		u = NewUUIDv7()
+	} else {
+		u = NewUUID()
+	}
+	if f.CanSet() {
+		f.Set(reflect.ValueOf(u))
+	}
+	return u
+}
+
+// bindUUIDs returns args whose UUID values are replaced by 16 bytes, or args itself if there's no UUID
+func bindUUIDs(args []interface{}) []interface{} {
+	var bound []interface{}
+	for i, a := range args {
+		var b interface{}
+		switch v := a.(type) {
+		case UUID:
+			b = v[:]
+		case *UUID:
+			if v == nil {
+				continue
+			}
+			b = v[:]
+		case SensitiveValue:
+			u, ok := v.v.(UUID)
+			if !ok {
+				continue
+			}
+			b = Sensitive(u[:])
+		default:
+			continue
+		}
+
+		if bound == nil {
+			bound = append([]interface{}(nil), args...)
+		}
+		bound[i] = b
+	}
+
+	if bound == nil {
+		return args
+	}
+	return bound
+}
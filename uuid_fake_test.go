@@ -0,0 +1,41 @@
+package sql_test
+
+import (
+	"bytes"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestUUID_driver(t *testing.T) {
+	type account struct {
+		ID   sql.UUID `sql:"primary key"`
+		Name string
+	}
+
+	db, f := gosqltest.New("mysql")
+	a := &account{Name: "a"}
+	if err := db.Table("accounts").Insert(a); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); a.ID.IsZero() || !bytes.Equal(s.Args[0].([]byte), a.ID[:]) {
+		t.Error(s.Args)
+	}
+
+	f.On("SELECT id, name FROM accounts").Returns([]string{"id", "name"}, []interface{}{a.ID[:], "a"})
+	var got account
+	if err := db.Table("accounts").SelectOne(&got, "id = ?", a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got != *a {
+		t.Error(got)
+	}
+
+	db, f = gosqltest.New("postgres")
+	if err := db.Table("accounts").Insert(&account{ID: a.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if s := f.LastStatement(); s.Args[0] != a.ID.String() {
+		t.Error(s.Args)
+	}
+}
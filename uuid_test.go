@@ -0,0 +1,105 @@
+package sql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUUID(t *testing.T) {
+	s := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	u, err := ParseUUID(s)
+	if err != nil || u.String() != s {
+		t.Fatal(err, u)
+	}
+
+	if v, err := ParseUUID("6ba7b8109dad11d180b400c04fd430c8"); err != nil || v != u {
+		t.Error(err, v)
+	}
+
+	if _, err := ParseUUID("6ba7b810-9dad-11d1-80b4_00c04fd430c8"); err == nil {
+		t.Error("no error")
+	}
+
+	var v UUID
+	if err := v.Scan(u[:]); err != nil || v != u {
+		t.Error(err, v)
+	}
+
+	if err := v.Scan([]byte(s)); err != nil || v != u {
+		t.Error(err, v)
+	}
+
+	if err := v.Scan(nil); err != nil || !v.IsZero() {
+		t.Error(err, v)
+	}
+
+	r := NewUUID()
+	if r[6]>>4 != 4 || r[8]>>6 != 2 {
+		t.Error(r)
+	}
+
+	r = NewUUIDv7()
+	ms := int64(r[0])<<40 | int64(r[1])<<32 | int64(r[2])<<24 | int64(r[3])<<16 | int64(r[4])<<8 | int64(r[5])
+	if r[6]>>4 != 7 || r[8]>>6 != 2 || time.Since(time.UnixMilli(ms)) > time.Minute {
+		t.Error(r)
+	}
+
+	args := []interface{}{1, u, Sensitive(u)}
+	bound := bindUUIDs(args)
+	if !reflect.DeepEqual(bound[:2], []interface{}{1, u[:]}) || bound[2].(SensitiveValue).v.([]byte)[0] != u[0] {
+		t.Error(bound)
+	}
+	if args[1] != u {
+		t.Error("args are changed")
+	}
+}
+
+type uuidRecord struct {
+	ID       UUID `sql:"primary key,uuid=v7"`
+	ParentID *UUID
+}
+
+func TestUUID_insert(t *testing.T) {
+	info := getColumnInfo(reflect.TypeOf(uuidRecord{}))
+	if !reflect.DeepEqual(info.uuidVersions, map[string]int{"id": 7}) {
+		t.Fatal(info.uuidVersions)
+	}
+
+	tbl := &Table{driverName: "mysql", name: "uuid_records", opts: &options{}}
+	r := &uuidRecord{}
+	_, values, err := tbl.prepareInsertQuery(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.ID.IsZero() || r.ID[6]>>4 != 7 || values[0] != r.ID || values[1] != (*UUID)(nil) {
+		t.Error(r, values)
+	}
+
+	if tbl.columnType(reflect.TypeOf(r.ParentID), 0, false) != "BINARY(16)" {
+		t.Error(tbl.columnType(reflect.TypeOf(r.ParentID), 0, false))
+	}
+}
+
+func TestUUID_update(t *testing.T) {
+	tbl := &Table{driverName: "mysql", name: "uuid_records", opts: &options{}}
+	r := &uuidRecord{}
+	if _, _, err := tbl.prepareUpdateQuery(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if !r.ID.IsZero() {
+		t.Error("uuid is generated by update", r.ID)
+	}
+
+	v := reflect.ValueOf(r).Elem()
+	info := getColumnInfo(v.Type())
+	if id, err := tbl.fieldValue(v, info, "id", false); err != nil || id != (UUID{}) || !r.ID.IsZero() {
+		t.Error(id, err)
+	}
+
+	if id, err := tbl.fieldValue(v, info, "id", true); err != nil || id == (UUID{}) || id != r.ID {
+		t.Error(id, err)
+	}
+}
@@ -0,0 +1,37 @@
+// Package compat exposes signatures of github.com/gopub/sql v1 for code which isn't migrated to v2 yet.
+// Its types are aliases of v1, so values are passed between migrated and unmigrated packages, e.g.
+//
+//	db, err := sql.Open("mysql", dsn)  // v2
+//	legacy.Run(compat.Unwrap(db))      // func Run(db *compat.DB), which was func Run(db *gosql.DB) of v1
+//
+// gosql-fix rewrites calls of v1 to v2, and lists calls it can't resolve in its migration guide
+package compat
+
+import (
+	v1 "github.com/gopub/sql"
+	v2 "github.com/gopub/sql/v2"
+)
+
+type (
+	DB         = v1.DB
+	Tx         = v1.Tx
+	Table      = v1.Table
+	Expression = v1.Expression
+)
+
+var ErrNoRows = v1.ErrNoRows
+
+// Open opens database with v1 signatures
+func Open(driverName, dataSourceName string) (*DB, error) {
+	return v1.Open(driverName, dataSourceName)
+}
+
+// Unwrap returns db of v1 signatures, which shares connections and settings with d
+func Unwrap(d *v2.DB) *DB {
+	return d.V1()
+}
+
+// Wrap returns db of v2 signatures, which shares connections and settings with db
+func Wrap(db *DB) *v2.DB {
+	return v2.FromV1(db)
+}
@@ -0,0 +1,219 @@
+// Package sql is v2 of github.com/gopub/sql. Statements take context as the first argument, and invalid usage
+// like a record without primary key is returned as error wrapping ErrInvalidUsage instead of panicking.
+//
+// DB wraps DB of v1, which keeps its settings like naming strategy, cache, tenant scope and middlewares.
+// V1 returns the wrapped DB for APIs not ported yet, see package compat and gosql-fix to migrate v1 code
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	v1 "github.com/gopub/sql"
+)
+
+// ErrInvalidUsage is wrapped by errors of invalid usage, which v1 panics with
+var ErrInvalidUsage = errors.New("invalid usage")
+
+var ErrNoRows = v1.ErrNoRows
+
+type (
+	Expression       = v1.Expression
+	ConflictStrategy = v1.ConflictStrategy
+	TxOptions        = v1.TxOptions
+	MultiError       = v1.MultiError
+	RecordError      = v1.RecordError
+)
+
+const (
+	UpdateOnConflict = v1.UpdateOnConflict
+	SkipConflicts    = v1.SkipConflicts
+	FailFast         = v1.FailFast
+)
+
+type DB struct {
+	db *v1.DB
+}
+
+// Open opens database
+// dataSourceName's format: username:password@tcp(host:port)/dbName
+func Open(driverName, dataSourceName string) (*DB, error) {
+	db, err := v1.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	return FromV1(db), nil
+}
+
+// OpenDB wraps db of driverName
+func OpenDB(driverName string, db *sql.DB) *DB {
+	return FromV1(v1.OpenDB(driverName, db))
+}
+
+// FromV1 wraps db of v1, they share connections and settings
+func FromV1(db *v1.DB) *DB {
+	if db == nil {
+		panic("db is nil")
+	}
+	return &DB{db: db}
+}
+
+// V1 returns the wrapped DB of v1
+func (d *DB) V1() *v1.DB {
+	return d.db
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+func (d *DB) Table(name string) *Table {
+	return &Table{t: d.db.Table(name)}
+}
+
+func (d *DB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.db.ExecContext(ctx, query, args...)
+}
+
+// Query executes a query on replica if there is any, rows are bound to ctx
+func (d *DB) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.db.QueryContext(ctx, query, args...)
+}
+
+func (d *DB) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return d.db.QueryRowContext(ctx, query, args...)
+}
+
+// Insert inserts record. Write-behind buffers of v1 are used by V1().Insert instead
+func (d *DB) Insert(ctx context.Context, record interface{}) error {
+	return d.tableOf(record).Insert(ctx, record)
+}
+
+func (d *DB) Update(ctx context.Context, record interface{}) error {
+	return d.tableOf(record).Update(ctx, record)
+}
+
+func (d *DB) Save(ctx context.Context, record interface{}) error {
+	return d.tableOf(record).Save(ctx, record)
+}
+
+// Delete deletes the row of record by its primary key
+func (d *DB) Delete(ctx context.Context, record interface{}) error {
+	return d.tableOf(record).DeleteRecord(ctx, record)
+}
+
+func (d *DB) Get(ctx context.Context, record interface{}, pk ...interface{}) error {
+	return d.tableOf(record).Get(ctx, record, pk...)
+}
+
+func (d *DB) Select(ctx context.Context, records interface{}, where string, args ...interface{}) error {
+	return d.tableOf(records).Select(ctx, records, where, args...)
+}
+
+func (d *DB) SelectOne(ctx context.Context, record interface{}, where string, args ...interface{}) error {
+	return d.tableOf(record).SelectOne(ctx, record, where, args...)
+}
+
+// MultiInsert inserts values within a transaction, which is rolled back at the first failed record reported by MultiError.
+// Statements aren't batched by SetMultiStatementBatchSize of v1
+func (d *DB) MultiInsert(ctx context.Context, values ...interface{}) error {
+	return d.multiExec(ctx, values, (*Tx).Insert)
+}
+
+// MultiUpdate updates values within a transaction like MultiInsert
+func (d *DB) MultiUpdate(ctx context.Context, values ...interface{}) error {
+	return d.multiExec(ctx, values, (*Tx).Update)
+}
+
+// MultiDelete deletes rows of values by their primary keys within a transaction like MultiInsert
+func (d *DB) MultiDelete(ctx context.Context, values ...interface{}) error {
+	return d.multiExec(ctx, values, (*Tx).Delete)
+}
+
+// MultiSave saves values within a transaction like MultiInsert, conflicting records are handled by strategy
+func (d *DB) MultiSave(ctx context.Context, strategy ConflictStrategy, values ...interface{}) error {
+	switch strategy {
+	case UpdateOnConflict:
+		return d.multiExec(ctx, values, (*Tx).Save)
+	case SkipConflicts:
+		return d.multiExec(ctx, values, func(tx *Tx, ctx context.Context, record interface{}) error {
+			_, err := tx.InsertIgnore(ctx, record)
+			return err
+		})
+	case FailFast:
+		return d.MultiInsert(ctx, values...)
+	default:
+		return fmt.Errorf("%w: invalid conflict strategy: %d", ErrInvalidUsage, strategy)
+	}
+}
+
+func (d *DB) multiExec(ctx context.Context, values []interface{}, fn func(tx *Tx, ctx context.Context, record interface{}) error) error {
+	tx, err := d.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	for i, v := range values {
+		if err = fn(tx, ctx, v); err != nil {
+			tx.Rollback()
+			return &MultiError{
+				Total:  len(values),
+				Errors: []*RecordError{{Index: i, Record: v, Err: err}},
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func (d *DB) BeginTx(ctx context.Context, opts *TxOptions) (*Tx, error) {
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx}, nil
+}
+
+// WithTx runs fn in a transaction, which is committed if fn returns nil, otherwise rolled back
+func (d *DB) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	return d.db.WithTx(ctx, func(tx *v1.Tx) error {
+		return fn(&Tx{tx: tx})
+	})
+}
+
+// tableOf returns table of record, or of elements of records which is a pointer to slice.
+// Invalid records are reported by statements of the table
+func (d *DB) tableOf(v interface{}) *Table {
+	var name string
+	if err := catch(func() { name = v1.TableNameOf(v) }); err != nil {
+		return &Table{err: err}
+	}
+	return d.Table(name)
+}
+
+// catch calls fn, and returns error wrapping ErrInvalidUsage if fn panics with invalid usage of v1, whose message is a string.
+// Other panics like runtime errors or panics of drivers and valuers are panicked again, as they aren't invalid usage.
+// Transactions of v1 are rolled back before panics reach here
+func catch(fn func()) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		msg, ok := r.(string)
+		if !ok {
+			panic(r)
+		}
+		err = fmt.Errorf("%w: %s", ErrInvalidUsage, msg)
+	}()
+	fn()
+	return nil
+}
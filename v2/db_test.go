@@ -0,0 +1,116 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/gopub/sql/gosqltest"
+	"github.com/gopub/sql/v2"
+	"reflect"
+	"testing"
+)
+
+type item struct {
+	ID   int64 `sql:"primary key"`
+	Name string
+}
+
+type log struct {
+	Message string
+}
+
+func TestDB(t *testing.T) {
+	db1, f := gosqltest.New("mysql")
+	db := sql.FromV1(db1)
+	ctx := context.Background()
+	if err := db.Insert(ctx, &item{ID: 1, Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "INSERT INTO items(id, name) VALUES (?, ?)" ||
+		!reflect.DeepEqual(s.Args, []interface{}{int64(1), "a"}) {
+		t.Error(s.Query, s.Args)
+	}
+
+	f.On("SELECT").Returns([]string{"id", "name"}, []interface{}{1, "a"})
+	var items []*item
+	if err := db.Table("items").OrderBy("id").Select(ctx, &items, "name = ?", "a"); err != nil || len(items) != 1 {
+		t.Fatal(err, items)
+	}
+
+	if s := f.LastStatement(); s.Query != "SELECT id, name FROM items WHERE name = ? ORDER BY id" {
+		t.Error(s.Query)
+	}
+
+	if db.V1() != db1 {
+		t.Error("v1 isn't shared")
+	}
+}
+
+func TestDB_invalidUsage(t *testing.T) {
+	db1, _ := gosqltest.New("mysql")
+	db := sql.FromV1(db1)
+	ctx := context.Background()
+	if err := db.Delete(ctx, &log{Message: "a"}); !errors.Is(err, sql.ErrInvalidUsage) {
+		t.Error(err)
+	}
+
+	if err := db.Select(ctx, &item{}, ""); !errors.Is(err, sql.ErrInvalidUsage) {
+		t.Error(err)
+	}
+
+	if err := db.Table("items").Paginate(0, 10).Select(ctx, &[]*item{}, ""); !errors.Is(err, sql.ErrInvalidUsage) {
+		t.Error(err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := db.Update(ctx, &item{ID: 1}); !errors.Is(err, context.Canceled) {
+		t.Error(err)
+	}
+}
+
+func TestDB_MultiSave(t *testing.T) {
+	db1, f := gosqltest.New("mysql")
+	db := sql.FromV1(db1)
+	f.On("INSERT IGNORE").Fails(errors.New("disk full"))
+	err := db.MultiSave(context.Background(), sql.SkipConflicts, &item{ID: 1}, &item{ID: 2})
+	var me *sql.MultiError
+	if !errors.As(err, &me) || me.Errors[0].Index != 0 {
+		t.Fatal(err)
+	}
+
+	if s := f.LastStatement(); s.Query != "ROLLBACK" {
+		t.Error(s.Query)
+	}
+
+	if err = db.MultiSave(context.Background(), sql.ConflictStrategy(-1)); !errors.Is(err, sql.ErrInvalidUsage) {
+		t.Error(err)
+	}
+}
+
+type panicValue string
+
+func (v panicValue) Value() (driver.Value, error) {
+	panic(errors.New("broken valuer"))
+}
+
+type broken struct {
+	ID    int64 `sql:"primary key"`
+	Value panicValue
+}
+
+func TestDB_MultiInsert_panic(t *testing.T) {
+	db1, f := gosqltest.New("mysql")
+	db := sql.FromV1(db1)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("panic of valuer is caught as invalid usage")
+		}
+
+		if s := f.LastStatement(); s.Query != "ROLLBACK" {
+			t.Error(s.Query)
+		}
+	}()
+	db.MultiInsert(context.Background(), &broken{ID: 1})
+}
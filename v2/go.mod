@@ -0,0 +1,12 @@
+module github.com/gopub/sql/v2
+
+go 1.18
+
+require github.com/gopub/sql v1.1.0
+
+require (
+	github.com/gopub/log v1.0.3 // indirect
+	github.com/gopub/mapper v1.0.8 // indirect
+	github.com/gopub/utils v1.0.1 // indirect
+	github.com/jinzhu/inflection v0.0.0-20180308033659-04140366298a // indirect
+)
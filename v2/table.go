@@ -0,0 +1,142 @@
+package sql
+
+import (
+	"context"
+	v1 "github.com/gopub/sql"
+)
+
+// Table builds statements of a table like Table of v1, whose statements take context first.
+// Invalid usage of builder methods is returned by statements of the built table
+type Table struct {
+	t   *v1.Table
+	err error
+}
+
+// V1 returns the wrapped Table of v1, it's nil if t is built with invalid usage
+func (t *Table) V1() *v1.Table {
+	return t.t
+}
+
+// with returns a copy of t modified by fn
+func (t *Table) with(fn func(t *v1.Table) *v1.Table) *Table {
+	c := *t
+	if c.err == nil {
+		c.err = catch(func() { c.t = fn(t.t) })
+	}
+	return &c
+}
+
+// exec runs fn with t in ctx
+func (t *Table) exec(ctx context.Context, fn func(t *v1.Table) error) error {
+	if t.err != nil {
+		return t.err
+	}
+
+	var err error
+	if perr := catch(func() { err = fn(t.t.WithContext(ctx)) }); perr != nil {
+		return perr
+	}
+	return err
+}
+
+func (t *Table) Columns(names ...string) *Table {
+	return t.with(func(c *v1.Table) *v1.Table {
+		return c.Columns(names...)
+	})
+}
+
+func (t *Table) OrderBy(columns ...string) *Table {
+	return t.with(func(c *v1.Table) *v1.Table {
+		return c.OrderBy(columns...)
+	})
+}
+
+func (t *Table) Limit(n int) *Table {
+	return t.with(func(c *v1.Table) *v1.Table {
+		return c.Limit(n)
+	})
+}
+
+func (t *Table) Offset(n int) *Table {
+	return t.with(func(c *v1.Table) *v1.Table {
+		return c.Offset(n)
+	})
+}
+
+// Paginate sets LIMIT and OFFSET for Select, page starts from 1
+func (t *Table) Paginate(page, size int) *Table {
+	return t.with(func(c *v1.Table) *v1.Table {
+		return c.Paginate(page, size)
+	})
+}
+
+func (t *Table) Insert(ctx context.Context, record interface{}) error {
+	return t.exec(ctx, func(c *v1.Table) error {
+		return c.Insert(record)
+	})
+}
+
+// InsertIgnore inserts record unless it conflicts with existing rows, and reports whether it's inserted
+func (t *Table) InsertIgnore(ctx context.Context, record interface{}) (bool, error) {
+	var inserted bool
+	err := t.exec(ctx, func(c *v1.Table) error {
+		var err error
+		inserted, err = c.InsertIgnore(record)
+		return err
+	})
+	return inserted, err
+}
+
+func (t *Table) Update(ctx context.Context, record interface{}) error {
+	return t.exec(ctx, func(c *v1.Table) error {
+		return c.Update(record)
+	})
+}
+
+func (t *Table) Save(ctx context.Context, record interface{}) error {
+	return t.exec(ctx, func(c *v1.Table) error {
+		return c.Save(record)
+	})
+}
+
+// Delete deletes rows matching where
+func (t *Table) Delete(ctx context.Context, where string, args ...interface{}) error {
+	return t.exec(ctx, func(c *v1.Table) error {
+		return c.Delete(where, args...)
+	})
+}
+
+// DeleteRecord deletes the row of record by its primary key
+func (t *Table) DeleteRecord(ctx context.Context, record interface{}) error {
+	return t.exec(ctx, func(c *v1.Table) error {
+		return c.DeleteRecord(record)
+	})
+}
+
+func (t *Table) Get(ctx context.Context, record interface{}, pk ...interface{}) error {
+	return t.exec(ctx, func(c *v1.Table) error {
+		return c.Get(record, pk...)
+	})
+}
+
+func (t *Table) Select(ctx context.Context, records interface{}, where string, args ...interface{}) error {
+	return t.exec(ctx, func(c *v1.Table) error {
+		return c.Select(records, where, args...)
+	})
+}
+
+func (t *Table) SelectOne(ctx context.Context, record interface{}, where string, args ...interface{}) error {
+	return t.exec(ctx, func(c *v1.Table) error {
+		return c.SelectOne(record, where, args...)
+	})
+}
+
+func (t *Table) Count(ctx context.Context, where string, args ...interface{}) (int, error) {
+	var n int
+	err := t.exec(ctx, func(c *v1.Table) error {
+		var err error
+		n, err = c.Count(where, args...)
+		return err
+	})
+	return n, err
+}
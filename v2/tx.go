@@ -0,0 +1,75 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	v1 "github.com/gopub/sql"
+)
+
+// Tx is a transaction begun by DB.BeginTx or DB.WithTx. Statements take context like DB,
+// which is usually the context of the transaction
+type Tx struct {
+	tx *v1.Tx
+}
+
+// V1 returns the wrapped Tx of v1
+func (t *Tx) V1() *v1.Tx {
+	return t.tx
+}
+
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (t *Tx) Table(name string) *Table {
+	return &Table{t: t.tx.Table(name)}
+}
+
+func (t *Tx) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, query, args...)
+}
+
+func (t *Tx) Insert(ctx context.Context, record interface{}) error {
+	return t.tableOf(record).Insert(ctx, record)
+}
+
+func (t *Tx) InsertIgnore(ctx context.Context, record interface{}) (bool, error) {
+	return t.tableOf(record).InsertIgnore(ctx, record)
+}
+
+func (t *Tx) Update(ctx context.Context, record interface{}) error {
+	return t.tableOf(record).Update(ctx, record)
+}
+
+func (t *Tx) Save(ctx context.Context, record interface{}) error {
+	return t.tableOf(record).Save(ctx, record)
+}
+
+// Delete deletes the row of record by its primary key
+func (t *Tx) Delete(ctx context.Context, record interface{}) error {
+	return t.tableOf(record).DeleteRecord(ctx, record)
+}
+
+func (t *Tx) Get(ctx context.Context, record interface{}, pk ...interface{}) error {
+	return t.tableOf(record).Get(ctx, record, pk...)
+}
+
+func (t *Tx) Select(ctx context.Context, records interface{}, where string, args ...interface{}) error {
+	return t.tableOf(records).Select(ctx, records, where, args...)
+}
+
+func (t *Tx) SelectOne(ctx context.Context, record interface{}, where string, args ...interface{}) error {
+	return t.tableOf(record).SelectOne(ctx, record, where, args...)
+}
+
+func (t *Tx) tableOf(v interface{}) *Table {
+	var name string
+	if err := catch(func() { name = v1.TableNameOf(v) }); err != nil {
+		return &Table{err: err}
+	}
+	return t.Table(name)
+}
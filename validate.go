@@ -0,0 +1,99 @@
+package sql
+
+import (
+	"github.com/gopub/log"
+	"strings"
+)
+
+// ValidationLevel decides how operations handle issues found by validation, see DB.SetValidation
+type ValidationLevel int
+
+const (
+	// ValidateOff skips validation, it's the default
+	ValidateOff ValidationLevel = iota
+
+	// ValidateWarn logs issues as warnings, and executes statements
+	ValidateWarn
+
+	// ValidateStrict fails operations with *ValidationError
+	ValidateStrict
+)
+
+// ValidationError lists issues of a statement found by validation
+type ValidationError struct {
+	Op     string
+	Table  string
+	Issues []string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Op + " " + e.Table + ": " + strings.Join(e.Issues, "; ")
+}
+
+// SetValidation makes Select, SelectOne, UpdateColumnsMap and Delete check statements for common mistakes before
+// execution, e.g. LIMIT without ORDER BY, or DELETE with where clause matching all rows
+func (d *DB) SetValidation(level ValidationLevel) {
+	d.opts.validation = level
+}
+
+// Validate returns *ValidationError if statement of op, i.e. SELECT, UPDATE or DELETE, built by t with where
+// has issues, regardless of validation level
+func (t *Table) Validate(op, where string) error {
+	if issues := t.issues(op, nil, where); len(issues) > 0 {
+		return &ValidationError{Op: op, Table: t.name, Issues: issues}
+	}
+	return nil
+}
+
+// validate checks statement of op by validation level. info is columns to select, which is nil for other operations
+func (t *Table) validate(op string, info *columnInfo, where string) error {
+	if t.opts.validation == ValidateOff {
+		return nil
+	}
+
+	issues := t.issues(op, info, where)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	err := &ValidationError{Op: op, Table: t.name, Issues: issues}
+	if t.opts.validation == ValidateWarn {
+		log.Warn(err)
+		return nil
+	}
+	return err
+}
+
+func (t *Table) issues(op string, info *columnInfo, where string) []string {
+	var issues []string
+	switch op {
+	case "SELECT":
+		if info != nil && len(info.names) == 0 && len(t.columns) > 0 {
+			issues = append(issues, "none of columns "+strings.Join(t.columns, ", ")+" is mapped")
+		}
+
+		if (t.limit > 0 || t.offset > 0) && len(t.orderBy) == 0 && !t.opts.strictOrder {
+			issues = append(issues, "LIMIT without ORDER BY returns rows in undefined order")
+		}
+	case "UPDATE", "DELETE":
+		if !t.allRows && isTautology(where) {
+			issues = append(issues, "where clause "+where+" matches all rows")
+		}
+	}
+	return issues
+}
+
+// isTautology reports whether where is always true, e.g. 1=1
+func isTautology(where string) bool {
+	w := strings.ToLower(strings.Join(strings.Fields(where), ""))
+	for strings.HasPrefix(w, "(") && strings.HasSuffix(w, ")") {
+		w = w[1 : len(w)-1]
+	}
+
+	switch w {
+	case "", "1", "true", "1=1", "0=0", "'1'='1'", "1<>0", "1!=0":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,40 @@
+package sql_test
+
+import (
+	"errors"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"testing"
+)
+
+func TestDB_SetValidation(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetValidation(sql.ValidateStrict)
+	var items []*fakeItem
+	err := db.Table("items").Limit(10).Select(&items, "name = ?", "a")
+	var ve *sql.ValidationError
+	if !errors.As(err, &ve) || ve.Op != "SELECT" || len(ve.Issues) != 1 {
+		t.Fatal(err)
+	}
+
+	if err = db.Table("items").Limit(10).OrderBy("id").Select(&items, "name = ?", "a"); err != nil {
+		t.Error(err)
+	}
+
+	if err = db.Table("items").Delete("1 = 1"); !errors.As(err, &ve) || ve.Op != "DELETE" {
+		t.Error(err)
+	}
+
+	if len(f.Statements()) != 1 {
+		t.Error(f.Statements())
+	}
+
+	db.SetValidation(sql.ValidateWarn)
+	if err = db.Table("items").Delete("(1=1)"); err != nil {
+		t.Error(err)
+	}
+
+	if err = db.Table("items").Validate("UPDATE", "true"); err == nil {
+		t.Error("tautology isn't found")
+	}
+}
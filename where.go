@@ -0,0 +1,289 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var _whereKeywords = map[string]struct{}{
+	"and": {}, "or": {}, "not": {}, "xor": {}, "in": {}, "is": {}, "null": {}, "like": {}, "ilike": {},
+	"between": {}, "exists": {}, "true": {}, "false": {}, "asc": {}, "desc": {}, "order": {}, "by": {},
+	"limit": {}, "offset": {}, "group": {}, "having": {}, "distinct": {}, "case": {}, "when": {}, "then": {},
+	"else": {}, "end": {}, "as": {}, "collate": {}, "escape": {}, "regexp": {}, "rlike": {}, "div": {},
+	"mod": {}, "interval": {}, "binary": {}, "any": {}, "all": {}, "some": {}, "select": {}, "from": {},
+	"where": {}, "year": {}, "month": {}, "day": {}, "hour": {}, "minute": {}, "second": {},
+}
+
+// WhereError describes an invalid where clause
+type WhereError struct {
+	Where  string
+	Reason string
+}
+
+func (e *WhereError) Error() string {
+	return fmt.Sprintf("invalid where %q: %s", e.Where, e.Reason)
+}
+
+type whereInfo struct {
+	// placeholders is the max n of $n, or the count of ? if there is no $n
+	placeholders int
+
+	// columns are lower case identifiers which are not keywords or functions
+	columns []string
+}
+
+// parseWhere scans where clause in dialect of driverName for placeholders and column names,
+// and checks quotes and parentheses are balanced. Comments are skipped
+func parseWhere(where, driverName string) (*whereInfo, error) {
+	info := &whereInfo{}
+	depth := 0
+	maxN := 0
+	for i := 0; i < len(where); i++ {
+		c := where[i]
+		if j := commentEnd(where, i); j >= 0 {
+			i = j
+			continue
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := quoteEnd(where, i, driverName == "mysql")
+			if j < 0 {
+				return nil, &WhereError{Where: where, Reason: fmt.Sprintf("unclosed quote at %d", i)}
+			}
+
+			if c == '`' {
+				info.columns = append(info.columns, strings.ToLower(where[i+1:j]))
+			}
+			i = j
+		case c == '?':
+			if n := operatorLen(where, i, driverName); n > 0 {
+				i += n - 1
+				continue
+			}
+			info.placeholders++
+		case c == '$':
+			n := 0
+			for i+1 < len(where) && where[i+1] >= '0' && where[i+1] <= '9' {
+				n = n*10 + int(where[i+1]-'0')
+				i++
+			}
+			if n > maxN {
+				maxN = n
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return nil, &WhereError{Where: where, Reason: fmt.Sprintf("unexpected ) at %d", i)}
+			}
+		case c == '@' || c >= '0' && c <= '9':
+			for i+1 < len(where) && (isIdentChar(where[i+1]) || where[i+1] == '.') {
+				i++
+			}
+		case isIdentChar(c):
+			j := i
+			for j < len(where) && isIdentChar(where[j]) {
+				j++
+			}
+			word := strings.ToLower(where[i:j])
+			i = j - 1
+
+			// skip qualifier of column
+			if j < len(where) && where[j] == '.' {
+				continue
+			}
+
+			// skip function name
+			k := j
+			for k < len(where) && where[k] == ' ' {
+				k++
+			}
+			if k < len(where) && where[k] == '(' {
+				continue
+			}
+
+			if _, ok := _whereKeywords[word]; !ok {
+				info.columns = append(info.columns, word)
+			}
+		}
+	}
+
+	if depth != 0 {
+		return nil, &WhereError{Where: where, Reason: "unbalanced parentheses"}
+	}
+
+	// ? are operators if placeholders are $n
+	if maxN > 0 {
+		info.placeholders = maxN
+	}
+	return info, nil
+}
+
+// commentEnd returns index of the last byte of -- or /* */ comment starting at i, or -1 if no comment starts at i
+func commentEnd(s string, i int) int {
+	switch {
+	case strings.HasPrefix(s[i:], "--"):
+		if j := strings.IndexByte(s[i:], '\n'); j >= 0 {
+			return i + j
+		}
+		return len(s) - 1
+	case strings.HasPrefix(s[i:], "/*"):
+		if j := strings.Index(s[i+2:], "*/"); j >= 0 {
+			return i + j + 3
+		}
+		return len(s) - 1
+	default:
+		return -1
+	}
+}
+
+// quoteEnd returns index of the quote closing the literal or identifier quoted at i, or -1 if it's unclosed.
+// Doubled quotes are escaped quotes, and backslashes escape the next byte in literals if backslash is true, e.g. in mysql
+func quoteEnd(s string, i int, backslash bool) int {
+	q := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case s[j] == '\\' && backslash && q != '`':
+			j++
+		case s[j] == q:
+			if j+1 < len(s) && s[j+1] == q {
+				j++
+				continue
+			}
+			return j
+		}
+	}
+	return -1
+}
+
+// operatorLen returns length of operator starting with ? at i, or 0 if it's a placeholder.
+// Operators are jsonb ?| and ?& of postgres, and ?? which is the escaped ? operator
+func operatorLen(s string, i int, driverName string) int {
+	if !isPostgres(driverName) || i+1 >= len(s) {
+		return 0
+	}
+
+	switch s[i+1] {
+	case '?', '&':
+		return 2
+	case '|':
+		// ?|| is a placeholder followed by concatenation
+		if i+2 < len(s) && s[i+2] == '|' {
+			return 0
+		}
+		return 2
+	default:
+		return 0
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// checkWhere validates where clause against args if strict where is enabled.
+// Referenced columns are also checked against info if info is not nil and there are no joins.
+func (t *Table) checkWhere(info *columnInfo, where string, args []interface{}) error {
+	if !t.opts.strictWhere {
+		return nil
+	}
+
+	if len(where) == 0 {
+		if len(args) > 0 {
+			return &WhereError{Where: where, Reason: fmt.Sprintf("no placeholder for %d args", len(args))}
+		}
+		return nil
+	}
+
+	w, err := parseWhere(where, t.driverName)
+	if err != nil {
+		return err
+	}
+
+	if w.placeholders != len(args) {
+		return &WhereError{Where: where, Reason: fmt.Sprintf("%d placeholders but %d args", w.placeholders, len(args))}
+	}
+
+	if info != nil && len(t.joins) == 0 {
+		for _, c := range w.columns {
+			if _, ok := info.nameToIndex[c]; !ok {
+				return &WhereError{Where: where, Reason: "unknown column " + c}
+			}
+		}
+	}
+	return nil
+}
+
+// rebind replaces ? placeholders with $1, $2... for postgres, unless query already uses $n placeholders.
+// Literals, quoted identifiers, comments and operators like ?| are kept, and ?? is replaced with the ? operator
+func rebind(query string) string {
+	if !strings.Contains(query, "?") || hasDollarPlaceholder(query) {
+		return query
+	}
+
+	var buf strings.Builder
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if j := skipEnd(query, i); j >= 0 {
+			buf.WriteString(query[i : j+1])
+			i = j
+			continue
+		}
+
+		if c != '?' {
+			buf.WriteByte(c)
+			continue
+		}
+
+		switch l := operatorLen(query, i, "postgres"); {
+		case l == 0:
+			n++
+			buf.WriteString("$" + strconv.Itoa(n))
+		case query[i+1] == '?':
+			buf.WriteByte('?')
+			i++
+		default:
+			buf.WriteString(query[i : i+l])
+			i += l - 1
+		}
+	}
+	return buf.String()
+}
+
+// hasDollarPlaceholder reports whether query has $n placeholders outside literals, quoted identifiers and comments
+func hasDollarPlaceholder(query string) bool {
+	for i := 0; i < len(query); i++ {
+		if j := skipEnd(query, i); j >= 0 {
+			i = j
+			continue
+		}
+
+		if query[i] == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9' &&
+			(i == 0 || !isIdentChar(query[i-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipEnd returns index of the last byte of comment, literal or quoted identifier starting at i, or -1 if none starts at i.
+// Unclosed quotes end at the end of query
+func skipEnd(query string, i int) int {
+	if j := commentEnd(query, i); j >= 0 {
+		return j
+	}
+
+	switch query[i] {
+	case '\'', '"', '`':
+		if j := quoteEnd(query, i, false); j >= 0 {
+			return j
+		}
+		return len(query) - 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,90 @@
+package sql
+
+import "testing"
+
+func TestParseWhere(t *testing.T) {
+	w, err := parseWhere("name=? AND (price > ? OR lower(t.txt) LIKE '%?%') ORDER BY updated_at DESC", "mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.placeholders != 2 {
+		t.Error(w.placeholders)
+	}
+
+	if len(w.columns) != 4 || w.columns[0] != "name" || w.columns[1] != "price" || w.columns[2] != "txt" || w.columns[3] != "updated_at" {
+		t.Error(w.columns)
+	}
+
+	w, err = parseWhere("id=$1 and name=$2", "postgres")
+	if err != nil || w.placeholders != 2 {
+		t.Error(err)
+	}
+
+	for _, where := range []string{"name='it''s", "(id=?", "id=?)", "name=\"abc"} {
+		if _, err = parseWhere(where, "mysql"); err == nil {
+			t.Error(where)
+		} else if _, ok := err.(*WhereError); !ok {
+			t.Error(err)
+		}
+	}
+}
+
+func TestParseWhere_dialects(t *testing.T) {
+	tests := []struct {
+		where        string
+		driverName   string
+		placeholders int
+	}{
+		{"name = 'it\\'s?' AND id = ?", "mysql", 1},
+		{"path = 'C:\\' AND id = ?", "postgres", 1},
+		{"id = ? -- or id = ?\n AND name = ?", "mysql", 2},
+		{"id = ? /* and name = ? */", "mysql", 1},
+		{"tags ?| ? AND attrs ?? 'a' AND attrs ?& ?", "postgres", 2},
+		{"attrs ?? 'a' AND name = ?||'x'", "postgres", 1},
+		{"attrs ? 'a' AND id = $1", "pgx", 1},
+	}
+	for _, test := range tests {
+		w, err := parseWhere(test.where, test.driverName)
+		if err != nil || w.placeholders != test.placeholders {
+			t.Error(test.where, err, w)
+		}
+	}
+
+	if _, err := parseWhere("path = 'C:\\' AND id = ?", "mysql"); err == nil {
+		t.Error("backslash isn't escape in mysql")
+	}
+}
+
+func TestTable_checkWhere(t *testing.T) {
+	tbl := &Table{driverName: "mysql", opts: &options{}}
+	if err := tbl.checkWhere(nil, "id = ?", nil); err != nil {
+		t.Error(err)
+	}
+
+	tbl.opts.strictWhere = true
+	if err := tbl.checkWhere(nil, "id = ?", nil); err == nil {
+		t.Error("no error")
+	}
+}
+
+func TestRebind(t *testing.T) {
+	tests := map[string]string{
+		"name = ? AND price > ?":            "name = $1 AND price > $2",
+		"name = '?' AND id = ?":             "name = '?' AND id = $1",
+		"name = 'it''s?' OR code = ?":       "name = 'it''s?' OR code = $1",
+		"id = $1":                           "id = $1",
+		"INSERT INTO t(a, b) VALUES (?, ?)": "INSERT INTO t(a, b) VALUES ($1, $2)",
+		"price = '$1' AND id = ?":           "price = '$1' AND id = $1",
+		"id = ? -- why?\nAND n = ?":         "id = $1 -- why?\nAND n = $2",
+		"/* ? */ id = ?":                    "/* ? */ id = $1",
+		"tags ?| ? AND attrs ?? 'a'":        "tags ?| $1 AND attrs ? 'a'",
+		"name = ?||'x' AND attrs ?& ?":      "name = $1||'x' AND attrs ?& $2",
+		"attrs ? 'a' AND id = $1":           "attrs ? 'a' AND id = $1",
+	}
+	for query, expected := range tests {
+		if got := rebind(query); got != expected {
+			t.Error(query, got)
+		}
+	}
+}
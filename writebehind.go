@@ -0,0 +1,277 @@
+package sql
+
+import (
+	"errors"
+	"github.com/gopub/log"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBufferOverflow = errors.New("write buffer overflow")
+	ErrDiscarded      = errors.New("discarded")
+
+	// ErrClosed is returned by Insert and AsyncInsert of buffered records after DB is closed
+	ErrClosed = errors.New("write buffer is closed")
+)
+
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks Insert until there is room in buffer
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop drops the record and returns ErrBufferOverflow
+	OverflowDrop
+)
+
+// WriteBehindConfig configures write-behind buffer of a table
+type WriteBehindConfig struct {
+	// Capacity is the max number of records waiting in buffer
+	Capacity int
+
+	// BatchSize is the max number of records inserted in one transaction
+	BatchSize int
+
+	// FlushInterval is the max time a record waits in buffer
+	FlushInterval time.Duration
+
+	// Workers is the number of background workers inserting batches concurrently, which is 1 by default
+	Workers int
+
+	Overflow OverflowPolicy
+
+	// DiscardOnClose discards buffered records instead of flushing them when DB is closed
+	DiscardOnClose bool
+
+	// OnError is called with records failed to be inserted
+	OnError func(records []interface{}, err error)
+}
+
+// EnableWriteBehind makes DB.Insert append records of table to an in-memory buffer,
+// which is flushed in batches by a background worker.
+// Records are lost if process exits before they are flushed, and auto_increment ids are not set back,
+// so only use it for tables like metrics, audit logs and events.
+func (d *DB) EnableWriteBehind(table string, config *WriteBehindConfig) {
+	if len(table) == 0 {
+		panic("table is empty")
+	}
+
+	b := newWriteBuffer(d, config)
+	if _, loaded := d.writeBuffers.LoadOrStore(table, b); loaded {
+		b.close()
+		panic("duplicate write-behind table: " + table)
+	}
+}
+
+// asyncBufferKey is key of the buffer of AsyncInsert in DB.writeBuffers, which isn't a valid table name
+const asyncBufferKey = ""
+
+// SetAsyncInsert configures the buffer of AsyncInsert, it must be called before the first AsyncInsert
+func (d *DB) SetAsyncInsert(config *WriteBehindConfig) {
+	b := newWriteBuffer(d, config)
+	if _, loaded := d.writeBuffers.LoadOrStore(asyncBufferKey, b); loaded {
+		b.close()
+		panic("async insert is already configured")
+	}
+}
+
+// AsyncInsert appends record to an in-memory buffer shared by all tables, which is inserted in batches by background workers.
+// Records of tables with write-behind buffer are appended to their own buffers. Failed inserts are reported to OnError
+// of the config set by SetAsyncInsert, db.Flush waits until buffered records are inserted, and db.Close drains buffers.
+// Same as write-behind buffer, records are lost if process exits before they are flushed
+func (d *DB) AsyncInsert(record interface{}) error {
+	if b, ok := d.writeBuffers.Load(getTableName(record)); ok {
+		return b.(*writeBuffer).add(record)
+	}
+
+	b, ok := d.writeBuffers.Load(asyncBufferKey)
+	if !ok {
+		nb := newWriteBuffer(d, &WriteBehindConfig{})
+		if b, ok = d.writeBuffers.LoadOrStore(asyncBufferKey, nb); ok {
+			nb.close()
+		}
+	}
+	return b.(*writeBuffer).add(record)
+}
+
+type writeBuffer struct {
+	db      *DB
+	config  *WriteBehindConfig
+	records chan interface{}
+	flushC  []chan chan struct{} //flush requests of each worker
+	quit    chan struct{}
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newWriteBuffer(db *DB, config *WriteBehindConfig) *writeBuffer {
+	c := *config
+	if c.Capacity <= 0 {
+		c.Capacity = 1024
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = time.Second
+	}
+	if c.Workers <= 0 {
+		c.Workers = 1
+	}
+
+	b := &writeBuffer{
+		db:      db,
+		config:  &c,
+		records: make(chan interface{}, c.Capacity),
+		flushC:  make([]chan chan struct{}, c.Workers),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(c.Workers)
+	for i := range b.flushC {
+		b.flushC[i] = make(chan chan struct{})
+		go func(flushC chan chan struct{}) {
+			defer wg.Done()
+			b.run(flushC)
+		}(b.flushC[i])
+	}
+
+	go func() {
+		wg.Wait()
+		close(b.done)
+	}()
+	return b
+}
+
+func (b *writeBuffer) add(record interface{}) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	if b.config.Overflow == OverflowDrop {
+		select {
+		case b.records <- record:
+			return nil
+		default:
+			return ErrBufferOverflow
+		}
+	}
+	b.records <- record
+	return nil
+}
+
+// flush blocks until records added before are inserted
+func (b *writeBuffer) flush() {
+	for _, flushC := range b.flushC {
+		c := make(chan struct{})
+		select {
+		case flushC <- c:
+			<-c
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *writeBuffer) close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.quit)
+	b.mu.Unlock()
+	<-b.done
+}
+
+func (b *writeBuffer) run(flushC chan chan struct{}) {
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]interface{}, 0, b.config.BatchSize)
+	for {
+		select {
+		case r := <-b.records:
+			batch = append(batch, r)
+			if len(batch) >= b.config.BatchSize {
+				b.insert(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			b.insert(batch)
+			batch = batch[:0]
+		case c := <-flushC:
+			batch = b.drain(batch, false)
+			close(c)
+		case <-b.quit:
+			b.drain(batch, b.config.DiscardOnClose)
+			return
+		}
+	}
+}
+
+// drain inserts or discards batch and all records in buffer, which may be shared with other workers
+func (b *writeBuffer) drain(batch []interface{}, discard bool) []interface{} {
+	for empty := false; !empty; {
+		select {
+		case r := <-b.records:
+			batch = append(batch, r)
+			if !discard && len(batch) >= b.config.BatchSize {
+				b.insert(batch)
+				batch = batch[:0]
+			}
+		default:
+			empty = true
+		}
+	}
+
+	if discard {
+		b.fail(batch, ErrDiscarded)
+	} else {
+		b.insert(batch)
+	}
+	return batch[:0]
+}
+
+func (b *writeBuffer) insert(records []interface{}) {
+	if len(records) == 0 {
+		return
+	}
+
+	tx, err := b.db.Begin()
+	if err != nil {
+		b.fail(records, err)
+		return
+	}
+
+	for _, r := range records {
+		err = tx.Insert(r)
+		if err != nil {
+			tx.Rollback()
+			b.fail(records, err)
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		b.fail(records, err)
+	}
+}
+
+func (b *writeBuffer) fail(records []interface{}, err error) {
+	if len(records) == 0 {
+		return
+	}
+
+	log.Error(err)
+	if b.config.OnError != nil {
+		b.config.OnError(append([]interface{}(nil), records...), err)
+	}
+}
@@ -0,0 +1,47 @@
+package sql_test
+
+import (
+	"errors"
+	"github.com/gopub/sql"
+	"github.com/gopub/sql/gosqltest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDB_AsyncInsert(t *testing.T) {
+	db, f := gosqltest.New("mysql")
+	db.SetAsyncInsert(&sql.WriteBehindConfig{BatchSize: 2, FlushInterval: time.Hour, Workers: 2})
+	for i := 1; i <= 5; i++ {
+		if err := db.AsyncInsert(&fakeItem{ID: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Flush()
+
+	inserts := 0
+	for _, s := range f.Statements() {
+		if strings.HasPrefix(s.Query, "INSERT INTO fake_items") {
+			inserts++
+		}
+	}
+	if inserts != 5 {
+		t.Fatal(f.Statements())
+	}
+
+	var failed []interface{}
+	db, f = gosqltest.New("mysql")
+	f.On("INSERT").Fails(errors.New("disk full"))
+	db.SetAsyncInsert(&sql.WriteBehindConfig{FlushInterval: time.Hour, OnError: func(records []interface{}, err error) {
+		failed = append(failed, records...)
+	}})
+	db.AsyncInsert(&fakeItem{ID: 1})
+	db.Close()
+	if len(failed) != 1 {
+		t.Error(failed)
+	}
+
+	if err := db.AsyncInsert(&fakeItem{ID: 2}); !errors.Is(err, sql.ErrClosed) {
+		t.Error(err)
+	}
+}